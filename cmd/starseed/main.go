@@ -2,29 +2,52 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+	_ "time/tzdata" // schedule timezones in minimal images
 
 	"starseed/internal/analytics"
+	"starseed/internal/audit"
+	"starseed/internal/bots"
 	"starseed/internal/config"
+	"starseed/internal/embed"
+	"starseed/internal/forecast"
+	"starseed/internal/graph"
+	"starseed/internal/ical"
     "starseed/internal/model"
 	"starseed/internal/recommend"
     "starseed/internal/schedule"
 	"starseed/internal/suggest"
 	"starseed/internal/theme"
+	"starseed/internal/util"
 	"starseed/internal/xclient"
     "starseed/internal/ingest"
 	"starseed/internal/jobs"
     "starseed/internal/nn"
     "starseed/internal/store/sqlitevec"
     "starseed/internal/engage"
+    "starseed/internal/queue"
+    "starseed/internal/policy"
+    "starseed/internal/tui"
     "starseed/internal/metrics"
     "starseed/internal/cmdlog"
+    "starseed/internal/logging"
 )
 
+// botModelPath is where 'bots train' writes the bot model (filters.botModel opts in to it).
+const botModelPath = "./starseed_bot_model.json"
+
 func main() {
     // start metrics server if configured
     metrics.StartServer("")
@@ -45,14 +68,32 @@ func main() {
         _ = cmdlog.Run("monitor", func() error { cmdMonitor(); return nil })
 	case "audit":
         _ = cmdlog.Run("audit", func() error { cmdAudit(); return nil })
+	case "graph":
+        _ = cmdlog.Run("graph", func() error { cmdGraph(); return nil })
+	case "bots":
+        _ = cmdlog.Run("bots", func() error { cmdBots(); return nil })
+	case "embed":
+        _ = cmdlog.Run("embed", func() error { cmdEmbed(); return nil })
 	case "schedule":
         _ = cmdlog.Run("schedule", func() error { cmdSchedule(); return nil })
+	case "suggest":
+        _ = cmdlog.Run("suggest", func() error { cmdSuggest(); return nil })
+	case "queue":
+        _ = cmdlog.Run("queue", func() error { cmdQueue(); return nil })
+	case "policy":
+        _ = cmdlog.Run("policy", func() error { cmdPolicy(); return nil })
+	case "tui":
+        _ = cmdlog.Run("tui", func() error { cmdTUI(); return nil })
     case "nn-train":
         _ = cmdlog.Run("nn_train", func() error { cmdNNTrain(); return nil })
     case "nn-infer":
         _ = cmdlog.Run("nn_infer", func() error { cmdNNInfer(); return nil })
     case "nn-train-db":
         _ = cmdlog.Run("nn_train_db", func() error { cmdNNTrainDB(); return nil })
+    case "nn-tune":
+        _ = cmdlog.Run("nn_tune", func() error { cmdNNTune(); return nil })
+    case "nn-drift":
+        _ = cmdlog.Run("nn_drift", func() error { cmdNNDrift(); return nil })
     case "ingest-events":
         _ = cmdlog.Run("ingest_events", func() error { cmdIngestEvents(); return nil })
 	case "ingest-loop":
@@ -60,6 +101,13 @@ func main() {
 	default:
 		printHelp()
 	}
+    nn.ClosePools()
+}
+
+// exit stops the warm model workers before exiting with code.
+func exit(code int) {
+	nn.ClosePools()
+	os.Exit(code)
 }
 
 func printHelp() {
@@ -69,24 +117,38 @@ func printHelp() {
 	fmt.Println("  init        Create a config file at ./starseed.yaml")
 	fmt.Println("  analyze     Analyze timeline and followings")
 	fmt.Println("  recommend   Recommend accounts and posts")
-	fmt.Println("  engage      Suggest comments with timing")
+	fmt.Println("  engage      Suggest comments with timing (-mode auto [-dry-run] posts gated drafts; -kill on|off|status)")
 	fmt.Println("  monitor     Show hourly engagement analytics")
-	fmt.Println("  audit       Bot-likelihood and organic filters")
-	fmt.Println("  schedule    Show next engagement window")
+	fmt.Println("  audit       Bot and organic audit of followings and followers (-format table|csv|json); audit history shows trends")
+	fmt.Println("  graph sync|changes|communities  Refresh the stored follow graph within the API budget; list follows/unfollows; cluster followings")
+	fmt.Println("  bots score -user h | bots train -labels file  Bot probability with per-signal contributions; fit the model to labeled accounts")
+	fmt.Println("  embed train | embed score -text t  Train the local embedding model on stored tweets; show a text's similarity to each topic")
+	fmt.Println("  audit decisions [-since 24h] [-author h] [-outcome o]  Review recorded engage decisions")
+	fmt.Println("  schedule    Show next engagement window and ranked windows")
+	fmt.Println("  schedule export -format ics [-out file|-serve 127.0.0.1:8788]  Export windows and queued drafts as iCalendar")
+	fmt.Println("  suggest preview -id <tweetID>  Render the persona prompt (and heuristic draft) for a tweet")
+	fmt.Println("  queue list|show|approve|edit|reject|post  Review drafted replies and post approved ones")
+	fmt.Println("  policy test [-policy file] [-at time] [-source tweets|drafts]  Evaluate the engagement policy against stored tweets or drafts")
+	fmt.Println("  tui         Full-screen triage of queued drafts (approve/edit/reject)")
     fmt.Println("  nn-train    Train NN on 15-min features")
     fmt.Println("  nn-infer    Infer with NN on 15-min features")
     fmt.Println("  nn-train-db Train NN from SQLite windows with calibration")
+    fmt.Println("  nn-tune     Hyperparameter search with time-ordered cross-validation")
+    fmt.Println("  nn-drift    Compare recent windows with training stats (PSI, mean shift)")
     fmt.Println("  ingest-events  Fetch likes/mentions and backfill labels")
 	fmt.Println("  ingest-loop    Continuous ingestion loop (use Ctrl-C to stop)")
     fmt.Println("Env:")
     fmt.Println("  METRICS_ADDR   e.g., :9090 to expose /metrics")
+    fmt.Println("  STARSEED_NN_WORKERS  warm model workers (default 2, 0 = spawn per call)")
 }
 
 func mustLoadClient(cfg config.Config) *xclient.HTTPClient {
 	if cfg.Credentials.BearerToken == "" {
 		fmt.Println("warning: missing X_BEARER_TOKEN; API calls will fail")
 	}
-	return xclient.NewHTTPClient(cfg.Credentials.BearerToken)
+	c := xclient.NewHTTPClient(cfg.Credentials.BearerToken)
+	c.SetUserToken(cfg.Credentials.UserToken)
+	return c
 }
 
 func cmdInit() {
@@ -96,7 +158,7 @@ func cmdInit() {
 	cfg := config.Default()
 	if err := config.Save(*path, cfg); err != nil {
 		fmt.Println("error:", err)
-		os.Exit(1)
+		exit(1)
 	}
 	abs, _ := filepath.Abs(*path)
 	theme.PrintBanner()
@@ -109,13 +171,13 @@ func cmdAnalyze() {
 	limit := fs.Int("limit", 100, "items limit")
 	_ = fs.Parse(os.Args[2:])
 	cfg, err := config.Load(*cfgPath)
-	if err != nil { fmt.Println("error:", err); os.Exit(1) }
+	if err != nil { fmt.Println("error:", err); exit(1) }
 	client := mustLoadClient(cfg)
 	ctx := context.Background()
 	me, err := client.GetUserByUsername(ctx, cfg.Account.Username)
-	if err != nil { fmt.Println("error:", err); os.Exit(1) }
+	if err != nil { fmt.Println("error:", err); exit(1) }
     follows, err := client.GetFollowing(ctx, me.ID, *limit)
-    if err != nil { fmt.Println("error:", err); os.Exit(1) }
+    if err != nil { fmt.Println("error:", err); exit(1) }
     fmt.Printf("Following: %d users\n", len(follows))
     // Try v1.1 home timeline if OAuth creds present
     var tl []model.Tweet
@@ -134,56 +196,254 @@ func cmdAnalyze() {
 func cmdRecommend() {
 	fs := flag.NewFlagSet("recommend", flag.ExitOnError)
 	cfgPath := fs.String("config", "./starseed.yaml", "config path")
+	tweets := fs.Int("tweets", 20, "recent tweets per account for the organic score")
+	fetch := fs.Int("fetch", 100, "max timelines fetched from the API per run, new candidates first (others use the cache or a neutral score; 0 = no cap)")
+	mode := fs.String("mode", "", "heuristic, pagerank or salsa (default recommend.mode)")
+	damping := fs.Float64("damping", 0, "pagerank/salsa restart damping (default recommend.damping or 0.85)")
+	iters := fs.Int("iters", 0, "pagerank/salsa iteration limit (default recommend.maxIterations or 50)")
 	_ = fs.Parse(os.Args[2:])
 	cfg, err := config.Load(*cfgPath)
-	if err != nil { fmt.Println("error:", err); os.Exit(1) }
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	if *mode != "" { cfg.Recommend.Mode = *mode }
+	if *damping != 0 { cfg.Recommend.Damping = *damping }
+	if *iters != 0 { cfg.Recommend.MaxIterations = *iters }
+	if err := cfg.Validate(); err != nil { fmt.Println("error:", err); exit(1) }
 	client := mustLoadClient(cfg)
 	ctx := context.Background()
+	db, err := sqlitevec.Open(cfg.Storage.DBPath)
+	if err != nil { fmt.Println("db error:", err); exit(1) }
+	defer db.Close()
+	rel := useEmbeddings(ctx, cfg, db)
+	bot := botScorer(cfg)
+	// One fetch budget for the whole run, shared by candidates and followings
+	budget := *fetch
+	orgOpts := recommend.OrganicOptions{Tweets: *tweets}
+	if budget > 0 { orgOpts.Budget = &budget }
+	rank := func(users []model.User) []recommend.AccountRecommendation {
+		warmBios(ctx, rel, users)
+		org, err := recommend.OrganicScores(ctx, db, client, users, orgOpts, time.Now().UTC())
+		if err != nil { logging.Error("organic_scores", map[string]any{"err": err.Error()}) }
+		return recommend.RankAccountsOrganic(users, org, cfg.Interests.Keywords, cfg.Interests.Weights, bot)
+	}
 	me, err := client.GetUserByUsername(ctx, cfg.Account.Username)
-	if err != nil { fmt.Println("error:", err); os.Exit(1) }
-	follows, err := client.GetFollowing(ctx, me.ID, 200)
-	if err != nil { fmt.Println("error:", err); os.Exit(1) }
-    recs := recommend.RankAccounts(follows, cfg.Interests.Keywords, cfg.Interests.Weights)
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	// Following lists come from the stored graph (starseed graph sync); up to 15 unsynced
+	// ones (the endpoint's per-15-minute limit) are fetched and stored, the rest left to sync
+	fallbacks := 15
+	stored := graph.Stored{DB: db, Fallback: client, Budget: &fallbacks}
+	follows, err := stored.GetFollowing(ctx, me.ID, 200)
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	// Candidates are scored first so the -fetch budget goes to accounts we have not
+	// seen before the followings whose timelines were cached on earlier runs
+	var newRecs []recommend.AccountRecommendation
+	header, note := "New accounts to consider:", ""
+	m := cfg.Recommend.Mode
+	byGraph := m == recommend.ModePageRank || m == recommend.ModeSALSA
+	if byGraph {
+		header = fmt.Sprintf("New accounts to consider (%s):", m)
+		newRecs, note = graphRecommend(ctx, db, client, cfg, rel, bot, me.ID, orgOpts)
+	} else if found, err := recommend.DiscoverTweetsByInterests(ctx, client, cfg, 100); err == nil {
+		// Discovery by interests -> recommend new accounts not already followed
+		already := make(map[string]struct{})
+		for _, u := range follows { already[u.ID] = struct{}{} }
+		newUsers, _ := recommend.DiscoverAccountsFromTweets(ctx, client, found, already)
+		// Graph expansion: mutuals and one-hop
+		graphUsers, _ := recommend.DiscoverGraph(ctx, stored, follows, 200)
+		newUsers = append(newUsers, graphUsers...)
+		if len(newUsers) > 0 { newRecs = rank(newUsers) } else { note = "No new accounts discovered from interest tweets." }
+	}
+	recs := rank(follows)
 	for i := 0; i < len(recs) && i < 20; i++ {
 		r := recs[i]
-		fmt.Printf("@%s score=%.2f rel=%.2f bot=%.2f\n", r.User.Username, r.FinalScore, r.RelevanceScore, r.BotLikelihood)
-	}
-    // Discovery by interests -> recommend new accounts not already followed
-    tweets, err := recommend.DiscoverTweetsByInterests(ctx, client, cfg, 100)
-    if err == nil {
-        already := make(map[string]struct{})
-        for _, u := range follows { already[u.ID] = struct{}{} }
-        newUsers, _ := recommend.DiscoverAccountsFromTweets(ctx, client, tweets, already)
-        // Graph expansion: mutuals and one-hop
-        graphUsers, _ := recommend.DiscoverGraph(ctx, client, follows, 200)
-        newUsers = append(newUsers, graphUsers...)
-        if len(newUsers) > 0 {
-            newRecs := recommend.RankAccounts(newUsers, cfg.Interests.Keywords, cfg.Interests.Weights)
-            fmt.Println("New accounts to consider:")
-            for i := 0; i < len(newRecs) && i < 20; i++ {
-                r := newRecs[i]
-                fmt.Printf("+ @%s score=%.2f rel=%.2f bot=%.2f\n", r.User.Username, r.FinalScore, r.RelevanceScore, r.BotLikelihood)
-            }
-        } else {
-            fmt.Println("No new accounts discovered from interest tweets.")
-        }
-    }
+		fmt.Printf("@%s score=%.2f rel=%.2f bot=%.2f org=%.2f (n=%d)\n", r.User.Username, r.FinalScore, r.RelevanceScore, r.BotLikelihood, r.OrganicScore, r.TweetsSampled)
+	}
+	if note != "" { fmt.Println(note) }
+	if len(newRecs) == 0 { return }
+	// Favor accounts followed from circles we under-engage (starseed graph communities)
+	if w := cfg.Recommend.CommunityWeight; w > 0 {
+		rep, err := graph.Communities(ctx, db, me.ID, graph.CommunityOptions{}, time.Now().UTC())
+		if err == nil { newRecs, err = recommend.BoostUnderserved(ctx, db, newRecs, rep, w) }
+		if err != nil { logging.Error("recommend_communities", map[string]any{"err": err.Error()}) }
+	}
+	fmt.Println(header)
+	for i := 0; i < len(newRecs) && i < 20; i++ {
+		r := newRecs[i]
+		circle := ""
+		if r.CommunityBoost > 0 { circle = fmt.Sprintf(" circle=%d(+%.2f)", r.Community, r.CommunityBoost) }
+		if byGraph {
+			fmt.Printf("+ @%s score=%.2f graph=%.2f rel=%.2f bot=%.2f org=%.2f (n=%d)%s\n", r.User.Username, r.FinalScore, r.GraphScore, r.RelevanceScore, r.BotLikelihood, r.OrganicScore, r.TweetsSampled, circle)
+		} else {
+			fmt.Printf("+ @%s score=%.2f rel=%.2f bot=%.2f org=%.2f (n=%d)%s\n", r.User.Username, r.FinalScore, r.RelevanceScore, r.BotLikelihood, r.OrganicScore, r.TweetsSampled, circle)
+		}
+	}
+}
+
+// graphRecommend ranks new accounts by personalized PageRank or SALSA over the stored
+// follow graph (starseed graph sync), with recent interactions weighting our edges. Without
+// candidates it returns a note instead.
+func graphRecommend(ctx context.Context, db *sqlitevec.DB, client *xclient.HTTPClient, cfg config.Config, rel *embed.Relevance, bot model.BotScorer, me string, orgOpts recommend.OrganicOptions) ([]recommend.AccountRecommendation, string) {
+	g, err := graph.Load(ctx, db)
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	end := time.Now().UTC()
+	counts := recommend.CountInteractionsByAuthor(ctx, db, end.Add(-30*24*time.Hour), end)
+	rc := cfg.Recommend
+	scores := recommend.GraphScores(g, me, counts, recommend.GraphRankOptions{Mode: rc.Mode, Rank: graph.RankOptions{Damping: rc.Damping, MaxIter: rc.MaxIterations}, InteractionWeight: rc.InteractionWeight})
+	if len(scores) == 0 { return nil, "No graph candidates; run starseed graph sync to store followings beyond your own." }
+	ids := recommend.TopGraph(scores, 50)
+	known, err := db.GetUsers(ctx, ids)
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	var missing []string
+	for _, id := range ids {
+		if _, ok := known[id]; !ok { missing = append(missing, id) }
+	}
+	if len(missing) > 0 {
+		fetched, err := client.GetUsersByIDs(ctx, missing)
+		if err != nil { logging.Error("recommend_profiles", map[string]any{"err": err.Error()}) }
+		_ = db.PutUsers(ctx, fetched, end)
+		for _, u := range fetched { known[u.ID] = u }
+	}
+	users := make([]model.User, 0, len(known))
+	for _, id := range ids {
+		if u, ok := known[id]; ok { users = append(users, u) }
+	}
+	org, err := recommend.OrganicScores(ctx, db, client, users, orgOpts, end)
+	if err != nil { logging.Error("organic_scores", map[string]any{"err": err.Error()}) }
+	warmBios(ctx, rel, users)
+	return recommend.RankByGraph(users, scores, org, cfg.Interests.Keywords, cfg.Interests.Weights, bot), ""
 }
 
 func cmdEngage() {
 	fs := flag.NewFlagSet("engage", flag.ExitOnError)
 	cfgPath := fs.String("config", "./starseed.yaml", "config path")
     seedFile := fs.String("seeds", "", "optional path to seed accounts file (one @handle per line)")
+	mode := fs.String("mode", "suggest", "suggest (queue drafts for review) or auto (post drafts that pass every gate)")
+	dryRun := fs.Bool("dry-run", false, "auto: log and audit what would be posted without posting")
+	kill := fs.String("kill", "", "set the automated-posting kill switch: on, off or status")
 	_ = fs.Parse(os.Args[2:])
 	cfg, err := config.Load(*cfgPath)
-	if err != nil { fmt.Println("error:", err); os.Exit(1) }
-    client := mustLoadClient(cfg)
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	if *mode != "suggest" && *mode != "auto" { fmt.Println("error: -mode must be suggest or auto"); exit(1) }
+	auto := *mode == "auto"
+	if auto && !*dryRun && !cfg.Engagement.Auto.Enabled {
+		fmt.Println("error: automated posting is off; set engagement.auto.enabled: true or use -dry-run")
+		exit(1)
+	}
     ctx := context.Background()
     now := time.Now().UTC()
+    db, _ := sqlitevec.Open(cfg.Storage.DBPath)
+    if db != nil { defer db.Close() }
+	if *kill != "" {
+		if db == nil { fmt.Println("error: database unavailable"); exit(1) }
+		switch *kill {
+		case "on", "off":
+			if err := queue.SetKillSwitch(ctx, db, *kill == "on"); err != nil { fmt.Println("error:", err); exit(1) }
+		case "status":
+		default:
+			fmt.Println("error: -kill must be on, off or status"); exit(1)
+		}
+		if on, why := queue.KillSwitch(ctx, db, cfg.Engagement.Auto.KillSwitchFile); on { fmt.Println("Automated posting halted:", why) } else { fmt.Println("Automated posting allowed.") }
+		return
+	}
+    rules, err := schedule.Compile(cfg.Engagement)
+    if err != nil { fmt.Println("error:", err); exit(1) }
+    var rec *audit.Recorder
+    if db != nil {
+        rec, err = audit.Open(db, cfg.Storage.DecisionLog)
+        if err != nil { fmt.Println("decision log error:", err) }
+        defer rec.Close()
+    }
+    client := mustLoadClient(cfg)
+    useEmbeddings(ctx, cfg, db)
+    bot := botScorer(cfg)
+    tweets, sugs, authors := draftSuggestions(ctx, client, db, rec, cfg, bot, *seedFile, now)
+    pol, err := policy.ForConfig(cfg)
+    if err != nil { fmt.Println("error:", err); exit(1) }
+    // Predict the current window with the calibrated model, if present
+    pred, thr, hasModel := predictWindow(ctx, db, tweetsToModel(tweets), now)
+    if !auto {
+        // Run-level gate: the policy sees the prediction and the rolling global budgets. Drafting
+        // only checks them; budgets are spent when a reply is actually posted.
+        budget := engage.NewBudget(db, cfg.Engagement)
+        v, _ := budget.Check(ctx, "engage", now)
+        f := policy.Facts{"action": "engage", "budget.allowed": v.Allowed}
+        if hasModel { f["prediction"], f["model.pass"] = float64(pred), pred >= thr }
+        res := pol.Evaluate(f)
+        gate := policy.Facts{"candidates": len(sugs), "policy": res, "budget.allowed": v.Allowed, "budget.next": v.Next}
+        if hasModel { gate["prediction"], gate["threshold"] = pred, thr }
+        if counts, err := budget.Counts(ctx, "engage", now); err == nil { gate["budget"] = counts }
+        outcome := "allowed"
+        if res.Decision == policy.Deny { outcome = queue.OutcomeSkipped } else if res.Decision == policy.Defer { outcome = queue.OutcomeDeferred }
+        recordEntry(ctx, rec, audit.Entry{Kind: "engage_gate", Outcome: outcome, Reason: "policy " + res.Rule + ": " + res.Reason, Facts: gate}, now)
+        if res.Decision != policy.Allow {
+            fmt.Printf("Skipping engagement suggestions (%s by policy rule %s: %s).\n", res.Decision, res.Rule, res.Reason)
+            if !v.Allowed { fmt.Printf("Budget: %s; next engagement at %s.\n", v.Reason, v.Next.Format(time.RFC3339)) }
+            return
+        }
+    }
+    // Place suggestions in the best forecast windows (quiet hours skipped) within budgets
+    run := policy.Facts{}
+    if hasModel { run["prediction"], run["threshold"] = pred, thr }
+    sugs = assignWindows(ctx, db, rec, cfg, rules, now, "./starseed-nn/target/release/starseed-nn", "./starseed_model.json", sugs, authors, bot, run)
+    if db != nil {
+        ranked := rankAuthors(ctx, db, cfg, authors, bot, now)
+        stored, qerr := queue.Enqueue(ctx, db, sugs, ranked, now)
+        added := 0
+        for _, e := range stored {
+            if e.Created { added++ }
+        }
+        if qerr != nil { fmt.Println("queue error:", qerr) } else { fmt.Printf("Queued %d new drafts for review (starseed queue list).\n", added) }
+        // Audit what was stored: queued for new drafts, duplicate when the tweet had one
+        counts, _ := engage.NewBudget(db, cfg.Engagement).Counts(ctx, "reply", now)
+        for i, e := range stored {
+            sg := sugs[i]
+            u := authors[sg.Tweet.AuthorID]
+            f := candidateFacts(sg.Tweet, u, bot, now)
+            f["policy"] = pol.Evaluate(f)
+            f["draft.score"], f["draft.text"], f["when"], f["window"], f["budget"] = sg.Score, sg.Text, sg.When, sg.Window, counts
+            if a, ok := ranked[sg.Tweet.AuthorID]; ok { f["author.score"] = a.FinalScore }
+            for k, v := range run { f[k] = v }
+            outcome, reason := queue.OutcomeQueued, sg.Why
+            if !e.Created { outcome, reason = queue.OutcomeDuplicate, fmt.Sprintf("tweet already has draft #%d", e.DraftID) }
+            recordEntry(ctx, rec, audit.Entry{Kind: "engage_candidate", Outcome: outcome, DraftID: e.DraftID, TweetID: sg.Tweet.ID, AuthorID: sg.Tweet.AuthorID, Author: u.Username, Reason: reason, Facts: f}, now)
+        }
+    }
+    if auto {
+        if db == nil { fmt.Println("error: auto mode needs the database"); exit(1) }
+        opts := queue.AutoOptions{Engagement: cfg.Engagement, Policy: pol, Quiet: rules.IsQuiet, Location: rules.Location(), HasModel: hasModel, Prediction: pred, Threshold: thr, DryRun: *dryRun, Recorder: rec}
+        decisions, err := queue.Auto(ctx, db, client, opts, now)
+        for _, d := range decisions {
+            fmt.Printf("[%s] draft=%d tweet=%s %s\n", d.Outcome, d.DraftID, d.TweetID, d.Reason)
+        }
+        if err != nil { fmt.Println("error:", err); exit(1) }
+        return
+    }
+    for _, s := range sugs {
+		fmt.Printf("when=%s why=%s score=%.2f\n%s\n", s.When.Format(time.RFC3339), s.Why, s.Score, s.Text)
+		for _, alt := range s.Alternates {
+			fmt.Printf("  alt (%s, %.2f): %s\n", alt.Source, alt.Score, alt.Text)
+		}
+		fmt.Println("---")
+	}
+}
+
+// pastReplies returns our replies within the novelty window, for the repetition checks.
+func pastReplies(ctx context.Context, db *sqlitevec.DB, cfg config.Config, now time.Time) []string {
+    if db == nil { return nil }
+    days := cfg.Suggest.NoveltyDays
+    if days <= 0 { days = 30 }
+    past, err := suggest.PastReplies(ctx, db, now.AddDate(0, 0, -days), now)
+    if err != nil { logging.Error("past_replies", map[string]any{"err": err.Error()}) }
+    return past
+}
+
+// draftSuggestions discovers candidate tweets (seed accounts or interests) and drafts scored
+// reply candidates for each, persisting the candidate sets when db is available. Dropped
+// candidates are recorded through rec (nil records nothing).
+func draftSuggestions(ctx context.Context, client *xclient.HTTPClient, db *sqlitevec.DB, rec *audit.Recorder, cfg config.Config, bot model.BotScorer, seedFile string, now time.Time) ([]model.Tweet, []suggest.Suggestion, map[string]model.User) {
     // If seed file is provided, expand discovery by those users' recent tweets
     var tweets []model.Tweet
-    if *seedFile != "" {
-        seeds, _ := readHandles(*seedFile)
+    if seedFile != "" {
+        seeds, _ := readHandles(seedFile)
         // Resolve handles to IDs
         for _, h := range seeds {
             u, err := client.GetUserByUsername(ctx, h)
@@ -197,37 +457,243 @@ func cmdEngage() {
         found, _ := recommend.DiscoverTweetsByInterests(ctx, client, cfg, 50)
         tweets = append(tweets, found...)
     }
-    sugs := suggest.HeuristicSuggest(tweets, now)
-    // Respect quiet hours from config when scheduling
-    qh := cfg.Engagement.QuietHours
-    for i := range sugs {
-        sugs[i].When = schedule.NextWindow(now, qh)
+    tweets = dropDrafted(ctx, db, tweets)
+    pr, err := suggest.NewPrompter(cfg.Persona)
+    if err != nil { fmt.Println("error:", err); exit(1) }
+    base := suggest.PromptData{Account: cfg.Account.Username, Interests: cfg.Interests}
+    authors := lookupAuthors(ctx, client, tweets)
+    // Embed tweet texts in batches before anything scores them; drafts are warmed per tweet
+    // in suggest.Generate (failures are logged once by the embedder)
+    texts := make([]string, len(tweets))
+    for i, t := range tweets { texts[i] = t.Text }
+    _ = model.WarmRelevance(ctx, texts)
+    tweets = policyFilter(ctx, rec, cfg, tweets, authors, bot, now)
+    sugs := pr.HeuristicSuggest(tweets, base, authors, now)
+    // Load each conversation and drop threads we already replied in
+    threads := loadThreads(ctx, client, db, cfg, now, sugs)
+    kept := sugs[:0]
+    for _, sg := range sugs {
+        if _, ok := threads[sg.Tweet.ID]; !ok {
+            fmt.Fprintf(os.Stderr, "Skipping tweet %s: already replied in this conversation.\n", sg.Tweet.ID)
+            u := authors[sg.Tweet.AuthorID]
+            recordCandidate(ctx, rec, queue.OutcomeSkipped, "already replied in this conversation", sg.Tweet, u, candidateFacts(sg.Tweet, u, bot, now), now)
+            continue
+        }
+        kept = append(kept, sg)
     }
-    // Optionally upgrade with LLM
-    for i := range sugs {
-        upgraded, err := suggest.DraftWithLLM(ctx, cfg.LLM, sugs[i].Tweet.Text, sugs[i].Text)
-        if err == nil && upgraded != "" { sugs[i].Text = upgraded }
+    sugs = kept
+    // Generate scored candidates (LLM samples + heuristic variants) and keep the best
+    past := pastReplies(ctx, db, cfg, now)
+    scorer := suggest.NewScorer(cfg.Suggest.Weights, cfg.Interests, pr.Persona().MaxLength, past)
+    validator := suggest.NewValidator(cfg.Suggest.Rules, pr.Persona().MaxLength, past)
+    kept = sugs[:0]
+    for _, sg := range sugs {
+        d := base
+        d.Tweet, d.Author = sg.Tweet, authors[sg.Tweet.AuthorID]
+        d.Thread, d.Replies = threads[d.Tweet.ID].Summarize(4, 3)
+        cands, rejected, err := suggest.Generate(ctx, cfg.LLM, pr, scorer, validator, d, cfg.Suggest.Candidates)
+        if err != nil { logging.Error("suggest_llm", map[string]any{"tweet_id": d.Tweet.ID, "err": err.Error()}) }
+        if db != nil { _ = suggest.SaveCandidates(ctx, db, d.Tweet.ID, now, cands, rejected) }
+        for _, r := range rejected {
+            logging.Info("suggest_rejected", map[string]any{"tweet_id": d.Tweet.ID, "source": r.Source, "rule": r.Rejection.Rule, "reason": r.Rejection.Reason})
+        }
+        if len(cands) == 0 {
+            fmt.Fprintf(os.Stderr, "Dropping tweet %s: all %d drafts rejected.\n", d.Tweet.ID, len(rejected))
+            f := candidateFacts(d.Tweet, d.Author, bot, now)
+            var rules []string
+            for _, r := range rejected { rules = append(rules, r.Rejection.Rule) }
+            f["rejections"] = rules
+            recordCandidate(ctx, rec, queue.OutcomeRejected, fmt.Sprintf("all %d drafts rejected", len(rejected)), d.Tweet, d.Author, f, now)
+            continue
+        }
+        sg.Text, sg.Score, sg.Alternates = cands[0].Text, cands[0].Score, cands[1:]
+        kept = append(kept, sg)
     }
-    // Gate by calibrated threshold if model is present
-    db, _ := sqlitevec.Open(cfg.Storage.DBPath)
-    if db != nil { defer db.Close() }
-    thr := engage.LoadEffectiveThreshold(db, "./starseed_model.json")
-    if thr > 0 {
-        // Build feature for now window and infer
-        fv, _ := nn.BuildFeaturesWithHistory(ctx, db, now.Add(-15*time.Minute), tweetsToModel(tweets), nil)
-        preds, _ := nn.Infer("./starseed-nn/target/release/starseed-nn", "./starseed_model.json", []nn.FeatureVector{fv})
-        if !engage.ShouldEngage(ctx, thr, preds) {
-            fmt.Println("Below threshold; skipping engagement suggestions.")
-            return
+    sugs = kept
+    return tweets, sugs, authors
+}
+
+// dropDrafted removes tweets that already have a draft in any state (pending, approved,
+// posted, rejected or expired), so a run never drafts the same tweet twice.
+func dropDrafted(ctx context.Context, db *sqlitevec.DB, tweets []model.Tweet) []model.Tweet {
+    if db == nil || len(tweets) == 0 { return tweets }
+    ids := make([]string, len(tweets))
+    for i, t := range tweets { ids[i] = t.ID }
+    states, err := db.DraftStates(ctx, ids)
+    if err != nil { logging.Error("draft_states", map[string]any{"err": err.Error()}); return tweets }
+    kept := tweets[:0]
+    for _, t := range tweets {
+        if st, ok := states[t.ID]; ok {
+            logging.Info("already_drafted", map[string]any{"tweet_id": t.ID, "state": st})
+            continue
         }
-        // Also check budgets
-        ok, _ := engage.ShouldAllowEngage(ctx, db, cfg.Engagement, now)
-        if !ok { fmt.Println("Budget exceeded; skipping engagement suggestions."); return }
-        _ = engage.RecordEngage(ctx, db, now)
+        kept = append(kept, t)
     }
-    for _, s := range sugs {
-		fmt.Printf("when=%s why=%s\n%s\n---\n", s.When.Format(time.RFC3339), s.Why, s.Text)
-	}
+    if n := len(tweets) - len(kept); n > 0 { fmt.Fprintf(os.Stderr, "Skipping %d tweets that already have drafts.\n", n) }
+    return kept
+}
+
+// predictWindow scores the current window with the calibrated model; hasModel is false
+// when no threshold (and so no usable model) is available.
+func predictWindow(ctx context.Context, db *sqlitevec.DB, tweets []model.Tweet, now time.Time) (pred, thr float32, hasModel bool) {
+    thr = engage.LoadEffectiveThreshold(db, "./starseed_model.json")
+    if thr <= 0 { return 0, thr, false }
+    fv, _ := nn.BuildFeaturesWithHistory(ctx, db, now.Add(-15*time.Minute), tweets, nil)
+    preds, _ := nn.Infer("./starseed-nn/target/release/starseed-nn", "./starseed_model.json", []nn.FeatureVector{fv})
+    if len(preds) > 0 && len(preds[0]) > 0 { pred = preds[0][0] }
+    return pred, thr, true
+}
+
+// policyFilter drops tweets the engagement policy denies replying to. Time and budget
+// facts are left out here: those are decided when the reply is scheduled or posted.
+func policyFilter(ctx context.Context, rec *audit.Recorder, cfg config.Config, tweets []model.Tweet, authors map[string]model.User, bot model.BotScorer, now time.Time) []model.Tweet {
+    pol, err := policy.ForConfig(cfg)
+    if err != nil { fmt.Println("error:", err); exit(1) }
+    kept := tweets[:0]
+    for _, t := range tweets {
+        f := candidateFacts(t, authors[t.AuthorID], bot, now)
+        if res := pol.Evaluate(f); res.Decision == policy.Deny {
+            logging.Info("policy_skip", map[string]any{"tweet_id": t.ID, "rule": res.Rule, "reason": res.Reason})
+            f["policy"] = res
+            recordCandidate(ctx, rec, queue.OutcomeSkipped, "policy "+res.Rule+": "+res.Reason, t, authors[t.AuthorID], f, now)
+            continue
+        }
+        kept = append(kept, t)
+    }
+    return kept
+}
+
+// candidateFacts describes a candidate tweet and its author (when known, scored with bot)
+// for the policy engine and the decision audit.
+func candidateFacts(t model.Tweet, u model.User, bot model.BotScorer, now time.Time) policy.Facts {
+    f := policy.Facts{"action": "reply"}.WithTweet(t, now)
+    if u.ID != "" { f.WithAuthor(u, bot) }
+    return f
+}
+
+// suggestionFacts are the candidate facts of a drafted suggestion plus the run facts.
+func suggestionFacts(sg suggest.Suggestion, u model.User, bot model.BotScorer, run policy.Facts, now time.Time) policy.Facts {
+    f := candidateFacts(sg.Tweet, u, bot, now)
+    f["draft.score"], f["draft.text"] = sg.Score, sg.Text
+    for k, v := range run { f[k] = v }
+    return f
+}
+
+// recordCandidate audits one engage decision about tweet t.
+func recordCandidate(ctx context.Context, rec *audit.Recorder, outcome, reason string, t model.Tweet, u model.User, f policy.Facts, now time.Time) {
+    recordEntry(ctx, rec, audit.Entry{Kind: "engage_candidate", Outcome: outcome, TweetID: t.ID, AuthorID: t.AuthorID, Author: u.Username, Reason: reason, Facts: f}, now)
+}
+
+// recordEntry writes e to the decision log, logging (not failing on) write errors.
+func recordEntry(ctx context.Context, rec *audit.Recorder, e audit.Entry, now time.Time) {
+    if _, err := rec.Record(ctx, e, now); err != nil { logging.Error("audit_record", map[string]any{"kind": e.Kind, "tweet_id": e.TweetID, "err": err.Error()}) }
+}
+
+// rankAuthors scores the authors of drafted tweets for reviewers, keyed by user ID. Organic
+// scores come from cached timelines only; authors without any stay neutral.
+func rankAuthors(ctx context.Context, db *sqlitevec.DB, cfg config.Config, authors map[string]model.User, bot model.BotScorer, now time.Time) map[string]recommend.AccountRecommendation {
+    users := make([]model.User, 0, len(authors))
+    for _, u := range authors { users = append(users, u) }
+    organic, err := recommend.OrganicScores(ctx, db, nil, users, recommend.OrganicOptions{}, now)
+    if err != nil { logging.Error("organic_scores", map[string]any{"err": err.Error()}) }
+    out := make(map[string]recommend.AccountRecommendation, len(users))
+    for _, r := range recommend.RankAccountsOrganic(users, organic, cfg.Interests.Keywords, cfg.Interests.Weights, bot) { out[r.User.ID] = r }
+    return out
+}
+
+// loadThreads fetches the conversation for each suggestion, keyed by tweet ID. Tweets whose
+// conversation already contains a reply from us (live or recorded) are left out.
+func loadThreads(ctx context.Context, client *xclient.HTTPClient, db *sqlitevec.DB, cfg config.Config, now time.Time, sugs []suggest.Suggestion) map[string]suggest.Thread {
+    selfID := ""
+    if cfg.Account.Username != "" {
+        if u, err := client.GetUserByUsername(ctx, cfg.Account.Username); err == nil { selfID = u.ID }
+    }
+    replied := map[string]bool{}
+    if db != nil { replied, _ = suggest.RepliedConversations(ctx, db, now.AddDate(0, 0, -30), now) }
+    out := make(map[string]suggest.Thread, len(sugs))
+    for _, sg := range sugs {
+        t := sg.Tweet
+        if replied[t.ID] || (t.ConversationID != "" && replied[t.ConversationID]) { continue }
+        th, err := suggest.FetchThread(ctx, client, t, selfID)
+        if err != nil { logging.Error("suggest_thread", map[string]any{"tweet_id": t.ID, "err": err.Error()}) }
+        if th.AlreadyReplied { continue }
+        out[t.ID] = th
+    }
+    return out
+}
+
+// lookupAuthors fetches profiles for the tweets' authors (best effort) keyed by user ID.
+func lookupAuthors(ctx context.Context, client *xclient.HTTPClient, tweets []model.Tweet) map[string]model.User {
+    seen := map[string]bool{}
+    var ids []string
+    for _, t := range tweets {
+        if t.AuthorID == "" || seen[t.AuthorID] { continue }
+        seen[t.AuthorID] = true
+        ids = append(ids, t.AuthorID)
+    }
+    out := make(map[string]model.User, len(ids))
+    for i := 0; i < len(ids); i += 100 {
+        end := i + 100
+        if end > len(ids) { end = len(ids) }
+        users, err := client.GetUsersByIDs(ctx, ids[i:end])
+        if err != nil { break }
+        for _, u := range users { out[u.ID] = u }
+    }
+    return out
+}
+
+// assignWindows gives each suggestion one of the top forecast windows where a reply fits the
+// rolling global and reply budgets, minimum spacing and the actions already recorded, then
+// applies cooldowns; without a model or history the windows are taken in time order.
+// Deferred and skipped suggestions are audited with their author and the run facts (prediction
+// and threshold when a model is available).
+func assignWindows(ctx context.Context, db *sqlitevec.DB, rec *audit.Recorder, cfg config.Config, rules *schedule.Rules, now time.Time, bin, modelPath string, sugs []suggest.Suggestion, authors map[string]model.User, bot model.BotScorer, run policy.Facts) []suggest.Suggestion {
+    if db == nil {
+        for i := range sugs { sugs[i].When = rules.Next(now) }
+        return sugs
+    }
+    ranked, err := forecast.Forecast(ctx, db, forecast.ModelScorer(bin, modelPath), now, forecast.Options{Skip: rules.IsQuiet})
+    if err != nil { fmt.Fprintln(os.Stderr, "forecast unavailable:", err) }
+    if len(ranked) == 0 { ranked = forecast.Chronological(now, 24*time.Hour, rules.IsQuiet) }
+    budget := engage.NewBudget(db, cfg.Engagement)
+    budget.DryRun = true
+    slots, err := forecast.Assign(ctx, budget, "reply", ranked, len(sugs))
+    if err != nil { logging.Error("assign_windows", map[string]any{"err": err.Error()}) }
+    if len(slots) < len(sugs) {
+        fmt.Fprintf(os.Stderr, "Deferred %d suggestions: budgets allow %d in the next 24h.\n", len(sugs)-len(slots), len(slots))
+        for _, sg := range sugs[len(slots):] {
+            u := authors[sg.Tweet.AuthorID]
+            f := suggestionFacts(sg, u, bot, run, now)
+            f["slots"] = len(slots)
+            recordCandidate(ctx, rec, queue.OutcomeDeferred, fmt.Sprintf("budgets allow %d replies in the next 24h", len(slots)), sg.Tweet, u, f, now)
+        }
+        sugs = sugs[:len(slots)]
+    }
+    value := make(map[time.Time]float32, len(ranked))
+    for _, w := range ranked { value[w.Start] = w.Score }
+    for i := range sugs { sugs[i].When, sugs[i].Window = slots[i], value[slots[i]] }
+    sort.SliceStable(sugs, func(i, j int) bool { return sugs[i].When.Before(sugs[j].When) })
+    return applyCooldowns(ctx, db, rec, cfg, now, sugs, authors, bot, run)
+}
+
+// applyCooldowns defers suggestions that would break a per-author or per-conversation
+// cooldown (within 24h) and drops the rest.
+func applyCooldowns(ctx context.Context, db *sqlitevec.DB, rec *audit.Recorder, cfg config.Config, now time.Time, sugs []suggest.Suggestion, authors map[string]model.User, bot model.BotScorer, run policy.Facts) []suggest.Suggestion {
+    byID := make(map[string]suggest.Suggestion, len(sugs))
+    for _, sg := range sugs { byID[sg.Tweet.ID] = sg }
+    kept, skipped, err := suggest.ApplyCooldowns(ctx, db, cfg.Engagement, sugs, 24*time.Hour, now)
+    if err != nil { logging.Error("cooldowns", map[string]any{"err": err.Error()}); return sugs }
+    ids := make([]string, 0, len(skipped))
+    for id := range skipped { ids = append(ids, id) }
+    sort.Strings(ids)
+    for _, id := range ids {
+        why := skipped[id]
+        fmt.Fprintf(os.Stderr, "Skipping tweet %s: %s.\n", id, why)
+        sg := byID[id]
+        u := authors[sg.Tweet.AuthorID]
+        recordCandidate(ctx, rec, queue.OutcomeSkipped, why, sg.Tweet, u, suggestionFacts(sg, u, bot, run, now), now)
+    }
+    return kept
 }
 
 // tweetsToModel converts []model.Tweet to []model.Tweet (pass-through helper for clarity)
@@ -248,17 +714,773 @@ func cmdMonitor() {
 	}
 }
 
+// cmdAudit audits our followings and followers for bots and inorganic content; "audit
+// history" shows stored audit stats and "audit decisions" the engage decision log.
 func cmdAudit() {
-	fmt.Println("Audit will evaluate bot-likelihood and organic content filters (WIP).")
+	args := os.Args[2:]
+	if len(args) > 0 {
+		switch args[0] {
+		case "decisions":
+			cmdAuditDecisions(); return
+		case "history":
+			cmdAuditHistory(); return
+		case "accounts":
+			args = args[1:]
+		}
+	}
+	fs := flag.NewFlagSet("audit", flag.ExitOnError)
+	cfgPath := fs.String("config", "./starseed.yaml", "config path")
+	format := fs.String("format", "table", "report format: table, csv or json")
+	out := fs.String("out", "-", "output file ('-' for stdout)")
+	limit := fs.Int("limit", 1000, "max followings and max followers to fetch")
+	tweets := fs.Int("tweets", 5, "recent tweets sampled per account for the organic score (0 to skip)")
+	sample := fs.Int("sample", 200, "accounts to sample tweets for, worst profiles first (0 for all)")
+	top := fs.Int("top", 50, "table: suspicious accounts to list (0 for all)")
+	noSave := fs.Bool("no-save", false, "do not store aggregate stats in the database")
+	botModel := fs.String("bot-model", "", "bot model from 'bots train' (default filters.botModel; profile heuristic when unset)")
+	_ = fs.Parse(args)
+	cfg, err := config.Load(*cfgPath)
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	if cfg.Account.Username == "" { fmt.Println("error: account.username is not set"); exit(1) }
+	client := mustLoadClient(cfg)
+	ctx := context.Background()
+	me, err := client.GetUserByUsername(ctx, cfg.Account.Username)
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	var bm *bots.Model
+	if *botModel == "" { *botModel = cfg.Filters.BotModel }
+	if *botModel != "" {
+		if bm, err = bots.LoadOrDefault(*botModel); err != nil { fmt.Println("error:", err); exit(1) }
+	}
+	db, err := sqlitevec.Open(cfg.Storage.DBPath)
+	if err != nil { fmt.Println("db error:", err); exit(1) }
+	defer db.Close()
+	opts := audit.Options{Limit: *limit, Tweets: *tweets, MaxSampled: *sample, MaxBot: cfg.Filters.MaxBotLikelihood, MinOrganic: cfg.Filters.MinOrganicScore, Bots: bm}
+	rep, err := audit.Accounts(ctx, cachedTimelines{client, db}, me.ID, opts, time.Now().UTC())
+	if err != nil { fmt.Println("audit error:", err); exit(1) }
+	w := os.Stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil { fmt.Println("error:", err); exit(1) }
+		defer f.Close()
+		w = f
+	}
+	if err := audit.Write(w, rep, *format, *top); err != nil { fmt.Println("error:", err); exit(1) }
+	if *out != "-" { fmt.Printf("Wrote %d accounts to %s\n", len(rep.Accounts), *out) }
+	if *noSave { return }
+	if err := db.PutAuditSnapshot(ctx, audit.Snapshot(rep, opts)); err != nil { fmt.Fprintln(os.Stderr, "save error:", err) }
+}
+
+// cmdGraph maintains the stored follow graph: sync refreshes adjacency lists within the
+// API budget, changes lists recorded follows and unfollows.
+func cmdGraph() {
+	usage := "usage: starseed graph sync [-budget 15] [-depth 2] [-max-age 24h] | graph changes [-since 7d] | graph communities [-since 30d] [-min-size 3] [-json]"
+	if len(os.Args) < 3 { fmt.Println(usage); exit(1) }
+	sub := os.Args[2]
+	fs := flag.NewFlagSet("graph "+sub, flag.ExitOnError)
+	cfgPath := fs.String("config", "./starseed.yaml", "config path")
+	budget := fs.Int("budget", 15, "sync: following lists fetched per run")
+	depth := fs.Int("depth", 2, "sync: 1 = our following list, 2 = also the lists of accounts we follow")
+	maxAge := fs.Duration("max-age", 24*time.Hour, "sync: refresh lists older than this")
+	since := fs.String("since", "", "changes, communities: duration back from now, RFC3339 or YYYY-MM-DD (default 7d, 30d)")
+	limit := fs.Int("limit", 100, "changes: max rows (0 for all)")
+	minSize := fs.Int("min-size", 3, "communities: smallest community listed")
+	keywords := fs.Int("keywords", 5, "communities: label terms per community")
+	show := fs.Int("members", 5, "communities: member handles shown per community")
+	asJSON := fs.Bool("json", false, "communities: print the report as JSON")
+	_ = fs.Parse(os.Args[3:])
+	cfg, err := config.Load(*cfgPath)
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	db, err := sqlitevec.Open(cfg.Storage.DBPath)
+	if err != nil { fmt.Println("db error:", err); exit(1) }
+	defer db.Close()
+	ctx := context.Background()
+	var changes []sqlitevec.EdgeChange
+	switch sub {
+	case "sync":
+		rep, err := jobs.RunGraphSync(ctx, db, mustLoadClient(cfg), cfg, graph.SyncOptions{Budget: *budget, Depth: *depth, MaxAge: *maxAge})
+		if err != nil { fmt.Println("sync error:", err); exit(1) }
+		fmt.Printf("Synced %d following lists (%d failed, %d left for later): %d follows, %d unfollows\n", rep.Synced, rep.Failed, rep.Pending, rep.Follows, rep.Unfollows)
+		changes = rep.Changes
+	case "changes":
+		if *since == "" { *since = "7d" }
+		from, err := audit.ParseTime(*since, time.Now().UTC())
+		if err != nil { fmt.Println("error: -since:", err); exit(1) }
+		if changes, err = db.EdgeChanges(ctx, from, *limit); err != nil { fmt.Println("error:", err); exit(1) }
+	case "communities":
+		if *since == "" { *since = "30d" }
+		graphCommunities(ctx, db, cfg, *since, graph.CommunityOptions{MinSize: *minSize, Keywords: *keywords}, *show, *asJSON)
+		return
+	default:
+		fmt.Println(usage); exit(1)
+	}
+	ids := map[string]bool{}
+	for _, c := range changes { ids[c.Src], ids[c.Dst] = true, true }
+	list := make([]string, 0, len(ids))
+	for id := range ids { list = append(list, id) }
+	users, _ := db.GetUsers(ctx, list)
+	name := func(id string) string {
+		if u, ok := users[id]; ok && u.Username != "" { return "@" + u.Username }
+		return id
+	}
+	for _, c := range changes {
+		fmt.Printf("%s  %s %-8s %s\n", c.TS.Local().Format(time.DateTime), name(c.Src), c.Kind, name(c.Dst))
+	}
+}
+
+// graphCommunities prints the communities among our followings with their labels and
+// how much of our engagement each gets.
+func graphCommunities(ctx context.Context, db *sqlitevec.DB, cfg config.Config, since string, opts graph.CommunityOptions, show int, asJSON bool) {
+	now := time.Now().UTC()
+	from, err := audit.ParseTime(since, now)
+	if err != nil { fmt.Println("error: -since:", err); exit(1) }
+	opts.Since = from
+	me, err := db.UserIDByUsername(ctx, cfg.Account.Username)
+	if errors.Is(err, sqlitevec.ErrNotFound) {
+		u, err := mustLoadClient(cfg).GetUserByUsername(ctx, cfg.Account.Username)
+		if err != nil { fmt.Println("error:", err); exit(1) }
+		me = u.ID
+	} else if err != nil { fmt.Println("error:", err); exit(1) }
+	rep, err := graph.Communities(ctx, db, me, opts, now)
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	if asJSON {
+		b, _ := json.MarshalIndent(rep, "", "  ")
+		fmt.Println(string(b))
+		return
+	}
+	if len(rep.Communities) == 0 {
+		fmt.Println("No communities found; run starseed graph sync -depth 2 to store the lists of accounts you follow.")
+		return
+	}
+	var ids []string
+	for _, c := range rep.Communities { ids = append(ids, c.Members...) }
+	users, _ := db.GetUsers(ctx, ids)
+	fmt.Printf("%d communities among your followings (%d unclustered); %d outbound engagements since %s\n", len(rep.Communities), len(rep.Unclustered), rep.Outbound, from.Local().Format(time.DateOnly))
+	for _, c := range rep.Communities {
+		e := c.Engagement
+		note := ""
+		if rep.Outbound > 0 && e.Served < graph.UnderservedBelow { note = "  under-served" }
+		fmt.Printf("\n#%d  %d members  [%s]\n", c.ID, len(c.Members), strings.Join(c.Keywords, ", "))
+		fmt.Printf("    out=%d in=%d seen=%d engaged=%d/%d served=%.2f%s\n", e.Outbound, e.Inbound, e.Seen, e.Engaged, len(c.Members), e.Served, note)
+		var names []string
+		for i := 0; i < len(c.Members) && i < show; i++ {
+			if u, ok := users[c.Members[i]]; ok && u.Username != "" { names = append(names, "@"+u.Username) } else { names = append(names, c.Members[i]) }
+		}
+		if len(c.Members) > show { names = append(names, "…") }
+		fmt.Println("    " + strings.Join(names, " "))
+	}
+}
+
+// cachedTimelines serves user timelines from the tweets table, refreshing entries older
+// than a day.
+type cachedTimelines struct {
+	*xclient.HTTPClient
+	db *sqlitevec.DB
+}
+
+func (c cachedTimelines) GetUserTimeline(ctx context.Context, userID string, limit int) ([]model.Tweet, error) {
+	return ingest.CachedTimeline(ctx, c.db, c.HTTPClient, userID, limit, 24*time.Hour, time.Now().UTC())
+}
+
+// cmdBots scores accounts with the bot model and trains it on labeled accounts.
+func cmdBots() {
+	usage := "usage: starseed bots score -user handle [-model path] | bots train -labels file [-out path]"
+	if len(os.Args) < 3 { fmt.Println(usage); exit(1) }
+	sub := os.Args[2]
+	fs := flag.NewFlagSet("bots "+sub, flag.ExitOnError)
+	cfgPath := fs.String("config", "./starseed.yaml", "config path")
+	modelPath := fs.String("model", botModelPath, "bot model path (score: built-in weights when missing)")
+	tweets := fs.Int("tweets", 50, "recent tweets per account (cached in the database for a day)")
+	user := fs.String("user", "", "score: account @handle")
+	labels := fs.String("labels", "", "train: file of 'handle,bot|human' lines")
+	out := fs.String("out", botModelPath, "train: where to write the model")
+	epochs := fs.Int("epochs", 2000, "train: gradient descent epochs")
+	lr := fs.Float64("lr", 0.5, "train: learning rate")
+	folds := fs.Int("folds", 5, "train: cross-validation folds for the held-out metrics")
+	_ = fs.Parse(os.Args[3:])
+	cfg, err := config.Load(*cfgPath)
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	db, err := sqlitevec.Open(cfg.Storage.DBPath)
+	if err != nil { fmt.Println("db error:", err); exit(1) }
+	defer db.Close()
+	client := mustLoadClient(cfg)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	features := func(handle string) (bots.Features, error) {
+		u, err := client.GetUserByUsername(ctx, strings.TrimPrefix(handle, "@"))
+		if err != nil { return nil, err }
+		ts, err := ingest.CachedTimeline(ctx, db, client, u.ID, *tweets, 24*time.Hour, now)
+		if err != nil { return nil, err }
+		return bots.Extract(u, ts, now), nil
+	}
+	switch sub {
+	case "score":
+		if *user == "" { fmt.Println("error: -user is required"); exit(1) }
+		m, err := bots.LoadOrDefault(*modelPath)
+		if err != nil { fmt.Println("error:", err); exit(1) }
+		f, err := features(*user)
+		if err != nil { fmt.Println("error:", err); exit(1) }
+		sc := m.Score(f)
+		fmt.Printf("@%s bot probability %.2f\n", strings.TrimPrefix(*user, "@"), sc.Probability)
+		for _, c := range sc.Contributions {
+			fmt.Printf("  %-18s value=%.2f weight=%+.2f logit=%+.2f\n", c.Signal, c.Value, c.Weight, c.Logit)
+		}
+	case "train":
+		if *labels == "" { fmt.Println("error: -labels is required"); exit(1) }
+		fh, err := os.Open(*labels)
+		if err != nil { fmt.Println("error:", err); exit(1) }
+		ls, err := bots.ParseLabels(fh)
+		fh.Close()
+		if err != nil { fmt.Println("error:", err); exit(1) }
+		var samples []bots.Sample
+		for _, l := range ls {
+			f, err := features(l.Handle)
+			if err != nil { fmt.Printf("skipping @%s: %v\n", l.Handle, err); continue }
+			samples = append(samples, bots.Sample{Features: f, Bot: l.Bot})
+		}
+		before := bots.Default().Evaluate(samples)
+		topts := bots.TrainOptions{Epochs: *epochs, LR: *lr}
+		m, err := bots.Train(samples, topts)
+		if err != nil { fmt.Println("train error:", err); exit(1) }
+		m.Trained = now
+		val, err := bots.CrossValidate(samples, *folds, topts)
+		if err != nil { fmt.Println("warning: no held-out metrics:", err) }
+		m.Validation = val
+		if err := m.Save(*out); err != nil { fmt.Println("write error:", err); exit(1) }
+		fmt.Printf("Trained on %d accounts (built-in -> trained, training set): log_loss %.3f -> %.3f, brier %.3f -> %.3f, accuracy %.2f -> %.2f\n",
+			len(samples), before.LogLoss, m.Metrics.LogLoss, before.Brier, m.Metrics.Brier, before.Accuracy, m.Metrics.Accuracy)
+		if val != nil {
+			fmt.Printf("Held out (%d-fold): log_loss %.3f, brier %.3f, accuracy %.2f\n", val.Folds, val.Metrics.LogLoss, val.Metrics.Brier, val.Metrics.Accuracy)
+			fmt.Println("  calibration   n  predicted  observed")
+			for _, b := range val.Calibration {
+				if b.N == 0 { continue }
+				fmt.Printf("  %.1f-%.1f  %5d  %9.2f  %8.2f\n", b.Lo, b.Hi, b.N, b.Predicted, b.Observed)
+			}
+		}
+		for _, name := range bots.Signals { fmt.Printf("  %-18s %+.2f\n", name, m.Weights[name]) }
+		fmt.Println("Wrote", *out)
+	default:
+		fmt.Println(usage); exit(1)
+	}
+}
+
+// botScorer scores author bot likelihood for filters, policy facts, ranking and the audit
+// with the bot model at filters.botModel (its built-in weights until one is trained), or
+// returns nil for the profile heuristic when none is configured. Model features always
+// use the heuristic they were trained on.
+func botScorer(cfg config.Config) model.BotScorer {
+	if cfg.Filters.BotModel == "" { return nil }
+	m, err := bots.LoadOrDefault(cfg.Filters.BotModel)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "warning: bot model unavailable, using the profile heuristic:", err)
+		return nil
+	}
+	return m.BotProbability
+}
+
+// useEmbeddings routes interest relevance through the configured embedder, falling
+// back to keyword matching when it is off or unavailable.
+func useEmbeddings(ctx context.Context, cfg config.Config, db *sqlitevec.DB) *embed.Relevance {
+	rel, err := embed.ForConfig(ctx, cfg, db)
+	if err != nil {
+		fmt.Println("warning: embeddings unavailable, using keyword matching:", err)
+		return nil
+	}
+	if rel != nil { model.SetRelevance(rel) }
+	return rel
+}
+
+// warmBios embeds the profile texts ranking scores in one pass.
+func warmBios(ctx context.Context, rel *embed.Relevance, users []model.User) {
+	if rel == nil { return }
+	texts := make([]string, len(users))
+	for i, u := range users { texts[i] = u.Description + " " + u.Name }
+	_ = rel.Warm(ctx, texts) // failures are logged by rel
+}
+
+// cmdEmbed trains the local embedding model and inspects relevance scores.
+func cmdEmbed() {
+	usage := "usage: starseed embed train [-limit 5000] [-dim 64] [-out path] | embed score -text t"
+	if len(os.Args) < 3 { fmt.Println(usage); exit(1) }
+	sub := os.Args[2]
+	fs := flag.NewFlagSet("embed "+sub, flag.ExitOnError)
+	cfgPath := fs.String("config", "./starseed.yaml", "config path")
+	limit := fs.Int("limit", 5000, "train: most recent stored tweets to train on")
+	dim := fs.Int("dim", 64, "train: embedding dimensions")
+	out := fs.String("out", "", "train: where to write the model (default embeddings.modelPath)")
+	text := fs.String("text", "", "score: text to score")
+	_ = fs.Parse(os.Args[3:])
+	cfg, err := config.Load(*cfgPath)
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	db, err := sqlitevec.Open(cfg.Storage.DBPath)
+	if err != nil { fmt.Println("db error:", err); exit(1) }
+	defer db.Close()
+	ctx := context.Background()
+	switch sub {
+	case "train":
+		path := *out
+		if path == "" { path = cfg.Embeddings.ModelPath }
+		if path == "" { path = "./starseed_embed_model.json" }
+		docs, err := db.TweetTexts(ctx, *limit)
+		if err != nil { fmt.Println("error:", err); exit(1) }
+		m, err := embed.Train(docs, embed.TrainOptions{Dim: *dim}, time.Now().UTC())
+		if err != nil { fmt.Println("train error:", err); exit(1) }
+		if err := m.Save(path); err != nil { fmt.Println("write error:", err); exit(1) }
+		fmt.Printf("Trained %s on %d tweets; wrote %s\n", m.Name(), m.Docs, path)
+		if p := cfg.Embeddings.Provider; p != "" && p != "local" { fmt.Printf("Note: embeddings.provider is %s; set it to local to use this model.\n", p) }
+	case "score":
+		if *text == "" { fmt.Println("error: -text is required"); exit(1) }
+		rel, err := embed.ForConfig(ctx, cfg, db)
+		if err != nil { fmt.Println("error:", err); exit(1) }
+		if rel == nil { fmt.Println("Embeddings are off (embeddings.provider: none) or no interests are configured."); return }
+		sims, err := rel.Similarities(ctx, *text)
+		if err != nil { fmt.Println("error:", err); exit(1) }
+		fmt.Printf("relevance %.2f (keywords only %.2f)\n", rel.Relevance(*text), model.KeywordRelevance(*text, cfg.Interests.Keywords, cfg.Interests.Weights))
+		for i, name := range rel.Topics() { fmt.Printf("  %-24s cosine=%.3f\n", name, sims[i]) }
+	default:
+		fmt.Println(usage); exit(1)
+	}
+}
+
+// cmdAuditHistory prints the aggregate stats of past account audits.
+func cmdAuditHistory() {
+	fs := flag.NewFlagSet("audit history", flag.ExitOnError)
+	cfgPath := fs.String("config", "./starseed.yaml", "config path")
+	since := fs.String("since", "90d", "start: duration back from now (24h, 90d), RFC3339 or YYYY-MM-DD")
+	_ = fs.Parse(os.Args[3:])
+	cfg, err := config.Load(*cfgPath)
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	from, err := audit.ParseTime(*since, time.Now().UTC())
+	if err != nil { fmt.Println("error: -since:", err); exit(1) }
+	db, err := sqlitevec.Open(cfg.Storage.DBPath)
+	if err != nil { fmt.Println("db error:", err); exit(1) }
+	defer db.Close()
+	snaps, err := db.ListAuditSnapshots(context.Background(), from)
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	if len(snaps) == 0 { fmt.Println("No audits stored yet (run starseed audit)."); return }
+	if err := audit.WriteHistory(os.Stdout, snaps); err != nil { fmt.Println("error:", err); exit(1) }
+}
+
+// cmdAuditDecisions lists recorded engage decisions.
+func cmdAuditDecisions() {
+	fs := flag.NewFlagSet("audit decisions", flag.ExitOnError)
+	cfgPath := fs.String("config", "./starseed.yaml", "config path")
+	since := fs.String("since", "24h", "start: duration back from now (24h, 7d), RFC3339 or YYYY-MM-DD")
+	until := fs.String("until", "", "end (exclusive), same formats as -since (default now)")
+	author := fs.String("author", "", "author @handle or user ID")
+	outcome := fs.String("outcome", "", "outcome (queued, skipped, deferred, rejected, posted, dry_run, halted, error, allowed)")
+	kind := fs.String("kind", "", "decision kind (engage_gate, engage_candidate, auto_post)")
+	limit := fs.Int("limit", 100, "max decisions (0 for all)")
+	asJSON := fs.Bool("json", false, "print JSON lines with the full decision detail")
+	_ = fs.Parse(os.Args[3:])
+	cfg, err := config.Load(*cfgPath)
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	now := time.Now().UTC()
+	f := sqlitevec.DecisionFilter{Author: *author, Outcome: *outcome, Kind: *kind, Limit: *limit}
+	if f.Since, err = audit.ParseTime(*since, now); err != nil { fmt.Println("error: -since:", err); exit(1) }
+	if f.Until, err = audit.ParseTime(*until, now); err != nil { fmt.Println("error: -until:", err); exit(1) }
+	db, err := sqlitevec.Open(cfg.Storage.DBPath)
+	if err != nil { fmt.Println("db error:", err); exit(1) }
+	defer db.Close()
+	decisions, err := db.ListDecisions(context.Background(), f)
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	counts := map[string]int{}
+	for _, d := range decisions {
+		counts[d.Outcome]++
+		if *asJSON { fmt.Println(audit.JSON(d)) } else { fmt.Println(audit.Format(d)) }
+	}
+	if *asJSON { return }
+	outcomes := make([]string, 0, len(counts))
+	for o := range counts { outcomes = append(outcomes, fmt.Sprintf("%s=%d", o, counts[o])) }
+	sort.Strings(outcomes)
+	fmt.Printf("%d decisions %s\n", len(decisions), strings.Join(outcomes, " "))
 }
 
 func cmdSchedule() {
+	if len(os.Args) > 2 && os.Args[2] == "export" { cmdScheduleExport(); return }
 	fs := flag.NewFlagSet("schedule", flag.ExitOnError)
-	quiet := fs.String("quiet", "0,1,2,3,4,5", "quiet hours (UTC) comma-separated")
+	cfgPath := fs.String("config", "./starseed.yaml", "config path")
+	bin := fs.String("bin", "./starseed-nn/target/release/starseed-nn", "path to Rust NN binary")
+	modelPath := fs.String("model", "./starseed_model.json", "model path")
+	top := fs.Int("top", 10, "number of ranked windows to print")
 	_ = fs.Parse(os.Args[2:])
-	qh := parseHours(*quiet)
-	next := scheduleNext(qh)
-	fmt.Println("Next window:", next.Format(time.RFC3339))
+	cfg, err := config.Load(*cfgPath)
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	rules, err := schedule.Compile(cfg.Engagement)
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	loc := rules.Location()
+	now := time.Now().UTC()
+	fmt.Println("Next window:", rules.Next(now).In(loc).Format(time.RFC3339))
+	db, err := sqlitevec.Open(cfg.Storage.DBPath)
+	if err != nil { fmt.Println("db error:", err); return }
+	defer db.Close()
+	ranked, err := forecast.Forecast(context.Background(), db, forecast.ModelScorer(*bin, *modelPath), now, forecast.Options{Skip: rules.IsQuiet})
+	if err != nil { fmt.Println("forecast unavailable:", err); return }
+	fmt.Println("Best windows (next 24h):")
+	for i := 0; i < len(ranked) && i < *top; i++ {
+		fmt.Printf("%2d. %s  score=%.3f\n", i+1, ranked[i].Start.In(loc).Format(time.RFC3339), ranked[i].Score)
+	}
+}
+
+func cmdSuggest() {
+	if len(os.Args) < 3 || os.Args[2] != "preview" {
+		fmt.Println("usage: starseed suggest preview -id <tweetID> [-config path] [-draft]")
+		exit(1)
+	}
+	fs := flag.NewFlagSet("suggest preview", flag.ExitOnError)
+	cfgPath := fs.String("config", "./starseed.yaml", "config path")
+	id := fs.String("id", "", "tweet ID to render the prompt for")
+	draft := fs.Bool("draft", false, "also call the configured LLM and print its draft")
+	_ = fs.Parse(os.Args[3:])
+	if *id == "" { fmt.Println("error: -id is required"); exit(1) }
+	cfg, err := config.Load(*cfgPath)
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	pr, err := suggest.NewPrompter(cfg.Persona)
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	client := mustLoadClient(cfg)
+	ctx := context.Background()
+	tweets, err := client.GetTweetsByIDs(ctx, []string{*id})
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	if len(tweets) == 0 { fmt.Println("error: tweet not found:", *id); exit(1) }
+	d := suggest.PromptData{Account: cfg.Account.Username, Interests: cfg.Interests, Tweet: tweets[0]}
+	d.Author = lookupAuthors(ctx, client, tweets)[tweets[0].AuthorID]
+	prompt, err := pr.Prompt(d)
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	heuristic, err := pr.Heuristic(d)
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	heuristic, _ = pr.Finalize(heuristic)
+	fmt.Printf("--- system ---\n%s\n--- user ---\n%s\n--- heuristic draft ---\n%s\n", prompt.System, prompt.User, heuristic)
+	if *draft {
+		out, err := suggest.DraftWithLLM(ctx, cfg.LLM, pr, d, heuristic)
+		if err != nil { fmt.Println("llm error:", err) }
+		fmt.Printf("--- llm draft ---\n%s\n", out)
+		v := suggest.NewValidator(cfg.Suggest.Rules, pr.Persona().MaxLength, nil)
+		if rej := v.Validate(out, suggest.DraftContext{Tweet: d.Tweet, Author: d.Author}); rej != nil {
+			fmt.Println("rejected:", rej.Error())
+		}
+	}
+}
+
+// cmdScheduleExport writes upcoming windows and queued drafts as an iCalendar feed.
+func cmdScheduleExport() {
+	fs := flag.NewFlagSet("schedule export", flag.ExitOnError)
+	cfgPath := fs.String("config", "./starseed.yaml", "config path")
+	format := fs.String("format", "ics", "export format (ics)")
+	out := fs.String("out", "./starseed_plan.ics", "output file ('-' for stdout)")
+	serve := fs.String("serve", "", "serve the feed at http://<addr>/calendar.ics instead of writing a file (e.g. 127.0.0.1:8788; a bare :port binds to localhost)")
+	refresh := fs.Duration("refresh", 5*time.Minute, "with -serve, how long a built feed is reused between polls")
+	bin := fs.String("bin", "./starseed-nn/target/release/starseed-nn", "path to Rust NN binary")
+	modelPath := fs.String("model", "./starseed_model.json", "model path")
+	top := fs.Int("top", 8, "number of recommended windows to export")
+	noSugs := fs.Bool("no-suggestions", false, "export windows only (skip queued drafts)")
+	_ = fs.Parse(os.Args[3:])
+	if *format != "ics" { fmt.Println("error: unsupported format", *format); exit(1) }
+	cfg, err := config.Load(*cfgPath)
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	rules, err := schedule.Compile(cfg.Engagement)
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	db, err := sqlitevec.Open(cfg.Storage.DBPath)
+	if err != nil { fmt.Println("db error:", err); exit(1) }
+	defer db.Close()
+	// Replies come from the review queue (drafted by engage), so exporting never
+	// runs discovery or the LLM.
+	build := func(ctx context.Context) ([]ical.Event, error) {
+		now := time.Now().UTC()
+		var events []ical.Event
+		ranked, err := forecast.Forecast(ctx, db, forecast.ModelScorer(*bin, *modelPath), now, forecast.Options{Skip: rules.IsQuiet})
+		if err != nil { fmt.Fprintln(os.Stderr, "forecast unavailable:", err) }
+		for i := 0; i < len(ranked) && i < *top; i++ {
+			w := ranked[i]
+			events = append(events, ical.Event{
+				UID:         ical.UID("window", w.Start.UTC().Format(time.RFC3339)),
+				Start:       w.Start,
+				End:         w.Start.Add(15 * time.Minute),
+				Summary:     fmt.Sprintf("Engagement window #%d", i+1),
+				Description: fmt.Sprintf("Predicted engagement score %.3f", w.Score),
+			})
+		}
+		if *noSugs { return events, nil }
+		drafts, err := db.ListDrafts(ctx, []string{queue.Pending, queue.Approved}, 0)
+		if err != nil { return nil, err }
+		for _, d := range drafts {
+			if d.Scheduled.IsZero() { continue }
+			link := "https://x.com/i/web/status/" + d.TweetID
+			events = append(events, ical.Event{
+				UID:         ical.UID("reply", d.TweetID),
+				Start:       d.Scheduled,
+				End:         d.Scheduled.Add(15 * time.Minute),
+				Summary:     "Reply: " + util.Truncate(d.TweetText, 60),
+				Description: fmt.Sprintf("Tweet: %s\n\nDraft (%s): %s\n\nWhy: %s\n%s", d.TweetText, d.State, d.Text, d.Why, link),
+				URL:         link,
+			})
+		}
+		return events, nil
+	}
+	if *serve != "" {
+		addr := *serve
+		if strings.HasPrefix(addr, ":") { addr = "127.0.0.1" + addr }
+		var (
+			mu     sync.Mutex
+			cached []ical.Event
+			built  time.Time
+		)
+		feed := func(r *http.Request) ([]ical.Event, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if !built.IsZero() && time.Since(built) < *refresh { return cached, nil }
+			events, err := build(r.Context())
+			if err != nil { return nil, err }
+			cached, built = events, time.Now()
+			return cached, nil
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/calendar.ics", ical.Handler("Starseed plan", feed))
+		fmt.Printf("Serving calendar at http://%s/calendar.ics\n", addr)
+		if err := http.ListenAndServe(addr, mux); err != nil { fmt.Println("error:", err); exit(1) }
+		return
+	}
+	events, err := build(context.Background())
+	if err != nil { fmt.Fprintln(os.Stderr, "error:", err); exit(1) }
+	w := os.Stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil { fmt.Println("error:", err); exit(1) }
+		defer f.Close()
+		w = f
+	}
+	if err := ical.Write(w, "Starseed plan", events); err != nil { fmt.Println("error:", err); exit(1) }
+	if *out != "-" { fmt.Printf("Wrote %d events to %s\n", len(events), *out) }
+}
+
+// cmdQueue reviews drafted replies: list/show/approve/edit/reject, and post approved ones.
+func cmdQueue() {
+	usage := "usage: starseed queue list|show|approve|edit|reject|post [-config path] [-id N] ..."
+	if len(os.Args) < 3 { fmt.Println(usage); exit(1) }
+	sub := os.Args[2]
+	fs := flag.NewFlagSet("queue "+sub, flag.ExitOnError)
+	cfgPath := fs.String("config", "./starseed.yaml", "config path")
+	id := fs.Int64("id", 0, "draft ID")
+	state := fs.String("state", "pending,approved", "list: comma-separated states (empty for all)")
+	limit := fs.Int("limit", 50, "list: max drafts")
+	text := fs.String("text", "", "edit: replacement text")
+	force := fs.Bool("force", false, "edit: save even if the validator rejects the text")
+	at := fs.String("at", "", "approve: reschedule to this RFC3339 time")
+	reason := fs.String("reason", "", "reject: reason to record")
+	dryRun := fs.Bool("dry-run", false, "post: show what would be posted")
+	_ = fs.Parse(os.Args[3:])
+	cfg, err := config.Load(*cfgPath)
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	db, err := sqlitevec.Open(cfg.Storage.DBPath)
+	if err != nil { fmt.Println("db error:", err); exit(1) }
+	defer db.Close()
+	ctx := context.Background()
+	now := time.Now().UTC()
+	loc := time.UTC
+	if rules, err := schedule.Compile(cfg.Engagement); err == nil { loc = rules.Location() }
+	needID := func() {
+		if *id == 0 { fmt.Println("error: -id is required"); exit(1) }
+	}
+	ttl := time.Duration(cfg.Engagement.DraftTTLHours) * time.Hour
+	switch sub {
+	case "list":
+		if _, err := queue.Expire(ctx, db, ttl, now); err != nil { fmt.Println("error:", err); exit(1) }
+		var states []string
+		if *state != "" { states = strings.Split(*state, ",") }
+		drafts, err := db.ListDrafts(ctx, states, *limit)
+		if err != nil { fmt.Println("error:", err); exit(1) }
+		for _, d := range drafts {
+			fmt.Printf("#%d [%s] when=%s score=%.2f tweet=%s\n  %s\n", d.ID, d.State, d.Scheduled.In(loc).Format(time.RFC3339), d.Score, d.TweetID, util.Truncate(d.Text, 100))
+		}
+		if len(drafts) == 0 { fmt.Println("Queue is empty.") }
+	case "show":
+		needID()
+		d, err := db.GetDraft(ctx, *id)
+		if err != nil { fmt.Println("error:", err); exit(1) }
+		fmt.Printf("#%d [%s] scheduled %s (created %s)\n", d.ID, d.State, d.Scheduled.In(loc).Format(time.RFC3339), d.Created.In(loc).Format(time.RFC3339))
+		fmt.Printf("Tweet %s by %s (%s):\n  %s\n", d.TweetID, d.AuthorID, d.TweetCreated.In(loc).Format(time.RFC3339), d.TweetText)
+		fmt.Printf("Draft (score %.2f, %s):\n  %s\n", d.Score, d.Why, d.Text)
+		var meta queue.Meta
+		_ = json.Unmarshal([]byte(d.Meta), &meta)
+		for _, alt := range meta.Alternates {
+			fmt.Printf("  alt (%s, %.2f): %s\n", alt.Source, alt.Score, alt.Text)
+		}
+		if d.PostedID != "" { fmt.Println("Posted as:", d.PostedID) }
+		if d.Note != "" { fmt.Println("Note:", d.Note) }
+	case "approve":
+		needID()
+		var when time.Time
+		if *at != "" {
+			when, err = time.Parse(time.RFC3339, *at)
+			if err != nil { fmt.Println("error: bad -at:", err); exit(1) }
+		}
+		d, err := queue.Approve(ctx, db, *id, when, now)
+		if err != nil { fmt.Println("error:", err); exit(1) }
+		fmt.Printf("Approved #%d for %s\n", d.ID, d.Scheduled.In(loc).Format(time.RFC3339))
+	case "edit":
+		needID()
+		d, err := db.GetDraft(ctx, *id)
+		if err != nil { fmt.Println("error:", err); exit(1) }
+		pr, err := suggest.NewPrompter(cfg.Persona)
+		if err != nil { fmt.Println("error:", err); exit(1) }
+		v := suggest.NewValidator(cfg.Suggest.Rules, pr.Persona().MaxLength, pastReplies(ctx, db, cfg, now))
+		if rej := v.Validate(*text, queue.DraftContext(d)); rej != nil && !*force {
+			fmt.Println("rejected:", rej.Error(), "(use -force to save anyway)")
+			exit(1)
+		}
+		d, err = queue.Edit(ctx, db, *id, *text, now)
+		if err != nil { fmt.Println("error:", err); exit(1) }
+		fmt.Printf("Updated #%d [%s]\n", d.ID, d.State)
+	case "reject":
+		needID()
+		d, err := queue.Reject(ctx, db, *id, *reason, now)
+		if err != nil { fmt.Println("error:", err); exit(1) }
+		fmt.Printf("Rejected #%d\n", d.ID)
+	case "post":
+		client := mustLoadClient(cfg)
+		res, err := queue.PostDue(ctx, db, client, cfg.Engagement, now, *dryRun)
+		for _, r := range res {
+			if r.Posted { fmt.Printf("Posted #%d as %s\n", r.Draft.ID, r.Draft.PostedID) } else { fmt.Printf("Not posted #%d: %s\n", r.Draft.ID, r.Reason) }
+		}
+		if err != nil { fmt.Println("error:", err); exit(1) }
+		if len(res) == 0 { fmt.Println("No approved drafts are due.") }
+	default:
+		fmt.Println(usage)
+		exit(1)
+	}
+}
+
+// cmdPolicy evaluates the engagement policy against stored tweets (with the same candidate
+// facts engage uses) or queued drafts, so rule changes can be checked before they gate anything.
+func cmdPolicy() {
+	if len(os.Args) < 3 || os.Args[2] != "test" {
+		fmt.Println("usage: starseed policy test [-config path] [-policy file] [-at RFC3339] [-source tweets|drafts] [-state s1,s2] [-limit N]")
+		exit(1)
+	}
+	fs := flag.NewFlagSet("policy test", flag.ExitOnError)
+	cfgPath := fs.String("config", "./starseed.yaml", "config path")
+	file := fs.String("policy", "", "policy file (default engagement.policy or the built-in policy)")
+	at := fs.String("at", "", "evaluate as of this RFC3339 time (default now)")
+	source := fs.String("source", "tweets", "what to evaluate: cached tweets as engage candidates, or queued drafts")
+	state := fs.String("state", "", "with -source drafts, comma-separated draft states (default all)")
+	limit := fs.Int("limit", 100, "max tweets or drafts")
+	verbose := fs.Bool("v", false, "print every candidate, not just the summary and non-allow decisions")
+	_ = fs.Parse(os.Args[3:])
+	cfg, err := config.Load(*cfgPath)
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	if *file != "" { cfg.Engagement.Policy = *file }
+	pol, err := policy.ForConfig(cfg)
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	rules, err := schedule.Compile(cfg.Engagement)
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	now := time.Now().UTC()
+	if *at != "" {
+		if now, err = time.Parse(time.RFC3339, *at); err != nil { fmt.Println("error: bad -at:", err); exit(1) }
+	}
+	db, err := sqlitevec.Open(cfg.Storage.DBPath)
+	if err != nil { fmt.Println("db error:", err); exit(1) }
+	defer db.Close()
+	ctx := context.Background()
+	bot := botScorer(cfg)
+	type candidate struct {
+		label, text string
+		target      engage.Target
+		f           policy.Facts
+	}
+	var cands []candidate
+	var tweets []model.Tweet
+	switch *source {
+	case "tweets":
+		tweets, err = db.RecentTweets(ctx, *limit)
+		if err != nil { fmt.Println("error:", err); exit(1) }
+		var ids []string
+		for _, t := range tweets { ids = append(ids, t.AuthorID) }
+		authors, err := db.GetUsers(ctx, ids)
+		if err != nil { fmt.Println("error:", err); exit(1) }
+		ranked := rankAuthors(ctx, db, cfg, authors, bot, now)
+		for _, t := range tweets {
+			f := candidateFacts(t, authors[t.AuthorID], bot, now)
+			if a, ok := ranked[t.AuthorID]; ok { f["author.score"] = a.FinalScore }
+			conv := t.ConversationID
+			if conv == "" { conv = t.ID }
+			cands = append(cands, candidate{label: "tweet=" + t.ID, text: t.Text, target: engage.Target{UserID: t.AuthorID, ConversationID: conv}, f: f})
+		}
+	case "drafts":
+		var states []string
+		if *state != "" { states = strings.Split(*state, ",") }
+		drafts, err := db.ListDrafts(ctx, states, *limit)
+		if err != nil { fmt.Println("error:", err); exit(1) }
+		for _, d := range drafts {
+			cands = append(cands, candidate{label: fmt.Sprintf("#%d tweet=%s", d.ID, d.TweetID), text: d.TweetText, target: queue.DraftTarget(d), f: queue.DraftFacts(d, now)})
+		}
+	default:
+		fmt.Println("error: -source must be tweets or drafts")
+		exit(1)
+	}
+	pred, thr, hasModel := predictWindow(ctx, db, tweets, now)
+	budget := engage.NewBudget(db, cfg.Engagement)
+	v, _ := budget.Check(ctx, "reply", now)
+	counts := map[string]int{}
+	byRule := map[string]int{}
+	for _, c := range cands {
+		cv, _ := budget.CheckTarget(ctx, "reply", c.target, now)
+		f := c.f.WithTime(now, rules.Location(), rules.IsQuiet(now))
+		f["budget.allowed"], f["cooldown.allowed"] = v.Allowed, cv.Allowed
+		if hasModel { f["prediction"], f["model.pass"] = float64(pred), pred >= thr }
+		res := pol.Evaluate(f)
+		counts[res.Decision]++
+		byRule[res.Rule]++
+		if *verbose || res.Decision != policy.Allow {
+			fmt.Printf("%s %-5s rule=%s (%s)\n  %s\n", c.label, res.Decision, res.Rule, res.Reason, util.Truncate(c.text, 90))
+		}
+	}
+	fmt.Printf("Evaluated %d %s at %s: allow=%d deny=%d defer=%d\n", len(cands), *source, now.In(rules.Location()).Format(time.RFC3339), counts[policy.Allow], counts[policy.Deny], counts[policy.Defer])
+	names := make([]string, 0, len(byRule))
+	for r := range byRule { names = append(names, r) }
+	sort.Strings(names)
+	for _, r := range names { fmt.Printf("  %-16s %d\n", r, byRule[r]) }
+}
+
+// cmdTUI opens the keyboard-driven triage screen over queued drafts. With -script, keys are
+// read from a file and the screen is written to stdout (no raw terminal needed).
+func cmdTUI() {
+	fs := flag.NewFlagSet("tui", flag.ExitOnError)
+	cfgPath := fs.String("config", "./starseed.yaml", "config path")
+	state := fs.String("state", "pending,approved", "comma-separated draft states to show")
+	limit := fs.Int("limit", 200, "max drafts")
+	script := fs.String("script", "", "read keys from this file instead of the terminal")
+	_ = fs.Parse(os.Args[2:])
+	cfg, err := config.Load(*cfgPath)
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	db, err := sqlitevec.Open(cfg.Storage.DBPath)
+	if err != nil { fmt.Println("db error:", err); exit(1) }
+	defer db.Close()
+	ctx := context.Background()
+	now := time.Now().UTC()
+	if _, err := queue.Expire(ctx, db, time.Duration(cfg.Engagement.DraftTTLHours)*time.Hour, now); err != nil { fmt.Println("error:", err); exit(1) }
+	drafts, err := db.ListDrafts(ctx, strings.Split(*state, ","), *limit)
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	pr, err := suggest.NewPrompter(cfg.Persona)
+	if err != nil { fmt.Println("error:", err); exit(1) }
+	v := suggest.NewValidator(cfg.Suggest.Rules, pr.Persona().MaxLength, pastReplies(ctx, db, cfg, now))
+	act := tui.QueueActions{DB: db, Check: func(d sqlitevec.Draft, text string) error {
+		if rej := v.Validate(text, queue.DraftContext(d)); rej != nil { return rej }
+		return nil
+	}}
+	var in io.Reader = os.Stdin
+	width, height := 100, 30
+	if *script != "" {
+		f, err := os.Open(*script)
+		if err != nil { fmt.Println("error:", err); exit(1) }
+		defer f.Close()
+		in = f
+	} else {
+		width, height = tui.Size(os.Stdin)
+		restore, err := tui.MakeRaw(os.Stdin)
+		if err != nil { fmt.Println("error:", err); exit(1) }
+		defer restore()
+	}
+	app := tui.New(tui.Items(drafts), act, width, height)
+	if rules, err := schedule.Compile(cfg.Engagement); err == nil { app.Loc = rules.Location() }
+	if err := app.Run(in, os.Stdout); err != nil { fmt.Println("error:", err) }
 }
 
 func cmdIngestEvents() {
@@ -267,13 +1489,13 @@ func cmdIngestEvents() {
     hours := fs.Int("hours", 6, "how many recent hours to process")
     _ = fs.Parse(os.Args[2:])
     cfg, err := config.Load(*cfgPath)
-    if err != nil { fmt.Println("error:", err); os.Exit(1) }
+    if err != nil { fmt.Println("error:", err); exit(1) }
     client := mustLoadClient(cfg)
     ctx := context.Background()
     me, err := client.GetUserByUsername(ctx, cfg.Account.Username)
-    if err != nil { fmt.Println("error:", err); os.Exit(1) }
+    if err != nil { fmt.Println("error:", err); exit(1) }
     db, err := sqlitevec.Open(cfg.Storage.DBPath)
-    if err != nil { fmt.Println("db error:", err); os.Exit(1) }
+    if err != nil { fmt.Println("db error:", err); exit(1) }
     defer db.Close()
     since := time.Now().UTC().Add(time.Duration(-*hours) * time.Hour)
     if err := ingest.IngestEngagements(ctx, db, client, me.ID, cfg.Account.Username, since); err != nil { fmt.Println("ingest error:", err) }
@@ -287,22 +1509,28 @@ func cmdIngestLoop() {
     cfgPath := fs.String("config", "./starseed.yaml", "config path")
     horizonStr := fs.String("horizon", "6h", "how far back to consider on first run (e.g., 6h, 1h)")
     intervalStr := fs.String("interval", "5m", "ingestion interval (e.g., 5m, 1m)")
+    driftModel := fs.String("drift-model", "./starseed_model.json", "model whose training stats are checked for drift each interval (empty disables)")
+    bin := fs.String("bin", "./starseed-nn/target/release/starseed-nn", "path to Rust NN binary (prediction drift)")
     _ = fs.Parse(os.Args[2:])
     cfg, err := config.Load(*cfgPath)
-    if err != nil { fmt.Println("error:", err); os.Exit(1) }
+    if err != nil { fmt.Println("error:", err); exit(1) }
     client := mustLoadClient(cfg)
     db, err := sqlitevec.Open(cfg.Storage.DBPath)
-    if err != nil { fmt.Println("db error:", err); os.Exit(1) }
+    if err != nil { fmt.Println("db error:", err); exit(1) }
     defer db.Close()
     horizon, err := time.ParseDuration(*horizonStr)
-    if err != nil { fmt.Println("bad horizon:", err); os.Exit(1) }
+    if err != nil { fmt.Println("bad horizon:", err); exit(1) }
     interval, err := time.ParseDuration(*intervalStr)
-    if err != nil { fmt.Println("bad interval:", err); os.Exit(1) }
+    if err != nil { fmt.Println("bad interval:", err); exit(1) }
     // Run until interrupted
     ctx := context.Background()
+    if *driftModel != "" {
+        opts := jobs.DriftOptions{ModelPath: *driftModel, BinPath: *bin, Window: 24 * time.Hour, Thresholds: nn.DefaultDriftThresholds()}
+        go jobs.RunDriftLoop(ctx, db, opts, interval)
+    }
     if err := jobs.RunIngestionLoop(ctx, db, client, cfg, horizon, interval); err != nil {
         fmt.Println("ingest loop error:", err)
-        os.Exit(1)
+        exit(1)
     }
 }
 
@@ -315,20 +1543,20 @@ func cmdNNTrain() {
     epochs := fs.Int("epochs", 10, "epochs")
     _ = fs.Parse(os.Args[2:])
     cfg, err := config.Load(*cfgPath)
-    if err != nil { fmt.Println("error:", err); os.Exit(1) }
+    if err != nil { fmt.Println("error:", err); exit(1) }
     client := mustLoadClient(cfg)
     ctx := context.Background()
     me, err := client.GetUserByUsername(ctx, cfg.Account.Username)
-    if err != nil { fmt.Println("error:", err); os.Exit(1) }
+    if err != nil { fmt.Println("error:", err); exit(1) }
     follows, err := client.GetFollowing(ctx, me.ID, 100)
-    if err != nil { fmt.Println("error:", err); os.Exit(1) }
+    if err != nil { fmt.Println("error:", err); exit(1) }
     // Build samples over the last few hours from followings' tweets (proxy)
     timeline, _ := ingest.FromFollowing(ctx, client, follows, 5, 300)
     authors, _ := ingest.CollectAuthors(ctx, client, timeline)
     var samples []nn.FeatureVector
     // Persist features in vector DB for rolling and later training
     db, err := sqlitevec.Open(cfg.Storage.DBPath)
-    if err != nil { fmt.Println("db error:", err); os.Exit(1) }
+    if err != nil { fmt.Println("db error:", err); exit(1) }
     defer db.Close()
     now := time.Now().UTC().Add(-6 * time.Hour)
     for w := 0; w < 24; w++ { // 6 hours in 15-min windows
@@ -338,7 +1566,9 @@ func cmdNNTrain() {
         samples = append(samples, fv)
         _ = db.PutFeature(ctx, ws, fv.X, nil, map[string]any{"source":"train-window"})
     }
-    if err := nn.Train(*bin, *modelOut, samples, *hidden, *epochs, 0.01); err != nil { fmt.Println("train error:", err); os.Exit(1) }
+    candidate := *modelOut + ".candidate"
+    if err := nn.Train(*bin, candidate, samples, *hidden, *epochs, 0.01); err != nil { _ = os.Remove(candidate); fmt.Println("train error:", err); exit(1) }
+    if err := nn.Promote(candidate, *modelOut); err != nil { fmt.Println("train error:", err); exit(1) }
     fmt.Println("Model written to:", *modelOut)
 }
 
@@ -349,24 +1579,24 @@ func cmdNNInfer() {
     modelPath := fs.String("model", "./starseed_model.json", "model path")
     _ = fs.Parse(os.Args[2:])
     cfg, err := config.Load(*cfgPath)
-    if err != nil { fmt.Println("error:", err); os.Exit(1) }
+    if err != nil { fmt.Println("error:", err); exit(1) }
     client := mustLoadClient(cfg)
     ctx := context.Background()
     me, err := client.GetUserByUsername(ctx, cfg.Account.Username)
-    if err != nil { fmt.Println("error:", err); os.Exit(1) }
+    if err != nil { fmt.Println("error:", err); exit(1) }
     follows, err := client.GetFollowing(ctx, me.ID, 100)
-    if err != nil { fmt.Println("error:", err); os.Exit(1) }
+    if err != nil { fmt.Println("error:", err); exit(1) }
     timeline, _ := ingest.FromFollowing(ctx, client, follows, 5, 100)
     authors, _ := ingest.CollectAuthors(ctx, client, timeline)
     ws := time.Now().UTC().Add(-15 * time.Minute)
     // open DB to leverage rolling history during inference feature build
     db, err := sqlitevec.Open(cfg.Storage.DBPath)
-    if err != nil { fmt.Println("db error:", err); os.Exit(1) }
+    if err != nil { fmt.Println("db error:", err); exit(1) }
     defer db.Close()
     fv, _ := nn.BuildFeaturesWithHistory(ctx, db, ws, timeline, nil)
     nn.AugmentMeta(&fv, timeline, authors, cfg.Interests.Keywords, cfg.Interests.Weights)
     preds, err := nn.Infer(*bin, *modelPath, []nn.FeatureVector{fv})
-    if err != nil { fmt.Println("infer error:", err); os.Exit(1) }
+    if err != nil { fmt.Println("infer error:", err); exit(1) }
     if len(preds) > 0 { fmt.Printf("pred next-window reply proxy: %.3f\n", preds[0][0]) }
 }
 
@@ -376,26 +1606,135 @@ func cmdNNTrainDB() {
     bin := fs.String("bin", "./starseed-nn/target/release/starseed-nn", "path to Rust NN binary")
     out := fs.String("out", "./starseed_model.json", "model path")
     hours := fs.Int("hours", 24, "train on last N hours")
+    tuned := fs.Bool("tuned", false, "use the best configuration from the latest nn-tune run")
     _ = fs.Parse(os.Args[2:])
     cfg, err := config.Load(*cfgPath)
-    if err != nil { fmt.Println("error:", err); os.Exit(1) }
+    if err != nil { fmt.Println("error:", err); exit(1) }
     db, err := sqlitevec.Open(cfg.Storage.DBPath)
-    if err != nil { fmt.Println("db error:", err); os.Exit(1) }
+    if err != nil { fmt.Println("db error:", err); exit(1) }
     defer db.Close()
     end := time.Now().UTC()
     start := end.Add(-time.Duration(*hours) * time.Hour)
-    if err := nn.TrainFromDB(context.Background(), db, start, end, *bin, *out); err != nil { fmt.Println("train-db error:", err); os.Exit(1) }
+    opts := nn.DefaultTrainOptions()
+    if *tuned {
+        best, score, err := nn.LoadBestTrainOptions(context.Background(), db)
+        if err != nil { fmt.Println("no tuned configuration:", err); exit(1) }
+        opts = best
+        fmt.Printf("Using tuned params hidden=%d lr=%g epochs=%d val-split=%g (cv mse=%.4f)\n", opts.Hidden, opts.LR, opts.Epochs, opts.ValSplit, score)
+    }
+    ctx := context.Background()
+    if at, ok := jobs.RetrainFlag(ctx, db); ok { fmt.Println("Retraining after drift flagged at", at.Local().Format(time.DateTime)) }
+    if err := nn.TrainFromDBWithOptions(ctx, db, start, end, *bin, *out, opts); err != nil { fmt.Println("train-db error:", err); exit(1) }
+    if err := jobs.ClearRetrainFlag(ctx, db); err != nil { fmt.Println("warning: retrain flag not cleared:", err) }
     fmt.Println("Model written to:", *out)
 }
 
-func parseHours(s string) []int {
-	var out []int
-	for _, p := range splitAndTrim(s) {
-		var h int
-		_, _ = fmt.Sscanf(p, "%d", &h)
-		if h >= 0 && h <= 23 { out = append(out, h) }
-	}
-	return out
+func cmdNNTune() {
+    fs := flag.NewFlagSet("nn-tune", flag.ExitOnError)
+    cfgPath := fs.String("config", "./starseed.yaml", "config path")
+    bin := fs.String("bin", "./starseed-nn/target/release/starseed-nn", "path to Rust NN binary")
+    hours := fs.Int("hours", 72, "tune on last N hours of labeled windows")
+    mode := fs.String("mode", "random", "search mode: grid or random")
+    trials := fs.Int("trials", 20, "random mode: number of configurations")
+    folds := fs.Int("folds", 3, "time-ordered cross-validation folds")
+    workers := fs.Int("workers", 4, "trials run in parallel")
+    seed := fs.Int64("seed", time.Now().UnixNano(), "random seed")
+    def := nn.DefaultTuneSpace()
+    hidden := fs.String("hidden", joinInts(def.Hidden), "hidden sizes, comma-separated")
+    lrs := fs.String("lr", joinFloats(def.LR), "learning rates, comma-separated")
+    epochs := fs.String("epochs", joinInts(def.Epochs), "epoch counts, comma-separated")
+    splits := fs.String("val-split", joinFloats(def.ValSplit), "validation splits, comma-separated")
+    out := fs.String("out", "./starseed_tune.json", "where to write the best configuration")
+    _ = fs.Parse(os.Args[2:])
+    if *mode != "grid" && *mode != "random" { fmt.Println("error: -mode must be grid or random"); exit(1) }
+    cfg, err := config.Load(*cfgPath)
+    if err != nil { fmt.Println("error:", err); exit(1) }
+    db, err := sqlitevec.Open(cfg.Storage.DBPath)
+    if err != nil { fmt.Println("db error:", err); exit(1) }
+    defer db.Close()
+    ctx := context.Background()
+    end := time.Now().UTC()
+    samples, err := nn.LoadLabeledSamples(ctx, db, end.Add(-time.Duration(*hours)*time.Hour), end)
+    if err != nil { fmt.Println("load error:", err); exit(1) }
+    space := nn.TuneSpace{Hidden: parseInts(*hidden), LR: parseFloats(*lrs), Epochs: parseInts(*epochs), ValSplit: parseFloats(*splits)}
+    opts := nn.TuneOptions{Mode: *mode, Trials: *trials, Folds: *folds, Workers: *workers, Seed: *seed}
+    results, err := nn.Tune(ctx, samples, space, opts, nn.BinaryFoldRunner(*bin))
+    if err != nil { fmt.Println("tune error:", err); exit(1) }
+    runID := end.Format("20060102T150405Z")
+    if err := nn.SaveTrials(ctx, db, runID, results); err != nil { fmt.Println("save error:", err) }
+    for i := 0; i < len(results) && i < 10; i++ {
+        r := results[i]
+        if r.Err != "" { fmt.Printf("hidden=%d lr=%g epochs=%d val-split=%g error=%s\n", r.Params.Hidden, r.Params.LR, r.Params.Epochs, r.Params.ValSplit, r.Err); continue }
+        fmt.Printf("hidden=%d lr=%g epochs=%d val-split=%g cv_mse=%.4f\n", r.Params.Hidden, r.Params.LR, r.Params.Epochs, r.Params.ValSplit, r.Score)
+    }
+    if len(results) == 0 || results[0].Err != "" { fmt.Println("no successful trials"); exit(1) }
+    b, _ := json.MarshalIndent(results[0].Params, "", "  ")
+    if err := os.WriteFile(*out, b, 0o644); err != nil { fmt.Println("write error:", err); exit(1) }
+    fmt.Printf("Run %s: best configuration written to %s (train with nn-train-db -tuned)\n", runID, *out)
+}
+
+func parseInts(s string) []int {
+    var out []int
+    for _, p := range splitAndTrim(s) {
+        if v, err := strconv.Atoi(p); err == nil && v > 0 { out = append(out, v) }
+    }
+    return out
+}
+
+func parseFloats(s string) []float32 {
+    var out []float32
+    for _, p := range splitAndTrim(s) {
+        if v, err := strconv.ParseFloat(p, 32); err == nil && v > 0 { out = append(out, float32(v)) }
+    }
+    return out
+}
+
+func joinInts(v []int) string {
+    parts := make([]string, len(v))
+    for i := range v { parts[i] = strconv.Itoa(v[i]) }
+    return strings.Join(parts, ",")
+}
+
+func joinFloats(v []float32) string {
+    parts := make([]string, len(v))
+    for i := range v { parts[i] = strconv.FormatFloat(float64(v[i]), 'g', -1, 32) }
+    return strings.Join(parts, ",")
+}
+
+func cmdNNDrift() {
+    fs := flag.NewFlagSet("nn-drift", flag.ExitOnError)
+    cfgPath := fs.String("config", "./starseed.yaml", "config path")
+    bin := fs.String("bin", "./starseed-nn/target/release/starseed-nn", "path to Rust NN binary (prediction drift)")
+    modelPath := fs.String("model", "./starseed_model.json", "model path")
+    hours := fs.Int("hours", 24, "compare the last N hours of windows")
+    def := nn.DefaultDriftThresholds()
+    psi := fs.Float64("psi", def.PSI, "PSI threshold per feature")
+    shift := fs.Float64("shift", def.MeanShift, "mean-shift threshold (training std units)")
+    minDrifted := fs.Int("min-drifted", def.MinDrifted, "drifted features needed to flag retraining")
+    _ = fs.Parse(os.Args[2:])
+    cfg, err := config.Load(*cfgPath)
+    if err != nil { fmt.Println("error:", err); exit(1) }
+    db, err := sqlitevec.Open(cfg.Storage.DBPath)
+    if err != nil { fmt.Println("db error:", err); exit(1) }
+    defer db.Close()
+    opts := jobs.DriftOptions{ModelPath: *modelPath, BinPath: *bin, Window: time.Duration(*hours) * time.Hour,
+        Thresholds: nn.DriftThresholds{PSI: *psi, MeanShift: *shift, MinDrifted: *minDrifted}}
+    rep, err := jobs.RunDriftCheck(context.Background(), db, opts)
+    if err != nil { fmt.Println("drift error:", err); exit(1) }
+    fmt.Printf("windows=%d drifted=%d\n", rep.Samples, rep.Drifted)
+    for _, f := range rep.Features {
+        mark := ""
+        if f.Drifted { mark = " DRIFT" }
+        fmt.Printf("%-16s psi=%.3f shift=%.2f%s\n", f.Name, f.PSI, f.MeanShift, mark)
+    }
+    if rep.Prediction != nil {
+        fmt.Printf("%-16s psi=%.3f shift=%.2f\n", "prediction", rep.Prediction.PSI, rep.Prediction.MeanShift)
+    }
+    if rep.RetrainNeeded {
+        fmt.Println("Retraining recommended.")
+    } else if at, ok := jobs.RetrainFlag(context.Background(), db); ok {
+        fmt.Println("Retraining recommended (drift flagged at", at.Local().Format(time.DateTime)+"; cleared by nn-train-db).")
+    }
 }
 
 func splitAndTrim(s string) []string {
@@ -432,7 +1771,3 @@ func splitLines(s string) []string {
     if cur != "" { out = append(out, cur) }
     return out
 }
-
-func scheduleNext(q []int) time.Time {
-	return schedule.NextWindow(time.Now().UTC(), q)
-}
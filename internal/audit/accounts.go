@@ -0,0 +1,203 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"starseed/internal/bots"
+	"starseed/internal/model"
+	"starseed/internal/util"
+)
+
+// Relations of an audited account to us.
+const (
+	Following = "following"
+	Follower  = "follower"
+	Mutual    = "mutual"
+)
+
+// Client is the part of the X client the account audit needs.
+type Client interface {
+	GetFollowing(ctx context.Context, userID string, limit int) ([]model.User, error)
+	GetFollowers(ctx context.Context, userID string, limit int) ([]model.User, error)
+	GetUserTimeline(ctx context.Context, userID string, limit int) ([]model.Tweet, error)
+}
+
+// Options control how many accounts and tweets are fetched and when an account counts
+// as suspicious (the filters' bot and organic thresholds).
+type Options struct {
+	Limit      int // max followings and max followers (default 1000)
+	Tweets     int // recent tweets sampled per account (0 = profile signals only)
+	MaxSampled int // accounts whose tweets are fetched, most suspicious profile first (0 = all)
+	MaxBot     float64
+	MinOrganic float64
+	// Bots scores bot likelihood from profile and tweets (model.BotLikelihood when nil)
+	Bots *bots.Model
+}
+
+// Account is one audited account with its scores and the signals that triggered.
+type Account struct {
+	User     model.User `json:"user"`
+	Relation string     `json:"relation"`
+	Bot      float64    `json:"bot"`
+	// Mean organic score of the sampled tweets; Sampled is 0 when none were fetched
+	Organic    float64  `json:"organic"`
+	Sampled    int      `json:"sampled"`
+	Suspicion  float64  `json:"suspicion"`
+	Suspicious bool     `json:"suspicious"`
+	Signals    []string `json:"signals,omitempty"`
+}
+
+// Stats aggregates one audit.
+type Stats struct {
+	Following   int     `json:"following"`
+	Followers   int     `json:"followers"`
+	Mutual      int     `json:"mutual"`
+	Suspicious  int     `json:"suspicious"`
+	MeanBot     float64 `json:"mean_bot"`
+	MeanOrganic float64 `json:"mean_organic"`
+	// Suspicious accounts by relation and how often each signal fired
+	ByRelation map[string]int `json:"by_relation"`
+	BySignal   map[string]int `json:"by_signal"`
+	// Timeline fetches that failed; those accounts are scored on their profile only
+	FetchErrors int `json:"fetch_errors"`
+}
+
+// Report is the result of an account audit, accounts ranked most suspicious first.
+type Report struct {
+	At       time.Time `json:"at"`
+	Accounts []Account `json:"accounts"`
+	Stats    Stats     `json:"stats"`
+}
+
+// Accounts fetches the followings and followers of userID, scores each account's bot
+// likelihood and the organic score of its recent tweets, and ranks them.
+func Accounts(ctx context.Context, c Client, userID string, opts Options, now time.Time) (Report, error) {
+	if opts.Limit <= 0 { opts.Limit = 1000 }
+	following, err := c.GetFollowing(ctx, userID, opts.Limit)
+	if err != nil { return Report{}, fmt.Errorf("following: %w", err) }
+	followers, err := c.GetFollowers(ctx, userID, opts.Limit)
+	if err != nil { return Report{}, fmt.Errorf("followers: %w", err) }
+	byID := map[string]*Account{}
+	var order []string
+	add := func(users []model.User, rel string) {
+		for _, u := range users {
+			if a, ok := byID[u.ID]; ok {
+				if a.Relation != rel { a.Relation = Mutual }
+				continue
+			}
+			a := &Account{User: u, Relation: rel}
+			if opts.Bots != nil { a.Bot = opts.Bots.Probability(bots.Extract(u, nil, now)) } else { a.Bot = model.BotLikelihood(u) }
+			byID[u.ID] = a
+			order = append(order, u.ID)
+		}
+	}
+	add(following, Following)
+	add(followers, Follower)
+	accounts := make([]Account, 0, len(order))
+	for _, id := range order { accounts = append(accounts, *byID[id]) }
+	// Sample tweets for the accounts whose profiles look worst first, so a capped run
+	// spends its API calls where they matter
+	sort.SliceStable(accounts, func(i, j int) bool { return accounts[i].Bot > accounts[j].Bot })
+	failed := 0
+	for i := range accounts {
+		var tweets []model.Tweet
+		if opts.Tweets > 0 && (opts.MaxSampled <= 0 || i < opts.MaxSampled) {
+			if tweets, err = c.GetUserTimeline(ctx, accounts[i].User.ID, opts.Tweets); err != nil { failed++ }
+		}
+		accounts[i].score(tweets, opts, now)
+	}
+	sort.SliceStable(accounts, func(i, j int) bool { return accounts[i].Suspicion > accounts[j].Suspicion })
+	st := summarize(accounts, len(following), len(followers))
+	st.FetchErrors = failed
+	return Report{At: now, Accounts: accounts, Stats: st}, nil
+}
+
+// score sets the organic score from tweets (if any), the combined suspicion and the
+// signals behind it; account age is measured at now.
+func (a *Account) score(tweets []model.Tweet, opts Options, now time.Time) {
+	u := a.User
+	var sig []string
+	if opts.Bots != nil {
+		sc := opts.Bots.Score(bots.Extract(u, tweets, now))
+		a.Bot = math.Round(sc.Probability*100) / 100
+		for _, c := range sc.Contributions {
+			if c.Logit >= 0.5 { sig = append(sig, fmt.Sprintf("%s=%.2f", c.Signal, c.Value)) }
+		}
+	} else {
+		if u.DefaultImage || u.DefaultProfile { sig = append(sig, "default profile") }
+		if !u.Verified && u.FollowersCount < 50 && u.FollowingCount > 500 {
+			sig = append(sig, fmt.Sprintf("follows %d, followed by %d", u.FollowingCount, u.FollowersCount))
+		}
+		if strings.TrimSpace(u.Description) == "" { sig = append(sig, "empty bio") }
+		if !u.CreatedAt.IsZero() && u.TweetCount > 0 {
+			if days := now.Sub(u.CreatedAt).Hours() / 24; days > 0 && float64(u.TweetCount)/days > 100 {
+				sig = append(sig, fmt.Sprintf("%.0f tweets/day", float64(u.TweetCount)/days))
+			}
+		}
+	}
+	a.Suspicion = a.Bot
+	if len(tweets) > 0 {
+		sum, links, spam := 0.0, 0, 0
+		for _, t := range tweets {
+			sum += model.OrganicContentScore(t)
+			if t.HasLink { links++ }
+			if util.ContainsAnyCaseInsensitive(t.Text, model.SpamTerms) { spam++ }
+		}
+		a.Sampled, a.Organic = len(tweets), sum/float64(len(tweets))
+		if spam > 0 { sig = append(sig, fmt.Sprintf("spam terms in %d/%d tweets", spam, len(tweets))) }
+		if links*2 > len(tweets) { sig = append(sig, fmt.Sprintf("links in %d/%d tweets", links, len(tweets))) }
+		a.Suspicion = 0.6*a.Bot + 0.4*(1-a.Organic)
+	}
+	if opts.MaxBot > 0 && a.Bot > opts.MaxBot {
+		a.Suspicious = true
+		sig = append(sig, fmt.Sprintf("bot %.2f > %.2f", a.Bot, opts.MaxBot))
+	}
+	if a.Sampled > 0 && a.Organic < opts.MinOrganic {
+		a.Suspicious = true
+		sig = append(sig, fmt.Sprintf("organic %.2f < %.2f", a.Organic, opts.MinOrganic))
+	}
+	a.Signals = sig
+}
+
+func summarize(accounts []Account, following, followers int) Stats {
+	st := Stats{Following: following, Followers: followers, ByRelation: map[string]int{}, BySignal: map[string]int{}}
+	sampled := 0
+	for _, a := range accounts {
+		if a.Relation == Mutual { st.Mutual++ }
+		st.MeanBot += a.Bot
+		if a.Sampled > 0 { st.MeanOrganic += a.Organic; sampled++ }
+		if !a.Suspicious { continue }
+		st.Suspicious++
+		st.ByRelation[a.Relation]++
+		for _, s := range a.Signals { st.BySignal[signalKind(s)]++ }
+	}
+	if len(accounts) > 0 { st.MeanBot /= float64(len(accounts)) }
+	if sampled > 0 { st.MeanOrganic /= float64(sampled) }
+	return st
+}
+
+// signalKind strips the numbers from a signal so counts group by kind.
+func signalKind(s string) string {
+	switch {
+	case strings.HasPrefix(s, "follows "):
+		return "follow ratio"
+	case strings.HasSuffix(s, "tweets/day"):
+		return "tweet rate"
+	case strings.HasPrefix(s, "spam terms"):
+		return "spam terms"
+	case strings.HasPrefix(s, "links in"):
+		return "link heavy"
+	case strings.HasPrefix(s, "bot "):
+		return "bot threshold"
+	case strings.HasPrefix(s, "organic "):
+		return "organic threshold"
+	case strings.Contains(s, "="):
+		return s[:strings.Index(s, "=")]
+	}
+	return s
+}
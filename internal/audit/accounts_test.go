@@ -0,0 +1,126 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"starseed/internal/model"
+	"starseed/internal/store/sqlitevec"
+)
+
+type fakeClient struct {
+	following, followers []model.User
+	tweets               map[string][]model.Tweet
+	fetched              []string
+	failing              map[string]bool
+}
+
+func (f *fakeClient) GetFollowing(ctx context.Context, userID string, limit int) ([]model.User, error) { return f.following, nil }
+func (f *fakeClient) GetFollowers(ctx context.Context, userID string, limit int) ([]model.User, error) { return f.followers, nil }
+func (f *fakeClient) GetUserTimeline(ctx context.Context, userID string, limit int) ([]model.Tweet, error) {
+	f.fetched = append(f.fetched, userID)
+	if f.failing[userID] { return nil, errors.New("rate limited") }
+	return f.tweets[userID], nil
+}
+
+func auditFixture() *fakeClient {
+	good := model.User{ID: "1", Username: "good", Description: "gopher", FollowersCount: 900, FollowingCount: 300}
+	bot := model.User{ID: "2", Username: "bot", DefaultImage: true, FollowersCount: 3, FollowingCount: 2000}
+	promo := model.User{ID: "3", Username: "promo", Description: "deals", FollowersCount: 400, FollowingCount: 100}
+	return &fakeClient{
+		following: []model.User{good, promo},
+		followers: []model.User{good, bot},
+		tweets: map[string][]model.Tweet{
+			"1": {{Text: "thoughts on raft"}, {Text: "go 1.24 is out"}},
+			"3": {{Text: "giveaway! click here https://x.co", HasLink: true}, {Text: "promo code https://y.co", HasLink: true}},
+		},
+	}
+}
+
+func TestAccountsRanksSuspiciousWithSignals(t *testing.T) {
+	c := auditFixture()
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	r, err := Accounts(context.Background(), c, "me", Options{Tweets: 5, MaxBot: 0.35, MinOrganic: 0.55}, now)
+	if err != nil { t.Fatal(err) }
+	if len(r.Accounts) != 3 { t.Fatalf("accounts = %d", len(r.Accounts)) }
+	if r.Accounts[0].User.Username != "bot" || !r.Accounts[0].Suspicious || r.Accounts[0].Relation != Follower {
+		t.Fatalf("want bot first, got %+v", r.Accounts[0])
+	}
+	var promo, good Account
+	for _, a := range r.Accounts {
+		switch a.User.Username {
+		case "promo":
+			promo = a
+		case "good":
+			good = a
+		}
+	}
+	if good.Relation != Mutual || good.Suspicious { t.Errorf("good = %+v", good) }
+	if !promo.Suspicious || promo.Sampled != 2 { t.Errorf("promo = %+v", promo) }
+	joined := strings.Join(promo.Signals, "; ")
+	if !strings.Contains(joined, "spam terms in 2/2 tweets") || !strings.Contains(joined, "organic ") { t.Errorf("promo signals = %q", joined) }
+	st := r.Stats
+	if st.Following != 2 || st.Followers != 2 || st.Mutual != 1 || st.Suspicious != 2 || st.BySignal["organic threshold"] != 1 {
+		t.Errorf("stats = %+v", st)
+	}
+}
+
+func TestAccountsCapsTweetSampling(t *testing.T) {
+	c := auditFixture()
+	_, err := Accounts(context.Background(), c, "me", Options{Tweets: 5, MaxSampled: 1, MaxBot: 0.35}, time.Now())
+	if err != nil { t.Fatal(err) }
+	if len(c.fetched) != 1 || c.fetched[0] != "2" { t.Fatalf("fetched = %v, want the worst profile only", c.fetched) }
+}
+
+func TestAccountsCountsFetchFailures(t *testing.T) {
+	c := auditFixture()
+	c.failing = map[string]bool{"3": true}
+	r, err := Accounts(context.Background(), c, "me", Options{Tweets: 5, MaxBot: 0.35, MinOrganic: 0.55}, time.Now())
+	if err != nil { t.Fatal(err) }
+	if r.Stats.FetchErrors != 1 { t.Fatalf("fetch errors = %d", r.Stats.FetchErrors) }
+	var buf bytes.Buffer
+	if err := Write(&buf, r, "table", 0); err != nil || !strings.Contains(buf.String(), "timeline fetches failed: 1") { t.Errorf("table:\n%s %v", buf.String(), err) }
+	buf.Reset()
+	if err := Write(&buf, r, "json", 0); err != nil || !strings.Contains(buf.String(), `"fetch_errors": 1`) { t.Errorf("json: %s %v", buf.String(), err) }
+}
+
+func TestReportFormatsAndSnapshots(t *testing.T) {
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	opts := Options{Tweets: 5, MaxBot: 0.35, MinOrganic: 0.55}
+	r, err := Accounts(context.Background(), auditFixture(), "me", opts, now)
+	if err != nil { t.Fatal(err) }
+	var buf bytes.Buffer
+	if err := Write(&buf, r, "table", 0); err != nil { t.Fatal(err) }
+	if !strings.Contains(buf.String(), "@bot") || strings.Contains(buf.String(), "@good") || !strings.Contains(buf.String(), "suspicious=2") {
+		t.Errorf("table:\n%s", buf.String())
+	}
+	buf.Reset()
+	if err := Write(&buf, r, "csv", 0); err != nil { t.Fatal(err) }
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil || len(rows) != 4 || rows[1][1] != "bot" { t.Errorf("csv rows = %v %v", rows, err) }
+	buf.Reset()
+	if err := Write(&buf, r, "json", 0); err != nil { t.Fatal(err) }
+	var back Report
+	if err := json.Unmarshal(buf.Bytes(), &back); err != nil || len(back.Accounts) != 3 { t.Errorf("json: %v", err) }
+	if err := Write(&buf, r, "xml", 0); err == nil { t.Error("want error for unknown format") }
+
+	db, err := sqlitevec.Open(":memory:")
+	if err != nil { t.Fatal(err) }
+	defer db.Close()
+	ctx := context.Background()
+	if err := db.PutAuditSnapshot(ctx, Snapshot(r, opts)); err != nil { t.Fatal(err) }
+	later := Snapshot(r, opts)
+	later.TS, later.Suspicious = now.Add(24*time.Hour), 1
+	if err := db.PutAuditSnapshot(ctx, later); err != nil { t.Fatal(err) }
+	snaps, err := db.ListAuditSnapshots(ctx, now)
+	if err != nil || len(snaps) != 2 || snaps[0].Suspicious != 2 || snaps[1].Mutual != 1 { t.Fatalf("snapshots = %+v %v", snaps, err) }
+	buf.Reset()
+	if err := WriteHistory(&buf, snaps); err != nil { t.Fatal(err) }
+	if !strings.Contains(buf.String(), "-33.3") { t.Errorf("history:\n%s", buf.String()) }
+}
@@ -0,0 +1,125 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"starseed/internal/store/sqlitevec"
+)
+
+// Entry is one engage decision: what was decided about which tweet and author, and the
+// inputs (scores, prediction, threshold, budget counts, policy result, ...) in Facts.
+type Entry struct {
+	Kind     string
+	Outcome  string
+	TweetID  string
+	AuthorID string
+	Author   string
+	DraftID  int64
+	Reason   string
+	Facts    map[string]any
+}
+
+// Recorder appends decisions to the decisions table and, when Log is set, as JSON lines
+// to Log. A nil Recorder records nothing, so callers need not check.
+type Recorder struct {
+	DB  *sqlitevec.DB
+	Log io.Writer
+
+	mu sync.Mutex
+}
+
+// Open returns a recorder over db that also appends to the JSONL file at path ("" for
+// the table only). Close releases the file.
+func Open(db *sqlitevec.DB, path string) (*Recorder, error) {
+	r := &Recorder{DB: db}
+	if path == "" { return r, nil }
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil { return r, err }
+	r.Log = f
+	return r, nil
+}
+
+// Close closes the log file if Open created one.
+func (r *Recorder) Close() error {
+	if r == nil { return nil }
+	if c, ok := r.Log.(io.Closer); ok { return c.Close() }
+	return nil
+}
+
+// line is the JSONL shape of a decision.
+type line struct {
+	ID       int64          `json:"id,omitempty"`
+	TS       string         `json:"ts"`
+	Kind     string         `json:"kind"`
+	Outcome  string         `json:"outcome"`
+	TweetID  string         `json:"tweet_id,omitempty"`
+	AuthorID string         `json:"author_id,omitempty"`
+	Author   string         `json:"author,omitempty"`
+	DraftID  int64          `json:"draft_id,omitempty"`
+	Reason   string         `json:"reason,omitempty"`
+	Detail   map[string]any `json:"detail,omitempty"`
+}
+
+// Record stores e at now and returns the stored decision. The table write is the source
+// of truth; a failed log write is reported but the decision is still returned.
+func (r *Recorder) Record(ctx context.Context, e Entry, now time.Time) (sqlitevec.Decision, error) {
+	dc := sqlitevec.Decision{TS: now, Kind: e.Kind, Outcome: e.Outcome, DraftID: e.DraftID, TweetID: e.TweetID, AuthorID: e.AuthorID, Author: e.Author, Reason: e.Reason}
+	if e.Facts != nil {
+		b, _ := json.Marshal(e.Facts)
+		dc.Detail = string(b)
+	}
+	if r == nil { return dc, nil }
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.DB != nil {
+		id, err := r.DB.PutDecision(ctx, dc)
+		if err != nil { return dc, err }
+		dc.ID = id
+	}
+	if r.Log != nil {
+		b, _ := json.Marshal(line{ID: dc.ID, TS: now.UTC().Format(time.RFC3339), Kind: e.Kind, Outcome: e.Outcome, TweetID: e.TweetID, AuthorID: e.AuthorID, Author: e.Author, DraftID: e.DraftID, Reason: e.Reason, Detail: e.Facts})
+		if _, err := fmt.Fprintln(r.Log, string(b)); err != nil { return dc, err }
+	}
+	return dc, nil
+}
+
+// Format renders a decision as one human-readable line.
+func Format(dc sqlitevec.Decision) string {
+	who := dc.Author
+	if who != "" { who = "@" + who } else { who = dc.AuthorID }
+	s := fmt.Sprintf("%s  %-16s %-9s", dc.TS.Local().Format("2006-01-02 15:04:05"), dc.Kind, dc.Outcome)
+	if dc.TweetID != "" { s += " tweet=" + dc.TweetID }
+	if who != "" { s += " author=" + who }
+	if dc.DraftID != 0 { s += fmt.Sprintf(" draft=%d", dc.DraftID) }
+	if dc.Reason != "" { s += "  " + dc.Reason }
+	return s
+}
+
+// JSON renders a decision in the same shape as the JSONL log.
+func JSON(dc sqlitevec.Decision) string {
+	var detail map[string]any
+	_ = json.Unmarshal([]byte(dc.Detail), &detail)
+	b, _ := json.Marshal(line{ID: dc.ID, TS: dc.TS.UTC().Format(time.RFC3339), Kind: dc.Kind, Outcome: dc.Outcome, TweetID: dc.TweetID, AuthorID: dc.AuthorID, Author: dc.Author, DraftID: dc.DraftID, Reason: dc.Reason, Detail: detail})
+	return string(b)
+}
+
+// ParseTime reads a filter time: a duration back from now ("24h", "90m", "7d"), an
+// RFC3339 time or a date (2006-01-02, UTC). "" is the zero time.
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	if s == "" { return time.Time{}, nil }
+	if strings.HasSuffix(s, "d") {
+		if n, err := strconv.Atoi(strings.TrimSuffix(s, "d")); err == nil { return now.AddDate(0, 0, -n), nil }
+	}
+	if d, err := time.ParseDuration(s); err == nil { return now.Add(-d), nil }
+	if t, err := time.Parse(time.RFC3339, s); err == nil { return t, nil }
+	if t, err := time.Parse("2006-01-02", s); err == nil { return t, nil }
+	return time.Time{}, fmt.Errorf("bad time %q: want a duration (24h, 7d), RFC3339 or YYYY-MM-DD", s)
+}
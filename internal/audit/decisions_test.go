@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"starseed/internal/store/sqlitevec"
+)
+
+func TestRecorderWritesTableAndLog(t *testing.T) {
+	db, err := sqlitevec.Open(":memory:")
+	if err != nil { t.Fatal(err) }
+	defer db.Close()
+	ctx := context.Background()
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	var log bytes.Buffer
+	r := &Recorder{DB: db, Log: &log}
+	entries := []Entry{
+		{Kind: "engage_candidate", Outcome: "queued", TweetID: "1", AuthorID: "10", Author: "Alice", Facts: map[string]any{"draft.score": 0.8}},
+		{Kind: "engage_candidate", Outcome: "skipped", TweetID: "2", AuthorID: "20", Author: "bob", Reason: "author cooldown: 1 reply per 6h"},
+		{Kind: "engage_gate", Outcome: "allowed", Facts: map[string]any{"prediction": 0.7, "threshold": 0.5}},
+	}
+	for i, e := range entries {
+		if _, err := r.Record(ctx, e, now.Add(time.Duration(i)*time.Hour)); err != nil { t.Fatal(err) }
+	}
+	lines := bytes.Split(bytes.TrimSpace(log.Bytes()), []byte("\n"))
+	if len(lines) != 3 { t.Fatalf("log lines = %d", len(lines)) }
+	var first map[string]any
+	if err := json.Unmarshal(lines[0], &first); err != nil { t.Fatal(err) }
+	if first["outcome"] != "queued" || first["author"] != "Alice" || first["detail"].(map[string]any)["draft.score"] != 0.8 {
+		t.Fatalf("log line = %s", lines[0])
+	}
+
+	all, err := db.ListDecisions(ctx, sqlitevec.DecisionFilter{Since: now.Add(-time.Hour)})
+	if err != nil { t.Fatal(err) }
+	if len(all) != 3 || all[0].Kind != "engage_gate" { t.Fatalf("want 3 newest first, got %+v", all) }
+	for _, c := range []struct {
+		f    sqlitevec.DecisionFilter
+		want string
+	}{
+		{sqlitevec.DecisionFilter{Author: "@alice"}, "1"},
+		{sqlitevec.DecisionFilter{Author: "20"}, "2"},
+		{sqlitevec.DecisionFilter{Outcome: "skipped"}, "2"},
+		{sqlitevec.DecisionFilter{Kind: "engage_candidate", Until: now.Add(time.Minute)}, "1"},
+		{sqlitevec.DecisionFilter{Since: now.Add(30 * time.Minute), Kind: "engage_candidate"}, "2"},
+	} {
+		got, err := db.ListDecisions(ctx, c.f)
+		if err != nil { t.Fatal(err) }
+		if len(got) != 1 || got[0].TweetID != c.want { t.Errorf("%+v: got %+v", c.f, got) }
+	}
+	var round map[string]any
+	if err := json.Unmarshal([]byte(JSON(all[0])), &round); err != nil || round["detail"].(map[string]any)["threshold"] != 0.5 { t.Fatalf("JSON = %s", JSON(all[0])) }
+}
+
+func TestNilRecorder(t *testing.T) {
+	var r *Recorder
+	dc, err := r.Record(context.Background(), Entry{Kind: "engage_gate", Outcome: "allowed"}, time.Now())
+	if err != nil || dc.Outcome != "allowed" { t.Fatalf("nil recorder: %v %+v", err, dc) }
+	if err := r.Close(); err != nil { t.Fatal(err) }
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	for in, want := range map[string]time.Time{
+		"24h":                  now.Add(-24 * time.Hour),
+		"7d":                   now.AddDate(0, 0, -7),
+		"2025-03-01":           time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		"2025-03-01T08:00:00Z": time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC),
+		"":                     {},
+	} {
+		got, err := ParseTime(in, now)
+		if err != nil || !got.Equal(want) { t.Errorf("ParseTime(%q) = %v, %v", in, got, err) }
+	}
+	if _, err := ParseTime("yesterday", now); err == nil { t.Error("want error") }
+}
@@ -0,0 +1,112 @@
+package audit
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"starseed/internal/store/sqlitevec"
+)
+
+// Formats accepted by Write.
+var Formats = []string{"table", "csv", "json"}
+
+// Write renders the report as a table (top accounts, 0 for all, then stats), CSV (every
+// account) or JSON (the whole report).
+func Write(w io.Writer, r Report, format string, top int) error {
+	switch format {
+	case "table":
+		return WriteTable(w, r, top)
+	case "csv":
+		return WriteCSV(w, r)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	}
+	return fmt.Errorf("unknown format %q (want %s)", format, strings.Join(Formats, ", "))
+}
+
+// WriteTable prints suspicious accounts (up to top) with their signals, then the stats.
+func WriteTable(w io.Writer, r Report, top int) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RANK\tACCOUNT\tRELATION\tSUSPICION\tBOT\tORGANIC\tSIGNALS")
+	n := 0
+	for _, a := range r.Accounts {
+		if !a.Suspicious { continue }
+		if top > 0 && n >= top { break }
+		n++
+		organic := "-"
+		if a.Sampled > 0 { organic = fmt.Sprintf("%.2f", a.Organic) }
+		fmt.Fprintf(tw, "%d\t@%s\t%s\t%.2f\t%.2f\t%s\t%s\n", n, a.User.Username, a.Relation, a.Suspicion, a.Bot, organic, strings.Join(a.Signals, "; "))
+	}
+	if err := tw.Flush(); err != nil { return err }
+	st := r.Stats
+	fmt.Fprintf(w, "\nfollowing=%d followers=%d mutual=%d suspicious=%d (%.1f%%) mean_bot=%.2f mean_organic=%.2f\n",
+		st.Following, st.Followers, st.Mutual, st.Suspicious, share(st.Suspicious, len(r.Accounts)), st.MeanBot, st.MeanOrganic)
+	if len(st.BySignal) > 0 { fmt.Fprintf(w, "signals: %s\n", counts(st.BySignal)) }
+	if len(st.ByRelation) > 0 { fmt.Fprintf(w, "suspicious by relation: %s\n", counts(st.ByRelation)) }
+	if st.FetchErrors > 0 { fmt.Fprintf(w, "timeline fetches failed: %d (scored on profile only)\n", st.FetchErrors) }
+	return nil
+}
+
+// WriteCSV writes one row per account, most suspicious first.
+func WriteCSV(w io.Writer, r Report) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"id", "username", "relation", "suspicion", "suspicious", "bot", "organic", "sampled", "followers", "following", "signals"})
+	for _, a := range r.Accounts {
+		_ = cw.Write([]string{a.User.ID, a.User.Username, a.Relation, f2(a.Suspicion), strconv.FormatBool(a.Suspicious), f2(a.Bot), f2(a.Organic),
+			strconv.Itoa(a.Sampled), strconv.Itoa(a.User.FollowersCount), strconv.Itoa(a.User.FollowingCount), strings.Join(a.Signals, "; ")})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Snapshot turns a report's stats into a row for the audit_snapshots table.
+func Snapshot(r Report, opts Options) sqlitevec.AuditSnapshot {
+	st := r.Stats
+	detail, _ := json.Marshal(map[string]any{"max_bot": opts.MaxBot, "min_organic": opts.MinOrganic, "tweets": opts.Tweets, "by_relation": st.ByRelation, "by_signal": st.BySignal, "fetch_errors": st.FetchErrors})
+	return sqlitevec.AuditSnapshot{TS: r.At, Following: st.Following, Followers: st.Followers, Mutual: st.Mutual, Suspicious: st.Suspicious,
+		MeanBot: st.MeanBot, MeanOrganic: st.MeanOrganic, Detail: string(detail)}
+}
+
+// WriteHistory prints stored snapshots, oldest first, with the change in suspicious share.
+func WriteHistory(w io.Writer, snaps []sqlitevec.AuditSnapshot) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "WHEN\tFOLLOWING\tFOLLOWERS\tSUSPICIOUS\tSHARE\tCHANGE\tMEAN_BOT\tMEAN_ORGANIC")
+	prev := -1.0
+	for _, s := range snaps {
+		sh := share(s.Suspicious, s.Following+s.Followers-s.Mutual)
+		change := "-"
+		if prev >= 0 { change = fmt.Sprintf("%+.1f", sh-prev) }
+		prev = sh
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%.1f%%\t%s\t%.2f\t%.2f\n", s.TS.Local().Format(time.DateTime), s.Following, s.Followers, s.Suspicious, sh, change, s.MeanBot, s.MeanOrganic)
+	}
+	return tw.Flush()
+}
+
+func share(n, total int) float64 {
+	if total <= 0 { return 0 }
+	return 100 * float64(n) / float64(total)
+}
+
+func f2(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+
+// counts renders a count map as "k=v" pairs, largest first.
+func counts(m map[string]int) string {
+	keys := make([]string, 0, len(m))
+	for k := range m { keys = append(keys, k) }
+	sort.Slice(keys, func(i, j int) bool {
+		if m[keys[i]] != m[keys[j]] { return m[keys[i]] > m[keys[j]] }
+		return keys[i] < keys[j]
+	})
+	parts := make([]string, len(keys))
+	for i, k := range keys { parts[i] = fmt.Sprintf("%s=%d", k, m[k]) }
+	return strings.Join(parts, " ")
+}
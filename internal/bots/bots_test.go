@@ -0,0 +1,110 @@
+package bots
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"starseed/internal/model"
+)
+
+var now = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func human() (model.User, []model.Tweet) {
+	u := model.User{ID: "h", Username: "gopher", Description: "Go, distributed systems", CreatedAt: now.AddDate(-4, 0, 0), FollowersCount: 900, FollowingCount: 300, TweetCount: 5000}
+	var ts []model.Tweet
+	for i := 0; i < 30; i++ {
+		t := model.Tweet{ID: fmt.Sprint(i), Text: fmt.Sprintf("notes on %s and %s", []string{"raft", "go", "tracing", "paxos", "queues", "caches"}[i%6], []string{"latency", "tests", "deploys", "retries", "budgets"}[i/6]), CreatedAt: now.Add(-time.Duration(i*26) * time.Hour).Truncate(24 * time.Hour).Add(time.Duration(9+i%7) * time.Hour)}
+		if i%4 == 0 { t.ReplyToID = "x" }
+		if i%5 == 0 { t.HasLink = true }
+		ts = append(ts, t)
+	}
+	return u, ts
+}
+
+func bot() (model.User, []model.Tweet) {
+	u := model.User{ID: "b", Username: "deals4u", DefaultImage: true, CreatedAt: now.AddDate(0, 0, -20), FollowersCount: 4, FollowingCount: 2500, TweetCount: 6000}
+	var ts []model.Tweet
+	for i := 0; i < 30; i++ {
+		ts = append(ts, model.Tweet{ID: fmt.Sprint(i), Text: fmt.Sprintf("@user%d huge promo %d https://spam.example/%d", i, i, i), ReplyToID: "x", CreatedAt: now.Add(-time.Duration(i*47) * time.Minute)})
+	}
+	return u, ts
+}
+
+func TestExtractSignals(t *testing.T) {
+	u, ts := bot()
+	f := Extract(u, ts, now)
+	if f[DuplicateContent] != 1 || f[LinkRatio] != 1 || f[ReplyOnly] != 1 || f[DefaultImage] != 1 { t.Errorf("bot content signals = %v", f) }
+	if f[AccountAge] < 0.7 || f[TweetRate] < 0.8 || f[FollowRatio] < 0.9 || f[TimeEntropy] < 0.5 { t.Errorf("bot activity signals = %v", f) }
+	h, hts := human()
+	f = Extract(h, hts, now)
+	if f[DuplicateContent] != 0 || f[TimeEntropy] != 0 || f[AccountAge] > 0.01 || f[ReplyOnly] > 0.3 { t.Errorf("human signals = %v", f) }
+	if _, ok := Extract(h, hts[:3], now)[LinkRatio]; ok { t.Error("content signals need MinTweets") }
+}
+
+func TestDefaultModelSeparates(t *testing.T) {
+	m := Default()
+	h, hts := human()
+	b, bts := bot()
+	ph, sb := m.Probability(Extract(h, hts, now)), m.Score(Extract(b, bts, now))
+	if ph > 0.2 || sb.Probability < 0.9 { t.Fatalf("human=%.2f bot=%.2f", ph, sb.Probability) }
+	if sb.Contributions[0].Signal != DuplicateContent { t.Errorf("top contribution = %+v", sb.Contributions[0]) }
+	// profile-only score is still informative
+	if p := m.Probability(Extract(b, nil, now)); p < 0.5 { t.Errorf("bot profile only = %.2f", p) }
+	// missing tweet signals take typical values rather than reading as perfectly human
+	f := Extract(h, nil, now)
+	zeros := Features{TimeEntropy: 0, DuplicateContent: 0, LinkRatio: 0, ReplyOnly: 0}
+	for k, v := range f { zeros[k] = v }
+	if p, pz := m.Probability(f), m.Probability(zeros); p <= pz { t.Errorf("profile only %.3f should exceed all-zero tweet signals %.3f", p, pz) }
+}
+
+func TestTrainAndSave(t *testing.T) {
+	var samples []Sample
+	for i := 0; i < 20; i++ {
+		h, hts := human()
+		h.FollowersCount += i * 50
+		b, bts := bot()
+		b.FollowingCount += i * 10
+		samples = append(samples, Sample{Features: Extract(h, hts, now)}, Sample{Features: Extract(b, bts, now), Bot: true})
+	}
+	m, err := Train(samples, TrainOptions{Epochs: 500})
+	if err != nil { t.Fatal(err) }
+	if m.Metrics.Accuracy != 1 || m.Metrics.LogLoss > 0.1 { t.Fatalf("metrics = %+v", m.Metrics) }
+	path := filepath.Join(t.TempDir(), "bots.json")
+	if err := m.Save(path); err != nil { t.Fatal(err) }
+	back, err := Load(path)
+	if err != nil { t.Fatal(err) }
+	if math.Abs(back.Probability(samples[1].Features)-m.Probability(samples[1].Features)) > 1e-9 { t.Error("round trip changed scores") }
+	if _, err := Train(samples[:1], TrainOptions{}); err == nil { t.Error("want error with one class") }
+	if d, err := LoadOrDefault(filepath.Join(t.TempDir(), "missing.json")); err != nil || d.Bias != Default().Bias { t.Errorf("LoadOrDefault: %v", err) }
+}
+
+func TestCrossValidateHoldsOutFolds(t *testing.T) {
+	var samples []Sample
+	for i := 0; i < 10; i++ {
+		h, hts := human()
+		h.FollowersCount += i * 50
+		b, bts := bot()
+		b.FollowingCount += i * 10
+		samples = append(samples, Sample{Features: Extract(h, hts, now)}, Sample{Features: Extract(b, bts, now), Bot: true})
+	}
+	v, err := CrossValidate(samples, 5, TrainOptions{Epochs: 300})
+	if err != nil { t.Fatal(err) }
+	if v.Folds != 5 || v.Metrics.N != len(samples) || v.Metrics.Accuracy < 0.9 { t.Fatalf("validation = %+v", v) }
+	n := 0
+	for _, b := range v.Calibration { n += b.N }
+	if len(v.Calibration) != 5 || n != len(samples) { t.Fatalf("calibration = %+v", v.Calibration) }
+	// Too few of a class to hold any out
+	if _, err := CrossValidate(samples[:3], 5, TrainOptions{}); err == nil { t.Error("want error with one bot") }
+}
+
+func TestParseLabels(t *testing.T) {
+	in := "handle,label\n@spam1,bot\n# known people\nalice human\nbob\t0\n"
+	ls, err := ParseLabels(strings.NewReader(in))
+	if err != nil { t.Fatal(err) }
+	if len(ls) != 3 || !ls[0].Bot || ls[0].Handle != "spam1" || ls[1].Bot || ls[2].Handle != "bob" { t.Fatalf("labels = %+v", ls) }
+	if _, err := ParseLabels(strings.NewReader("a,bot\nb,maybe\n")); err == nil { t.Error("want error for unknown label") }
+}
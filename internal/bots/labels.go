@@ -0,0 +1,40 @@
+package bots
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Label marks a known account as bot or human.
+type Label struct {
+	Handle string
+	Bot    bool
+}
+
+// ParseLabels reads one "@handle label" per line (comma, tab or space separated), where
+// label is bot/1/true or human/0/false. Blank lines and # comments are skipped.
+func ParseLabels(r io.Reader) ([]Label, error) {
+	var out []Label
+	sc := bufio.NewScanner(r)
+	line := 0
+	for sc.Scan() {
+		line++
+		s := strings.TrimSpace(sc.Text())
+		if s == "" || strings.HasPrefix(s, "#") { continue }
+		parts := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\t' || r == ' ' })
+		if len(parts) != 2 { return nil, fmt.Errorf("line %d: want handle and label, got %q", line, s) }
+		l := Label{Handle: strings.TrimPrefix(parts[0], "@")}
+		switch strings.ToLower(parts[1]) {
+		case "bot", "1", "true":
+			l.Bot = true
+		case "human", "0", "false":
+		default:
+			if line == 1 { continue } // header row
+			return nil, fmt.Errorf("line %d: unknown label %q", line, parts[1])
+		}
+		out = append(out, l)
+	}
+	return out, sc.Err()
+}
@@ -0,0 +1,262 @@
+package bots
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+
+	"starseed/internal/model"
+)
+
+// Model is a logistic regression over Features. Fitted by log loss its output is a
+// calibrated probability; Default is hand-set until a model is trained on labels.
+type Model struct {
+	Bias    float64            `json:"bias"`
+	Weights map[string]float64 `json:"weights"`
+	// Typical values of the tweet signals (mean over the training accounts that had
+	// them), used when an account is scored without enough tweets
+	Typical map[string]float64 `json:"typical,omitempty"`
+	Trained time.Time          `json:"trained,omitempty"`
+	Samples int                `json:"samples,omitempty"`
+	Metrics *Metrics           `json:"metrics,omitempty"`
+	// Out-of-fold metrics from CrossValidate (nil when not run)
+	Validation *Validation `json:"validation,omitempty"`
+}
+
+// Default returns the untrained model.
+func Default() *Model {
+	return &Model{Bias: -4, Weights: map[string]float64{
+		AccountAge: 1.5, TweetRate: 1.5, FollowRatio: 2, LowFollowers: 1, DefaultImage: 1.5, EmptyBio: 0.8,
+		Verified: -2, TimeEntropy: 1.5, DuplicateContent: 3, LinkRatio: 1.2, ReplyOnly: 1.2,
+	}, Typical: defaultTypical()}
+}
+
+// defaultTypical is a hand-set guess at an ordinary account's tweet signals.
+func defaultTypical() map[string]float64 {
+	return map[string]float64{TimeEntropy: 0.1, DuplicateContent: 0.05, LinkRatio: 0.3, ReplyOnly: 0.3}
+}
+
+// Contribution is one signal's share of a score, in log-odds.
+type Contribution struct {
+	Signal string  `json:"signal"`
+	Value  float64 `json:"value"`
+	Weight float64 `json:"weight"`
+	Logit  float64 `json:"logit"`
+}
+
+// Score is a bot probability with the signals behind it, largest effect first.
+type Score struct {
+	Probability   float64        `json:"probability"`
+	Contributions []Contribution `json:"contributions"`
+}
+
+// Score evaluates f. Tweet signals absent from f (too few tweets, or a profile scored
+// alone) take their typical values, so missing evidence does not read as human.
+func (m *Model) Score(f Features) Score {
+	f = m.fill(f)
+	z := m.Bias
+	var cs []Contribution
+	for name, v := range f {
+		w := m.Weights[name]
+		z += w * v
+		if w*v != 0 { cs = append(cs, Contribution{Signal: name, Value: v, Weight: w, Logit: w * v}) }
+	}
+	sort.Slice(cs, func(i, j int) bool {
+		if math.Abs(cs[i].Logit) != math.Abs(cs[j].Logit) { return math.Abs(cs[i].Logit) > math.Abs(cs[j].Logit) }
+		return cs[i].Signal < cs[j].Signal
+	})
+	return Score{Probability: sigmoid(z), Contributions: cs}
+}
+
+// Probability is Score(f).Probability.
+func (m *Model) Probability(f Features) float64 { return m.Score(f).Probability }
+
+// fill returns f with absent tweet signals set to their typical values.
+func (m *Model) fill(f Features) Features {
+	typical := m.Typical
+	if typical == nil { typical = defaultTypical() }
+	out := make(Features, len(f)+len(tweetSignals))
+	for k, v := range f { out[k] = v }
+	for _, k := range tweetSignals {
+		if _, ok := out[k]; !ok { out[k] = typical[k] }
+	}
+	return out
+}
+
+// BotProbability scores an account from its profile alone (a model.BotScorer).
+func (m *Model) BotProbability(u model.User) float64 { return m.Probability(Extract(u, nil, time.Now())) }
+
+func sigmoid(z float64) float64 { return 1 / (1 + math.Exp(-z)) }
+
+// Sample is a labeled account.
+type Sample struct {
+	Features Features
+	Bot      bool
+}
+
+// Metrics describe how well probabilities match labels.
+type Metrics struct {
+	N        int     `json:"n"`
+	LogLoss  float64 `json:"log_loss"`
+	Brier    float64 `json:"brier"`
+	Accuracy float64 `json:"accuracy"`
+}
+
+// Evaluate scores m on samples.
+func (m *Model) Evaluate(samples []Sample) Metrics {
+	probs := make([]float64, len(samples))
+	for i, s := range samples { probs[i] = m.Probability(s.Features) }
+	return metrics(samples, probs)
+}
+
+// metrics compares probabilities (one per sample) with the labels.
+func metrics(samples []Sample, probs []float64) Metrics {
+	mt := Metrics{N: len(samples)}
+	if len(samples) == 0 { return mt }
+	for i, s := range samples {
+		p := math.Min(math.Max(probs[i], 1e-9), 1-1e-9)
+		y := b2f(s.Bot)
+		mt.LogLoss -= y*math.Log(p) + (1-y)*math.Log(1-p)
+		mt.Brier += (p - y) * (p - y)
+		if (p >= 0.5) == s.Bot { mt.Accuracy++ }
+	}
+	n := float64(len(samples))
+	mt.LogLoss, mt.Brier, mt.Accuracy = mt.LogLoss/n, mt.Brier/n, mt.Accuracy/n
+	return mt
+}
+
+// TrainOptions control gradient descent; zero values take defaults.
+type TrainOptions struct {
+	Epochs int     // default 2000
+	LR     float64 // default 0.5
+	L2     float64 // default 0.01
+}
+
+// Train fits a model to labeled samples by batch gradient descent on L2-regularized log
+// loss, starting from Default. Both classes are needed.
+func Train(samples []Sample, opts TrainOptions) (*Model, error) {
+	bots := 0
+	for _, s := range samples {
+		if s.Bot { bots++ }
+	}
+	if bots == 0 || bots == len(samples) { return nil, errors.New("training needs both bot and human samples") }
+	if opts.Epochs <= 0 { opts.Epochs = 2000 }
+	if opts.LR <= 0 { opts.LR = 0.5 }
+	if opts.L2 <= 0 { opts.L2 = 0.01 }
+	m := Default()
+	for _, k := range tweetSignals {
+		sum, n := 0.0, 0
+		for _, s := range samples {
+			if v, ok := s.Features[k]; ok { sum += v; n++ }
+		}
+		if n > 0 { m.Typical[k] = sum / float64(n) }
+	}
+	// Train on what scoring sees: accounts with few tweets get the typical tweet signals
+	filled := make([]Sample, len(samples))
+	for i, s := range samples { filled[i] = Sample{Features: m.fill(s.Features), Bot: s.Bot} }
+	samples = filled
+	n := float64(len(samples))
+	for e := 0; e < opts.Epochs; e++ {
+		gb := 0.0
+		gw := map[string]float64{}
+		for _, s := range samples {
+			d := m.Probability(s.Features) - b2f(s.Bot)
+			gb += d
+			for k, v := range s.Features { gw[k] += d * v }
+		}
+		m.Bias -= opts.LR * gb / n
+		for _, k := range Signals {
+			m.Weights[k] -= opts.LR * (gw[k]/n + opts.L2*m.Weights[k])
+		}
+	}
+	m.Samples = len(samples)
+	mt := m.Evaluate(samples)
+	m.Metrics = &mt
+	return m, nil
+}
+
+// Bin is one reliability bucket: held-out accounts whose probability fell in [Lo, Hi),
+// with their mean probability and the share that were bots.
+type Bin struct {
+	Lo        float64 `json:"lo"`
+	Hi        float64 `json:"hi"`
+	N         int     `json:"n"`
+	Predicted float64 `json:"predicted"`
+	Observed  float64 `json:"observed"`
+}
+
+// Validation holds metrics pooled over k held-out folds and the calibration table.
+type Validation struct {
+	Folds       int     `json:"folds"`
+	Metrics     Metrics `json:"metrics"`
+	Calibration []Bin   `json:"calibration"`
+}
+
+// CrossValidate trains on k-1 folds and scores the held-out one, k times. Folds are
+// stratified so each holds bots and humans in proportion; k is capped by the smaller class.
+func CrossValidate(samples []Sample, k int, opts TrainOptions) (*Validation, error) {
+	var botIdx, humanIdx []int
+	for i, s := range samples {
+		if s.Bot { botIdx = append(botIdx, i) } else { humanIdx = append(humanIdx, i) }
+	}
+	if k > len(botIdx) { k = len(botIdx) }
+	if k > len(humanIdx) { k = len(humanIdx) }
+	if k < 2 { return nil, errors.New("cross-validation needs at least 2 bot and 2 human samples") }
+	fold := make([]int, len(samples))
+	for j, i := range botIdx { fold[i] = j % k }
+	for j, i := range humanIdx { fold[i] = j % k }
+	probs := make([]float64, len(samples))
+	for f := 0; f < k; f++ {
+		var train []Sample
+		for i, s := range samples {
+			if fold[i] != f { train = append(train, s) }
+		}
+		m, err := Train(train, opts)
+		if err != nil { return nil, err }
+		for i, s := range samples {
+			if fold[i] == f { probs[i] = m.Probability(s.Features) }
+		}
+	}
+	v := &Validation{Folds: k, Metrics: metrics(samples, probs)}
+	const bins = 5
+	for b := 0; b < bins; b++ {
+		bin := Bin{Lo: float64(b) / bins, Hi: float64(b+1) / bins}
+		for i, p := range probs {
+			if int(math.Min(p*bins, bins-1)) != b { continue }
+			bin.N++
+			bin.Predicted += p
+			bin.Observed += b2f(samples[i].Bot)
+		}
+		if bin.N > 0 { bin.Predicted, bin.Observed = bin.Predicted/float64(bin.N), bin.Observed/float64(bin.N) }
+		v.Calibration = append(v.Calibration, bin)
+	}
+	return v, nil
+}
+
+// Load reads a model saved by Save.
+func Load(path string) (*Model, error) {
+	b, err := os.ReadFile(path)
+	if err != nil { return nil, err }
+	var m Model
+	if err := json.Unmarshal(b, &m); err != nil { return nil, fmt.Errorf("bot model %s: %w", path, err) }
+	if m.Weights == nil { return nil, fmt.Errorf("bot model %s: no weights", path) }
+	return &m, nil
+}
+
+// LoadOrDefault loads path, falling back to Default when the file does not exist.
+func LoadOrDefault(path string) (*Model, error) {
+	m, err := Load(path)
+	if errors.Is(err, os.ErrNotExist) { return Default(), nil }
+	return m, err
+}
+
+// Save writes the model as JSON.
+func (m *Model) Save(path string) error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil { return err }
+	return os.WriteFile(path, b, 0o644)
+}
@@ -0,0 +1,100 @@
+package bots
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"starseed/internal/model"
+)
+
+// Signal names, each a value in [0,1] where higher looks more automated (verified is
+// the exception: the model learns a negative weight for it).
+const (
+	AccountAge       = "account_age"
+	TweetRate        = "tweet_rate"
+	FollowRatio      = "follow_ratio"
+	LowFollowers     = "low_followers"
+	DefaultImage     = "default_image"
+	EmptyBio         = "empty_bio"
+	Verified         = "verified"
+	TimeEntropy      = "time_entropy"
+	DuplicateContent = "duplicate_content"
+	LinkRatio        = "link_ratio"
+	ReplyOnly        = "reply_only"
+)
+
+// Signals lists every signal in report order.
+var Signals = []string{AccountAge, TweetRate, FollowRatio, LowFollowers, DefaultImage, EmptyBio, Verified, TimeEntropy, DuplicateContent, LinkRatio, ReplyOnly}
+
+// tweetSignals come from recent tweets and are absent when there are too few.
+var tweetSignals = []string{TimeEntropy, DuplicateContent, LinkRatio, ReplyOnly}
+
+// MinTweets is how many tweets the content and timing signals need; with fewer they are
+// left out and scoring uses the model's typical values for them.
+const MinTweets = 5
+
+// entropyTweets is how many tweets the posting-time signal needs.
+const entropyTweets = 20
+
+// Features are the signal values for one account; absent signals contribute nothing.
+type Features map[string]float64
+
+// Extract computes signals from the profile and recent tweets (any order) at now.
+func Extract(u model.User, tweets []model.Tweet, now time.Time) Features {
+	f := Features{
+		FollowRatio:  clamp01(math.Log10(1+float64(u.FollowingCount)/float64(u.FollowersCount+1)) / 2),
+		LowFollowers: clamp01(1 - float64(u.FollowersCount)/50),
+		DefaultImage: b2f(u.DefaultImage || u.DefaultProfile),
+		EmptyBio:     b2f(strings.TrimSpace(u.Description) == ""),
+		Verified:     b2f(u.Verified),
+	}
+	if !u.CreatedAt.IsZero() && u.CreatedAt.Before(now) {
+		days := now.Sub(u.CreatedAt).Hours() / 24
+		// New accounts score near 1, a year-old account about 0.02
+		f[AccountAge] = math.Exp(-days / 90)
+		// 200+ tweets a day saturates
+		f[TweetRate] = clamp01(math.Log10(1+float64(u.TweetCount)/math.Max(days, 1)) / math.Log10(201))
+	}
+	if len(tweets) < MinTweets { return f }
+	var hours [24]float64
+	seen := map[string]int{}
+	links, replies := 0, 0
+	for _, t := range tweets {
+		hours[t.CreatedAt.UTC().Hour()]++
+		seen[normalize(t.Text)]++
+		if t.HasLink || strings.Contains(t.Text, "https://") { links++ }
+		if t.ReplyToID != "" { replies++ }
+	}
+	n := float64(len(tweets))
+	h := 0.0
+	for _, c := range hours {
+		if c > 0 { h -= c / n * math.Log(c/n) }
+	}
+	// Posting around the clock: people sleep, so only entropy above 0.7 of the maximum
+	// counts, and only with enough tweets to tell
+	if n >= entropyTweets { f[TimeEntropy] = clamp01((h/math.Log(24) - 0.7) / 0.3) }
+	dups := 0
+	for _, c := range seen {
+		if c > 1 { dups += c }
+	}
+	f[DuplicateContent] = float64(dups) / n
+	f[LinkRatio] = float64(links) / n
+	f[ReplyOnly] = float64(replies) / n
+	return f
+}
+
+var noise = regexp.MustCompile(`https?://\S+|@\w+|#|\d+`)
+
+// normalize strips links, mentions and numbers so templated tweets compare equal.
+func normalize(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(noise.ReplaceAllString(s, ""))), " ")
+}
+
+func clamp01(v float64) float64 { return math.Max(0, math.Min(1, v)) }
+
+func b2f(b bool) float64 {
+	if b { return 1 }
+	return 0
+}
@@ -2,8 +2,11 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -17,6 +20,10 @@ type Config struct {
 	Filters     FiltersConfig     `yaml:"filters"`
 	Engagement  EngagementConfig  `yaml:"engagement"`
 	LLM         LLMConfig         `yaml:"llm"`
+	Persona     PersonaConfig     `yaml:"persona"`
+	Suggest     SuggestConfig     `yaml:"suggest"`
+	Recommend   RecommendConfig   `yaml:"recommend"`
+	Embeddings  EmbeddingsConfig  `yaml:"embeddings"`
     Storage     StorageConfig     `yaml:"storage"`
 }
 
@@ -48,36 +55,202 @@ type FiltersConfig struct {
 	MinOrganicScore float64 `yaml:"minOrganicScore"`
 	// Maximum acceptable bot likelihood [0,1]
 	MaxBotLikelihood float64 `yaml:"maxBotLikelihood"`
+	// Bot model from 'bots train' scoring bot likelihood; empty uses the profile heuristic
+	BotModel string `yaml:"botModel"`
 	// Language filters, e.g., ["en"]
 	Languages []string `yaml:"languages"`
 }
 
 type EngagementConfig struct {
-	// Max interactions per hour and per day
+	// Max interactions (all action types) per rolling hour and rolling 24h
 	MaxPerHour int `yaml:"maxPerHour"`
 	MaxPerDay  int `yaml:"maxPerDay"`
-	// Quiet hours (UTC) to avoid low-quality time windows
+	// Minimum seconds between any two actions, plus up to jitterSeconds extra
+	MinSpacingSeconds int `yaml:"minSpacingSeconds"`
+	JitterSeconds     int `yaml:"jitterSeconds"`
+	// Quiet hours (schedule timezone, UTC by default); ignored when schedule.quiet is set
 	QuietHours []int `yaml:"quietHours"`
     // Per action-type budgets (e.g., reply/like/follow)
     PerType map[string]ActionBudget `yaml:"perType"`
+	// Timezone-aware quiet ranges and blackout dates
+	Schedule ScheduleConfig `yaml:"schedule"`
+	// Queued drafts expire once their target tweet is older than this (default 24)
+	DraftTTLHours int `yaml:"draftTTLHours"`
+	// Per-author and per-conversation frequency caps
+	Cooldowns []CooldownRule `yaml:"cooldowns"`
+	// Optional YAML policy file; the built-in policy derives from filters when empty
+	Policy string `yaml:"policy"`
+	// Opt-in automated posting (engage -mode auto)
+	Auto AutoConfig `yaml:"auto"`
+}
+
+// CooldownRule allows at most Max actions (of Type, or any type when empty) towards the
+// same author or conversation within a rolling window of WindowHours.
+type CooldownRule struct {
+	// "author" or "conversation"
+	Scope       string `yaml:"scope"`
+	Type        string `yaml:"type"`
+	Max         int    `yaml:"max"`
+	WindowHours int    `yaml:"windowHours"`
+}
+
+type AutoConfig struct {
+	// Live posting requires enabled: true; dry runs work regardless
+	Enabled bool `yaml:"enabled"`
+	// Pending drafts scoring at least this are posted without review (default 0.6)
+	MinScore float64 `yaml:"minScore"`
+	// Posting stops while this file exists (default ./starseed.stop)
+	KillSwitchFile string `yaml:"killSwitchFile"`
+}
+
+type ScheduleConfig struct {
+	// IANA timezone name, e.g. "Europe/Berlin" (default UTC)
+	Timezone string `yaml:"timezone"`
+	// Quiet ranges in local wall-clock time
+	Quiet []QuietRange `yaml:"quiet"`
+	// Local dates (YYYY-MM-DD) with no engagement at all
+	Blackout []string `yaml:"blackout"`
+}
+
+type QuietRange struct {
+	// Weekdays the range starts on ("mon".."sun"); empty means every day
+	Days []string `yaml:"days"`
+	// HH:MM; an end before the start wraps past midnight, equal start/end covers the whole day
+	Start string `yaml:"start"`
+	End   string `yaml:"end"`
 }
 
 type ActionBudget struct {
     MaxPerHour int `yaml:"maxPerHour"`
     MaxPerDay  int `yaml:"maxPerDay"`
+    // Minimum seconds between two actions of this type
+    MinSpacingSeconds int `yaml:"minSpacingSeconds"`
 }
 
 type LLMConfig struct {
-	Provider string `yaml:"provider"` // "openai" or "none"
+	// "openai", "openai-compatible", "ollama", "anthropic" or "none"
+	Provider string `yaml:"provider"`
 	Model    string `yaml:"model"`
-	// If empty, read from env OPENAI_API_KEY
+	// If empty, read from env OPENAI_API_KEY (openai, openai-compatible) or ANTHROPIC_API_KEY
 	APIKey string `yaml:"apiKey"`
+	// Endpoint override; required for openai-compatible (e.g. http://localhost:8000/v1)
+	BaseURL string `yaml:"baseURL"`
+	// OpenAI API flavor: "responses" (default) or "chat"
+	API string `yaml:"api"`
+	// Per-request timeout and retries on 429/5xx/network errors
+	TimeoutSeconds int `yaml:"timeoutSeconds"`
+	MaxRetries     int `yaml:"maxRetries"`
+	// Generation limits (0 / unset = provider default; an explicit temperature: 0 is sent)
+	MaxTokens   int      `yaml:"maxTokens"`
+	Temperature *float64 `yaml:"temperature"`
+}
+
+// PersonaConfig shapes reply drafts for both the heuristic and LLM paths.
+type PersonaConfig struct {
+	// Tone of voice, e.g. "concise, wise, kind"
+	Voice string `yaml:"voice"`
+	// Topics we speak to with authority
+	Expertise []string `yaml:"expertise"`
+	// Phrases a draft must never contain (case-insensitive)
+	BannedPhrases []string `yaml:"bannedPhrases"`
+	// "none", "sparing" or "any"
+	Emoji string `yaml:"emoji"`
+	// Maximum reply length in characters (default 220)
+	MaxLength int `yaml:"maxLength"`
+	// Optional text/template files; empty uses the built-in prompts
+	Templates PromptTemplates `yaml:"templates"`
+}
+
+type PromptTemplates struct {
+	System    string `yaml:"system"`
+	Reply     string `yaml:"reply"`
+	Heuristic string `yaml:"heuristic"`
+}
+
+// SuggestConfig controls multi-candidate drafting.
+type SuggestConfig struct {
+	// Candidates kept per suggestion (LLM samples plus heuristic variants; default 3)
+	Candidates int `yaml:"candidates"`
+	// Relative weights of the candidate scores
+	Weights ScoreWeights `yaml:"weights"`
+	// How far back our own replies are checked for novelty (default 30)
+	NoveltyDays int `yaml:"noveltyDays"`
+	// Checks every draft must pass before it is shown or queued
+	Rules DraftRules `yaml:"rules"`
+}
+
+// DraftRules configures the draft validator; zero values use safe defaults.
+type DraftRules struct {
+	// Rule names to skip: length, chars, blocklist, pii, links, repetition
+	Disabled []string `yaml:"disabled"`
+	// Length bounds in characters (defaults 10 and persona.maxLength)
+	MinLength int `yaml:"minLength"`
+	MaxLength int `yaml:"maxLength"`
+	// Maximum hashtags per draft (default 2)
+	MaxHashtags int `yaml:"maxHashtags"`
+	// Terms that reject a draft (case-insensitive, whole words)
+	Blocklist []string `yaml:"blocklist"`
+	// "none" (default), "allowlist" or "any"
+	Links          string   `yaml:"links"`
+	AllowedDomains []string `yaml:"allowedDomains"`
+	// Reject drafts whose word n-gram similarity to one of our last N replies reaches the threshold
+	RepetitionWindow int     `yaml:"repetitionWindow"` // default 50
+	NGram            int     `yaml:"ngram"`            // default 3
+	MaxSimilarity    float64 `yaml:"maxSimilarity"`    // default 0.6
+}
+
+type ScoreWeights struct {
+	Length    float64 `yaml:"length"`
+	Relevance float64 `yaml:"relevance"`
+	Novelty   float64 `yaml:"novelty"`
+	Safety    float64 `yaml:"safety"`
+}
+
+// RecommendConfig selects how starseed recommend finds new accounts.
+type RecommendConfig struct {
+	// "heuristic" (interest search plus one-hop graph, default), "pagerank" or "salsa"
+	// (random walks over the stored follow graph seeded from our followings)
+	Mode string `yaml:"mode"`
+	// Probability a walk follows an edge instead of restarting at the seeds (default 0.85)
+	Damping float64 `yaml:"damping"`
+	// Iteration limit for the graph walks (default 50)
+	MaxIterations int `yaml:"maxIterations"`
+	// Extra edge weight per recent interaction with an author (default 0.5)
+	InteractionWeight float64 `yaml:"interactionWeight"`
+	// Score added to new accounts followed mostly from a community we under-engage (see
+	// starseed graph communities), times how under-served it is (default 0.1; 0 disables)
+	CommunityWeight float64 `yaml:"communityWeight"`
+}
+
+// EmbeddingsConfig selects the text embedder behind interest relevance.
+type EmbeddingsConfig struct {
+	// "local" (hashed n-grams, TF-IDF + SVD once trained with starseed embed train),
+	// "openai", "openai-compatible" or "none" (exact keyword matching only)
+	Provider string `yaml:"provider"`
+	// Endpoint model (default text-embedding-3-small)
+	Model string `yaml:"model"`
+	// Endpoint override; required for openai-compatible (e.g. http://localhost:8000/v1)
+	BaseURL string `yaml:"baseURL"`
+	// If empty, read from env OPENAI_API_KEY
+	APIKey         string `yaml:"apiKey"`
+	// Per-request timeout and retries on 429/5xx/network errors
+	TimeoutSeconds int `yaml:"timeoutSeconds"`
+	MaxRetries     int `yaml:"maxRetries"`
+	// Trained local model (default ./starseed_embed_model.json; untrained when missing)
+	ModelPath string `yaml:"modelPath"`
+	// Cosine similarity to the nearest topic centroid that counts as zero relevance (default 0.1)
+	MinSimilarity float64 `yaml:"minSimilarity"`
 }
 
 type StorageConfig struct {
     DBPath string `yaml:"dbPath"`
+    // DecisionLog is the append-only JSONL copy of the decisions table ("" disables it)
+    DecisionLog string `yaml:"decisionLog"`
 }
 
+// Float returns a pointer to v, for optional settings such as LLMConfig.Temperature.
+func Float(v float64) *float64 { return &v }
+
 // Default returns a sensible default configuration.
 func Default() Config {
 	return Config{
@@ -89,9 +262,13 @@ func Default() Config {
 			Weights:  map[string]float64{"golang": 1.2, "LLM": 1.0, "kubernetes": 0.9},
 		},
 		Filters: FiltersConfig{MinOrganicScore: 0.55, MaxBotLikelihood: 0.35, Languages: []string{"en"}},
-        Engagement: EngagementConfig{MaxPerHour: 6, MaxPerDay: 40, QuietHours: []int{0, 1, 2, 3, 4, 5}, PerType: map[string]ActionBudget{"reply": {MaxPerHour: 25, MaxPerDay: 150}, "like": {MaxPerHour: 60, MaxPerDay: 400}}},
-		LLM:       LLMConfig{Provider: "none", Model: "gpt-4o-mini", APIKey: ""},
-        Storage:  StorageConfig{DBPath: "./starseed.db"},
+        Engagement: EngagementConfig{MaxPerHour: 6, MaxPerDay: 40, MinSpacingSeconds: 120, JitterSeconds: 60, QuietHours: []int{0, 1, 2, 3, 4, 5}, DraftTTLHours: 24, Cooldowns: []CooldownRule{{Scope: "author", Type: "reply", Max: 1, WindowHours: 6}, {Scope: "author", Max: 3, WindowHours: 168}, {Scope: "conversation", Type: "reply", Max: 1, WindowHours: 24}}, Auto: AutoConfig{MinScore: 0.6, KillSwitchFile: "./starseed.stop"}, PerType: map[string]ActionBudget{"reply": {MaxPerHour: 25, MaxPerDay: 150}, "like": {MaxPerHour: 60, MaxPerDay: 400}}},
+		LLM:       LLMConfig{Provider: "none", Model: "gpt-4o-mini", APIKey: "", TimeoutSeconds: 30, MaxRetries: 2, MaxTokens: 200, Temperature: Float(0.7)},
+        Persona:  PersonaConfig{Voice: "concise, wise, kind", Emoji: "none", MaxLength: 220},
+        Suggest:  SuggestConfig{Candidates: 3, Weights: ScoreWeights{Length: 0.2, Relevance: 0.3, Novelty: 0.3, Safety: 0.2}, NoveltyDays: 30},
+        Recommend: RecommendConfig{Mode: "heuristic", Damping: 0.85, MaxIterations: 50, InteractionWeight: 0.5, CommunityWeight: 0.1},
+        Embeddings: EmbeddingsConfig{Provider: "local", ModelPath: "./starseed_embed_model.json", MinSimilarity: 0.1, TimeoutSeconds: 30, MaxRetries: 2},
+        Storage:  StorageConfig{DBPath: "./starseed.db", DecisionLog: "./starseed_decisions.jsonl"},
 	}
 }
 
@@ -115,8 +292,16 @@ func (c *Config) ResolveEnv() {
     if c.Credentials.AccessSecret == "" {
         c.Credentials.AccessSecret = os.Getenv("X_ACCESS_SECRET")
     }
-	if c.LLM.APIKey == "" && c.LLM.Provider == "openai" {
-		c.LLM.APIKey = os.Getenv("OPENAI_API_KEY")
+	if c.LLM.APIKey == "" {
+		switch c.LLM.Provider {
+		case "openai", "openai-compatible":
+			c.LLM.APIKey = os.Getenv("OPENAI_API_KEY")
+		case "anthropic":
+			c.LLM.APIKey = os.Getenv("ANTHROPIC_API_KEY")
+		}
+	}
+	if c.Embeddings.APIKey == "" && (c.Embeddings.Provider == "openai" || c.Embeddings.Provider == "openai-compatible") {
+		c.Embeddings.APIKey = os.Getenv("OPENAI_API_KEY")
 	}
 }
 
@@ -131,9 +316,123 @@ func Load(path string) (Config, error) {
 		return cfg, err
 	}
 	cfg.ResolveEnv()
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
 	return cfg, nil
 }
 
+// Validate reports the first invalid engagement, LLM, persona or suggest setting.
+func (c Config) Validate() error {
+	e := c.Engagement
+	if e.MaxPerHour < 0 || e.MaxPerDay < 0 || e.MinSpacingSeconds < 0 || e.JitterSeconds < 0 {
+		return errors.New("engagement: budgets must be non-negative")
+	}
+	for typ, b := range e.PerType {
+		if b.MaxPerHour < 0 || b.MaxPerDay < 0 || b.MinSpacingSeconds < 0 { return fmt.Errorf("engagement.perType.%s: budgets must be non-negative", typ) }
+	}
+	for _, h := range e.QuietHours {
+		if h < 0 || h > 23 { return fmt.Errorf("engagement.quietHours: hour %d out of range 0-23", h) }
+	}
+	if e.Auto.MinScore < 0 || e.Auto.MinScore > 1 {
+		return errors.New("engagement.auto: minScore must be in [0,1]")
+	}
+	for i, c := range e.Cooldowns {
+		if c.Scope != "author" && c.Scope != "conversation" { return fmt.Errorf("engagement.cooldowns[%d]: scope must be author or conversation, got %q", i, c.Scope) }
+		if c.Max < 1 || c.WindowHours < 1 { return fmt.Errorf("engagement.cooldowns[%d]: max and windowHours must be positive", i) }
+	}
+	switch c.LLM.Provider {
+	case "", "none", "openai", "ollama", "anthropic":
+	case "openai-compatible":
+		if c.LLM.BaseURL == "" { return errors.New("llm.baseURL is required for openai-compatible") }
+	default:
+		return fmt.Errorf("llm.provider: unknown provider %q", c.LLM.Provider)
+	}
+	if c.LLM.API != "" && c.LLM.API != "responses" && c.LLM.API != "chat" {
+		return fmt.Errorf("llm.api: want responses or chat, got %q", c.LLM.API)
+	}
+	switch c.Persona.Emoji {
+	case "", "none", "sparing", "any":
+	default:
+		return fmt.Errorf("persona.emoji: want none, sparing or any, got %q", c.Persona.Emoji)
+	}
+	if c.Persona.MaxLength < 0 || c.Persona.MaxLength > 280 {
+		return fmt.Errorf("persona.maxLength: %d out of range 0-280", c.Persona.MaxLength)
+	}
+	w := c.Suggest.Weights
+	if c.Suggest.Candidates < 0 || w.Length < 0 || w.Relevance < 0 || w.Novelty < 0 || w.Safety < 0 {
+		return errors.New("suggest: candidates and weights must be non-negative")
+	}
+	switch c.Embeddings.Provider {
+	case "", "none", "local", "openai":
+	case "openai-compatible":
+		if c.Embeddings.BaseURL == "" { return errors.New("embeddings.baseURL is required for openai-compatible") }
+	default:
+		return fmt.Errorf("embeddings.provider: unknown provider %q", c.Embeddings.Provider)
+	}
+	if c.Embeddings.MinSimilarity < 0 || c.Embeddings.MinSimilarity >= 1 {
+		return errors.New("embeddings.minSimilarity must be in [0,1)")
+	}
+	switch c.Recommend.Mode {
+	case "", "heuristic", "pagerank", "salsa":
+	default:
+		return fmt.Errorf("recommend.mode: want heuristic, pagerank or salsa, got %q", c.Recommend.Mode)
+	}
+	if c.Recommend.Damping < 0 || c.Recommend.Damping >= 1 || c.Recommend.MaxIterations < 0 || c.Recommend.InteractionWeight < 0 || c.Recommend.CommunityWeight < 0 {
+		return errors.New("recommend: damping must be in [0,1) and maxIterations, interactionWeight and communityWeight non-negative")
+	}
+	r := c.Suggest.Rules
+	for _, name := range r.Disabled {
+		switch name {
+		case "length", "chars", "blocklist", "pii", "links", "repetition":
+		default:
+			return fmt.Errorf("suggest.rules.disabled: unknown rule %q", name)
+		}
+	}
+	switch r.Links {
+	case "", "none", "allowlist", "any":
+	default:
+		return fmt.Errorf("suggest.rules.links: want none, allowlist or any, got %q", r.Links)
+	}
+	if r.MinLength < 0 || r.MaxLength < 0 || r.MaxHashtags < 0 || r.RepetitionWindow < 0 || r.NGram < 0 || r.MaxSimilarity < 0 || r.MaxSimilarity > 1 {
+		return errors.New("suggest.rules: limits must be non-negative and maxSimilarity within 0-1")
+	}
+	sc := e.Schedule
+	if sc.Timezone != "" {
+		if _, err := time.LoadLocation(sc.Timezone); err != nil { return fmt.Errorf("engagement.schedule.timezone: %w", err) }
+	}
+	for i, q := range sc.Quiet {
+		if _, err := ParseClock(q.Start); err != nil { return fmt.Errorf("engagement.schedule.quiet[%d].start: %w", i, err) }
+		if _, err := ParseClock(q.End); err != nil { return fmt.Errorf("engagement.schedule.quiet[%d].end: %w", i, err) }
+		for _, d := range q.Days {
+			if _, err := ParseWeekday(d); err != nil { return fmt.Errorf("engagement.schedule.quiet[%d].days: %w", i, err) }
+		}
+	}
+	for _, d := range sc.Blackout {
+		if _, err := time.Parse("2006-01-02", d); err != nil { return fmt.Errorf("engagement.schedule.blackout: invalid date %q", d) }
+	}
+	return nil
+}
+
+// ParseClock converts "HH:MM" into minutes after midnight.
+func ParseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil { return 0, fmt.Errorf("invalid time %q (want HH:MM)", s) }
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// ParseWeekday accepts English weekday names or their three-letter prefixes.
+func ParseWeekday(s string) (time.Weekday, error) {
+	v := strings.ToLower(strings.TrimSpace(s))
+	if len(v) >= 3 {
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			name := strings.ToLower(d.String())
+			if v == name || v == name[:3] { return d, nil }
+		}
+	}
+	return 0, fmt.Errorf("invalid weekday %q", s)
+}
+
 // Save writes YAML config to path, creating directories as needed.
 func Save(path string, cfg Config) error {
 	if path == "" {
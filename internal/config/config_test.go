@@ -0,0 +1,26 @@
+package config
+
+import "testing"
+
+func TestValidateSchedule(t *testing.T) {
+	cfg := Default()
+	cfg.Engagement.Schedule = ScheduleConfig{
+		Timezone: "Asia/Tokyo",
+		Quiet:    []QuietRange{{Days: []string{"Sat", "sunday"}, Start: "00:00", End: "09:30"}},
+		Blackout: []string{"2025-01-01"},
+	}
+	if err := cfg.Validate(); err != nil { t.Fatalf("valid config rejected: %v", err) }
+	bad := []func(c *Config){
+		func(c *Config) { c.Engagement.Schedule.Timezone = "Mars/Olympus" },
+		func(c *Config) { c.Engagement.Schedule.Quiet[0].Start = "25:00" },
+		func(c *Config) { c.Engagement.Schedule.Quiet[0].Days = []string{"funday"} },
+		func(c *Config) { c.Engagement.Schedule.Blackout = []string{"01/01/2025"} },
+		func(c *Config) { c.Engagement.QuietHours = []int{24} },
+	}
+	for i, mutate := range bad {
+		c := cfg
+		c.Engagement.Schedule.Quiet = append([]QuietRange(nil), cfg.Engagement.Schedule.Quiet...)
+		mutate(&c)
+		if err := c.Validate(); err == nil { t.Errorf("case %d: expected validation error", i) }
+	}
+}
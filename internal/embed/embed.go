@@ -0,0 +1,131 @@
+// Package embed turns text into vectors for interest relevance: a local hashed n-gram
+// model (optionally TF-IDF + SVD trained on stored tweets) or an OpenAI-compatible
+// embeddings endpoint, with vectors cached in sqlitevec.
+package embed
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"starseed/internal/store/sqlitevec"
+)
+
+// Embedder maps texts to vectors. Name identifies the vector space, so vectors from
+// different models or trainings are never compared or mixed in the store.
+type Embedder interface {
+	Name() string
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Key is the store key of a text.
+func Key(text string) string {
+	h := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(h[:16])
+}
+
+// Cosine returns the cosine similarity of a and b (0 if either is zero or they differ in length).
+func Cosine(a, b []float32) float64 {
+	if len(a) != len(b) { return 0 }
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 { return 0 }
+	return dot / math.Sqrt(na*nb)
+}
+
+// normalize scales v to unit length in place (zero vectors are left alone).
+func normalize(v []float32) []float32 {
+	var n float64
+	for _, x := range v { n += float64(x) * float64(x) }
+	if n == 0 { return v }
+	n = math.Sqrt(n)
+	for i := range v { v[i] = float32(float64(v[i]) / n) }
+	return v
+}
+
+// Cache embeds texts through an Embedder, keeping vectors in memory and, when DB is
+// set, in the embeddings table so each text is embedded once per model.
+type Cache struct {
+	Embedder Embedder
+	DB       *sqlitevec.DB
+	// Texts per Embed call (default 64)
+	Batch int
+
+	mu  sync.Mutex
+	mem map[string][]float32
+}
+
+// Vectors returns one vector per text, embedding only texts not cached yet. The lock
+// guards the in-memory map only, so store reads and Embed calls run concurrently.
+func (c *Cache) Vectors(ctx context.Context, texts []string) ([][]float32, error) {
+	keys := make([]string, len(texts))
+	for i, t := range texts { keys[i] = Key(t) }
+	missing := c.missing(keys)
+	name := c.Embedder.Name()
+	if c.DB != nil && len(missing) > 0 {
+		stored, err := c.DB.GetEmbeddings(ctx, name, missing)
+		if err != nil { return nil, err }
+		c.put(stored)
+	}
+	var todo []string
+	seen := map[string]bool{}
+	absent := map[string]bool{}
+	for _, k := range c.missing(keys) { absent[k] = true }
+	for i, t := range texts {
+		if absent[keys[i]] && !seen[keys[i]] { todo, seen[keys[i]] = append(todo, t), true }
+	}
+	batch := c.Batch
+	if batch <= 0 { batch = 64 }
+	for i := 0; i < len(todo); i += batch {
+		end := i + batch
+		if end > len(todo) { end = len(todo) }
+		vecs, err := c.Embedder.Embed(ctx, todo[i:end])
+		if err != nil { return nil, err }
+		if len(vecs) != end-i { return nil, fmt.Errorf("embed %s: got %d vectors for %d texts", name, len(vecs), end-i) }
+		fresh := make(map[string][]float32, len(vecs))
+		for j, v := range vecs { fresh[Key(todo[i+j])] = v }
+		c.put(fresh)
+		if c.DB != nil {
+			if err := c.DB.PutEmbeddings(ctx, name, fresh, time.Now().UTC()); err != nil { return nil, err }
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([][]float32, len(texts))
+	for i := range texts { out[i] = c.mem[keys[i]] }
+	return out, nil
+}
+
+// Cached returns text's vector if it is already in memory, without embedding it.
+func (c *Cache) Cached(text string) ([]float32, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.mem[Key(text)]
+	return v, ok
+}
+
+// missing returns the keys not in memory.
+func (c *Cache) missing(keys []string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var out []string
+	for _, k := range keys {
+		if _, ok := c.mem[k]; !ok { out = append(out, k) }
+	}
+	return out
+}
+
+func (c *Cache) put(vecs map[string][]float32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.mem == nil { c.mem = map[string][]float32{} }
+	for k, v := range vecs { c.mem[k] = v }
+}
@@ -0,0 +1,184 @@
+package embed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"starseed/internal/model"
+	"starseed/internal/store/sqlitevec"
+)
+
+func embedOne(t *testing.T, e Embedder, texts ...string) [][]float32 {
+	t.Helper()
+	v, err := e.Embed(context.Background(), texts)
+	if err != nil { t.Fatal(err) }
+	return v
+}
+
+func TestLocalUntrainedMatchesWordForms(t *testing.T) {
+	v := embedOne(t, NewLocal(0), "distributed systems", "designing a distributed system for scale", "watercolor painting at sunset", "")
+	near, far := Cosine(v[0], v[1]), Cosine(v[0], v[2])
+	if near < 0.3 || near <= far+0.2 { t.Fatalf("near %.3f far %.3f", near, far) }
+	if Cosine(v[0], v[3]) != 0 { t.Fatal("empty text should embed to zero") }
+}
+
+func TestJacobi(t *testing.T) {
+	vals, vecs := jacobi([][]float64{{2, 1}, {1, 2}})
+	lo, hi := math.Min(vals[0], vals[1]), math.Max(vals[0], vals[1])
+	if math.Abs(lo-1) > 1e-9 || math.Abs(hi-3) > 1e-9 { t.Fatalf("eigenvalues %v", vals) }
+	// columns are unit eigenvectors
+	for c := 0; c < 2; c++ {
+		x, y := vecs[0][c], vecs[1][c]
+		if math.Abs(x*x+y*y-1) > 1e-9 || math.Abs(2*x+y-vals[c]*x) > 1e-9 { t.Fatalf("vector %d: %v %v", c, x, y) }
+	}
+}
+
+func TestTrainLearnsCooccurrence(t *testing.T) {
+	var docs []string
+	sys := []string{"raft consensus", "leader election", "log replication", "quorum writes", "cluster membership"}
+	art := []string{"watercolor wash", "brush strokes", "pigment palette", "paper texture", "color mixing"}
+	for i := 0; i < 120; i++ {
+		docs = append(docs, fmt.Sprintf("today %s and %s", sys[i%5], sys[(i+2)%5]))
+		docs = append(docs, fmt.Sprintf("today %s and %s", art[i%5], art[(i+3)%5]))
+	}
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	m, err := Train(docs, TrainOptions{Buckets: 1024, Dim: 8}, now)
+	if err != nil { t.Fatal(err) }
+	// no shared words: related only through co-occurrence in the corpus
+	v := embedOne(t, m, "raft consensus", "quorum writes", "pigment palette")
+	if rel, unrel := Cosine(v[0], v[1]), Cosine(v[0], v[2]); rel < 0.4 || rel <= unrel+0.3 { t.Fatalf("related %.3f unrelated %.3f", rel, unrel) }
+	if _, err := Train(docs[:10], TrainOptions{Dim: 8}, now); err == nil { t.Fatal("expected too few texts error") }
+
+	path := filepath.Join(t.TempDir(), "embed.json")
+	if err := m.Save(path); err != nil { t.Fatal(err) }
+	back, err := LoadLocalOrDefault(path)
+	if err != nil { t.Fatal(err) }
+	if back.Name() != m.Name() || back.Docs != 240 { t.Fatalf("round trip %s %d", back.Name(), back.Docs) }
+	if w := embedOne(t, back, "raft consensus"); Cosine(w[0], v[0]) < 0.9999 { t.Fatal("loaded model embeds differently") }
+	if d, err := LoadLocalOrDefault(filepath.Join(t.TempDir(), "missing.json")); err != nil || !d.Trained.IsZero() { t.Fatalf("default %v %v", d, err) }
+}
+
+func TestOpenAIEmbeddings(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" || r.Header.Get("Authorization") != "Bearer k" { http.Error(w, "bad request", 400); return }
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "text-embedding-3-small" { http.Error(w, "model", 400); return }
+		type item struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		}
+		var data []item
+		for i := len(req.Input) - 1; i >= 0; i-- { data = append(data, item{i, []float32{float32(len(req.Input[i])), 1}}) }
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+	defer srv.Close()
+	o := &OpenAI{BaseURL: srv.URL + "/v1/", APIKey: "k"}
+	v := embedOne(t, o, "a", "abc")
+	if v[0][0] != 1 || v[1][0] != 3 { t.Fatalf("order %v", v) }
+	if o.Name() != "openai:text-embedding-3-small" { t.Fatal(o.Name()) }
+	if _, err := (&OpenAI{BaseURL: srv.URL}).Embed(context.Background(), []string{"x"}); err == nil { t.Fatal("expected status error") }
+}
+
+type countingEmbedder struct{ calls, texts int }
+
+func (c *countingEmbedder) Name() string { return "count" }
+
+func (c *countingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	c.calls++
+	c.texts += len(texts)
+	out := make([][]float32, len(texts))
+	for i, t := range texts { out[i] = []float32{float32(len(t)), 1} }
+	return out, nil
+}
+
+func TestCacheStoresVectors(t *testing.T) {
+	db, err := sqlitevec.Open(":memory:")
+	if err != nil { t.Fatal(err) }
+	defer db.Close()
+	ctx := context.Background()
+	e := &countingEmbedder{}
+	c := &Cache{Embedder: e, DB: db, Batch: 2}
+	v, err := c.Vectors(ctx, []string{"a", "bb", "a", "ccc"})
+	if err != nil { t.Fatal(err) }
+	if e.texts != 3 || e.calls != 2 || v[2][0] != 1 || v[3][0] != 3 { t.Fatalf("calls %d texts %d vecs %v", e.calls, e.texts, v) }
+	// a fresh cache on the same database reads the stored vectors
+	c2 := &Cache{Embedder: e, DB: db}
+	if _, err := c2.Vectors(ctx, []string{"bb", "dddd"}); err != nil { t.Fatal(err) }
+	if e.texts != 4 { t.Fatalf("stored vectors re-embedded: %d", e.texts) }
+	stored, _ := db.GetEmbeddings(ctx, "count", []string{Key("dddd")})
+	if len(stored[Key("dddd")]) != 2 { t.Fatalf("not stored: %v", stored) }
+}
+
+func TestRelevanceMatchesMultiWordTopics(t *testing.T) {
+	ctx := context.Background()
+	r, err := NewRelevance(ctx, &Cache{Embedder: NewLocal(0)}, []string{"distributed systems", "product design"}, []string{"golang"}, nil, 0.1)
+	if err != nil { t.Fatal(err) }
+	if got := r.Topics(); len(got) != 3 || got[2] != "keywords" { t.Fatalf("topics %v", got) }
+	text := "Notes on distributed system design tradeoffs"
+	if kw := model.KeywordRelevance(text, []string{"distributed systems"}, nil); kw != 0 { t.Fatalf("keyword match %v", kw) }
+	on, off := r.Relevance(text), r.Relevance("my cat sleeps all afternoon")
+	if on <= 0.2 || off >= on/2 { t.Fatalf("on-topic %.2f off-topic %.2f", on, off) }
+
+	model.SetRelevance(r)
+	defer model.SetRelevance(nil)
+	if got := model.InterestRelevance(text, []string{"golang"}, nil); got != on { t.Fatalf("InterestRelevance %v want %v", got, on) }
+	if got := model.InterestRelevance("golang", []string{"golang"}, nil); got < 0.5 { t.Fatalf("keyword hit lost: %v", got) }
+}
+
+// flakyEmbedder fails while down; with release set, each call signals started and
+// blocks until release is closed.
+type flakyEmbedder struct {
+	countingEmbedder
+	down             bool
+	started, release chan struct{}
+}
+
+func (f *flakyEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if f.down { f.calls++; return nil, fmt.Errorf("embedder down") }
+	if f.release != nil { f.started <- struct{}{}; <-f.release }
+	return f.countingEmbedder.Embed(ctx, texts)
+}
+
+func TestRelevanceWarmsAndBacksOff(t *testing.T) {
+	ctx := context.Background()
+	f := &flakyEmbedder{}
+	r, err := NewRelevance(ctx, &Cache{Embedder: f}, []string{"go"}, nil, nil, 0.1)
+	if err != nil { t.Fatal(err) }
+	if err := r.Warm(ctx, []string{"a", "bb"}); err != nil || f.calls != 2 { t.Fatalf("warm: calls=%d %v", f.calls, err) }
+	if r.Relevance("a"); f.calls != 2 { t.Fatalf("warmed text re-embedded: calls=%d", f.calls) }
+	f.down = true
+	for i := 0; i < 3; i++ {
+		if got := r.Relevance("ccc"); got != 0 { t.Fatalf("failed embed scored %v", got) }
+	}
+	if f.calls != 3 { t.Fatalf("embedder retried within the back-off: calls=%d", f.calls) }
+	if r.Relevance("bb") == 0 { t.Fatal("cached text not scored during the back-off") }
+
+	// a slow Embed does not block readers of the cache
+	f2 := &flakyEmbedder{started: make(chan struct{}), release: make(chan struct{})}
+	c := &Cache{Embedder: f2}
+	c.put(map[string][]float32{Key("x"): {1}})
+	done := make(chan struct{})
+	go func() { _, _ = c.Vectors(ctx, []string{"slow"}); close(done) }()
+	<-f2.started
+	read := make(chan bool)
+	go func() { _, ok := c.Cached("x"); read <- ok }()
+	select {
+	case ok := <-read:
+		if !ok { t.Fatal("cached vector lost") }
+	case <-time.After(2 * time.Second):
+		t.Fatal("cache locked during Embed")
+	}
+	close(f2.release)
+	<-done
+}
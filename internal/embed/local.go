@@ -0,0 +1,166 @@
+package embed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"starseed/internal/util"
+)
+
+// Local is the offline embedder. Texts become hashed counts of word unigrams, word
+// bigrams and character trigrams (so "system" and "systems" overlap). The untrained
+// default uses those counts as the vector, which matches word forms but knows no
+// synonyms; a trained model weighs them by IDF and projects them onto the top
+// singular vectors of the TF-IDF matrix of our stored tweets (latent topics), so
+// words that occur together score as related.
+type Local struct {
+	Buckets int       `json:"buckets"`
+	Dim     int       `json:"dim"`
+	IDF     []float32 `json:"idf,omitempty"`  // per bucket; nil weighs every bucket 1
+	Proj    []float32 `json:"proj,omitempty"` // Buckets x Dim, row-major; nil: vectors are the buckets
+	Docs    int       `json:"docs"`
+	Trained time.Time `json:"trained"`
+}
+
+// NewLocal returns the untrained model over buckets hash buckets (default 1024).
+func NewLocal(buckets int) *Local {
+	if buckets <= 0 { buckets = 1024 }
+	return &Local{Buckets: buckets, Dim: buckets}
+}
+
+// Name identifies the vector space: trained models by their training time.
+func (l *Local) Name() string {
+	if l.Proj == nil { return fmt.Sprintf("local:hashed-%d", l.Buckets) }
+	return fmt.Sprintf("local:svd-%dx%d-%d", l.Buckets, l.Dim, l.Trained.Unix())
+}
+
+// Embed returns unit-length vectors (zero for texts without usable words).
+func (l *Local) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, t := range texts {
+		v := make([]float32, l.Dim)
+		for b, x := range l.features(t) {
+			if l.Proj == nil { v[b] = x; continue }
+			row := l.Proj[b*l.Dim : (b+1)*l.Dim]
+			for j := range v { v[j] += x * row[j] }
+		}
+		out[i] = normalize(v)
+	}
+	return out, nil
+}
+
+// features returns the IDF-weighted hashed n-gram counts of text by bucket.
+func (l *Local) features(text string) map[int]float32 {
+	f := map[int]float32{}
+	add := func(s string, w float32) {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(s))
+		sum := h.Sum32()
+		b := int(sum % uint32(l.Buckets))
+		if (sum/uint32(l.Buckets))&1 == 1 { w = -w }
+		f[b] += w
+	}
+	words := Words(text)
+	for i, w := range words {
+		add("w:"+w, 1)
+		if i > 0 { add("b:"+words[i-1]+" "+w, 0.5) }
+		// a word's trigrams weigh as much as the word itself
+		r := []rune("<" + w + ">")
+		n := len(r) - 2
+		for j := 0; j < n; j++ { add("c:"+string(r[j:j+3]), float32(1/math.Sqrt(float64(n)))) }
+	}
+	if l.IDF != nil {
+		for b := range f { f[b] *= l.IDF[b] }
+	}
+	return f
+}
+
+// Words lowercases text and keeps its words, dropping links, mentions and stray punctuation.
+func Words(text string) []string {
+	var out []string
+	for _, tok := range util.Tokenize(text) {
+		if strings.HasPrefix(tok, "http") || strings.HasPrefix(tok, "@") { continue }
+		if tok = strings.Trim(tok, "#\"'“”‘’-_/|*&…"); tok != "" { out = append(out, tok) }
+	}
+	return out
+}
+
+// TrainOptions control Train; zero values take defaults.
+type TrainOptions struct {
+	Buckets int   // hash buckets (default 4096)
+	Dim     int   // output dimensions (default 64)
+	Iter    int   // subspace iterations (default 4)
+	Seed    int64 // default 1
+}
+
+// Train fits IDF weights and an SVD projection to docs (latent semantic analysis).
+func Train(docs []string, opts TrainOptions, now time.Time) (*Local, error) {
+	if opts.Buckets <= 0 { opts.Buckets = 4096 }
+	if opts.Dim <= 0 { opts.Dim = 64 }
+	if opts.Iter <= 0 { opts.Iter = 4 }
+	if opts.Seed == 0 { opts.Seed = 1 }
+	if len(docs) < 2*opts.Dim { return nil, fmt.Errorf("embed: need at least %d texts to train %d dimensions, have %d", 2*opts.Dim, opts.Dim, len(docs)) }
+	l := &Local{Buckets: opts.Buckets, Dim: opts.Dim}
+	rows := make([]map[int]float32, 0, len(docs))
+	df := make([]int, l.Buckets)
+	for _, d := range docs {
+		f := l.features(d)
+		if len(f) == 0 { continue }
+		for b := range f { df[b]++ }
+		rows = append(rows, f)
+	}
+	if len(rows) < 2*opts.Dim { return nil, fmt.Errorf("embed: only %d of %d texts have words", len(rows), len(docs)) }
+	l.IDF = make([]float32, l.Buckets)
+	for b, n := range df { l.IDF[b] = float32(math.Log(float64(1+len(rows))/float64(1+n)) + 1) }
+	sparse := make([][]entry, len(rows))
+	for i, f := range rows {
+		var norm float64
+		for b, x := range f {
+			x *= l.IDF[b]
+			sparse[i] = append(sparse[i], entry{b, float64(x)})
+			norm += float64(x) * float64(x)
+		}
+		norm = math.Sqrt(norm)
+		for j := range sparse[i] { sparse[i][j].v /= norm }
+	}
+	v := topRightSingular(sparse, l.Buckets, l.Dim, opts.Iter, opts.Seed)
+	l.Proj = make([]float32, l.Buckets*l.Dim)
+	for j, col := range v {
+		for b, x := range col { l.Proj[b*l.Dim+j] = float32(x) }
+	}
+	l.Docs, l.Trained = len(rows), now
+	return l, nil
+}
+
+// LoadLocal reads a trained model.
+func LoadLocal(path string) (*Local, error) {
+	b, err := os.ReadFile(path)
+	if err != nil { return nil, err }
+	var l Local
+	if err := json.Unmarshal(b, &l); err != nil { return nil, fmt.Errorf("embedding model %s: %w", path, err) }
+	if l.Buckets <= 0 || l.Dim <= 0 || l.Proj != nil && len(l.Proj) != l.Buckets*l.Dim || l.Proj == nil && l.Dim != l.Buckets || l.IDF != nil && len(l.IDF) != l.Buckets {
+		return nil, fmt.Errorf("embedding model %s: inconsistent dimensions", path)
+	}
+	return &l, nil
+}
+
+// LoadLocalOrDefault loads path, falling back to the untrained model when the file does not exist.
+func LoadLocalOrDefault(path string) (*Local, error) {
+	l, err := LoadLocal(path)
+	if errors.Is(err, os.ErrNotExist) { return NewLocal(0), nil }
+	return l, err
+}
+
+// Save writes the model as JSON.
+func (l *Local) Save(path string) error {
+	b, err := json.Marshal(l)
+	if err != nil { return err }
+	return os.WriteFile(path, b, 0o644)
+}
@@ -0,0 +1,52 @@
+package embed
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"starseed/internal/httpjson"
+)
+
+// OpenAI calls an OpenAI-compatible POST {BaseURL}/embeddings endpoint.
+type OpenAI struct {
+	BaseURL string // default https://api.openai.com/v1
+	APIKey  string
+	Model   string // default text-embedding-3-small
+	Timeout time.Duration
+	Retries int // extra attempts after network errors, 429 and 5xx
+	HTTP    *http.Client
+}
+
+func (o *OpenAI) model() string {
+	if o.Model == "" { return "text-embedding-3-small" }
+	return o.Model
+}
+
+// Name identifies the endpoint model.
+func (o *OpenAI) Name() string { return "openai:" + o.model() }
+
+// Embed sends all texts in one request.
+func (o *OpenAI) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 { return nil, nil }
+	base := o.BaseURL
+	if base == "" { base = "https://api.openai.com/v1" }
+	var headers map[string]string
+	if o.APIKey != "" { headers = map[string]string{"Authorization": "Bearer " + o.APIKey} }
+	opts := httpjson.Options{Name: "embeddings", Timeout: o.Timeout, Retries: o.Retries, MaxBytes: 64 << 20, Client: o.HTTP}
+	var out struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := httpjson.Post(ctx, opts, strings.TrimRight(base, "/")+"/embeddings", headers, map[string]any{"model": o.model(), "input": texts}, &out); err != nil { return nil, err }
+	if len(out.Data) != len(texts) { return nil, fmt.Errorf("embeddings response: %d vectors for %d texts", len(out.Data), len(texts)) }
+	sort.Slice(out.Data, func(i, j int) bool { return out.Data[i].Index < out.Data[j].Index })
+	vecs := make([][]float32, len(texts))
+	for i, d := range out.Data { vecs[i] = d.Embedding }
+	return vecs, nil
+}
@@ -0,0 +1,142 @@
+package embed
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"starseed/internal/config"
+	"starseed/internal/logging"
+	"starseed/internal/store/sqlitevec"
+)
+
+// New returns the embedder configured in cfg (nil for provider "none").
+func New(cfg config.EmbeddingsConfig) (Embedder, error) {
+	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+	switch cfg.Provider {
+	case "none":
+		return nil, nil
+	case "openai", "openai-compatible":
+		return &OpenAI{BaseURL: cfg.BaseURL, APIKey: cfg.APIKey, Model: cfg.Model, Timeout: timeout, Retries: cfg.MaxRetries}, nil
+	}
+	path := cfg.ModelPath
+	if path == "" { path = "./starseed_embed_model.json" }
+	return LoadLocalOrDefault(path)
+}
+
+// Relevance scores texts by cosine similarity to interest centroids: one per topic and
+// one for the keywords (their vectors averaged by weight). It implements
+// model.Relevance.
+type Relevance struct {
+	cache     *Cache
+	names     []string
+	centroids [][]float32
+	// Similarity counted as zero relevance; the best centroid similarity is rescaled from [Floor,1] to [0,1]
+	Floor float64
+
+	// ctx bounds embedding calls made by Relevance, which model.Relevance gives no context
+	ctx      context.Context
+	mu       sync.Mutex
+	failedAt time.Time
+}
+
+// relevanceRetry is how long after an embedding error Relevance scores uncached texts 0
+// instead of calling the embedder again.
+var relevanceRetry = time.Minute
+
+// NewRelevance embeds the topics and keywords through c. ctx also bounds the embedding
+// calls of later Relevance calls.
+func NewRelevance(ctx context.Context, c *Cache, topics, keywords []string, weights map[string]float64, floor float64) (*Relevance, error) {
+	r := &Relevance{cache: c, Floor: floor, ctx: ctx}
+	if len(topics) > 0 {
+		vecs, err := c.Vectors(ctx, topics)
+		if err != nil { return nil, err }
+		r.names, r.centroids = append(r.names, topics...), append(r.centroids, vecs...)
+	}
+	if len(keywords) > 0 {
+		vecs, err := c.Vectors(ctx, keywords)
+		if err != nil { return nil, err }
+		var mean []float32
+		for i, v := range vecs {
+			w := 1.0
+			if x, ok := weights[strings.ToLower(keywords[i])]; ok { w = x }
+			if mean == nil { mean = make([]float32, len(v)) }
+			for j := range v { mean[j] += float32(w) * v[j] }
+		}
+		r.names, r.centroids = append(r.names, "keywords"), append(r.centroids, mean)
+	}
+	return r, nil
+}
+
+// Topics returns the centroid names: the topics, then "keywords".
+func (r *Relevance) Topics() []string { return r.names }
+
+// Warm embeds texts ahead of Relevance calls, in batches.
+func (r *Relevance) Warm(ctx context.Context, texts []string) error {
+	_, err := r.cache.Vectors(ctx, texts)
+	if err != nil { r.fail(err) }
+	return err
+}
+
+// Similarities returns the cosine similarity of text to each centroid, in Topics order.
+func (r *Relevance) Similarities(ctx context.Context, text string) ([]float64, error) {
+	vecs, err := r.cache.Vectors(ctx, []string{text})
+	if err != nil { return nil, err }
+	return r.similarities(vecs[0]), nil
+}
+
+func (r *Relevance) similarities(v []float32) []float64 {
+	out := make([]float64, len(r.centroids))
+	for i, c := range r.centroids { out[i] = Cosine(v, c) }
+	return out
+}
+
+// Relevance returns the rescaled similarity to the nearest centroid in [0,1]. Texts not
+// warmed are embedded on the spot; an embedding error scores 0, is logged, and for
+// relevanceRetry after it only cached texts are scored.
+func (r *Relevance) Relevance(text string) float64 {
+	v, ok := r.cache.Cached(text)
+	if !ok {
+		if r.failing() { return 0 }
+		ctx := r.ctx
+		if ctx == nil { ctx = context.Background() }
+		vecs, err := r.cache.Vectors(ctx, []string{text})
+		if err != nil { r.fail(err); return 0 }
+		v = vecs[0]
+	}
+	best := 0.0
+	for _, s := range r.similarities(v) {
+		if s > best { best = s }
+	}
+	if best <= r.Floor { return 0 }
+	return math.Round((best-r.Floor)/(1-r.Floor)*100) / 100
+}
+
+func (r *Relevance) failing() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return !r.failedAt.IsZero() && time.Since(r.failedAt) < relevanceRetry
+}
+
+// fail logs err unless a failure was already logged within relevanceRetry.
+func (r *Relevance) fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.failedAt.IsZero() && time.Since(r.failedAt) < relevanceRetry { return }
+	r.failedAt = time.Now()
+	logging.Error("embed_relevance", map[string]any{"err": err.Error(), "retry_in": relevanceRetry.String()})
+}
+
+// ForConfig builds the relevance scorer for cfg, caching vectors in db (nil for
+// provider "none" or when there are no topics or keywords).
+func ForConfig(ctx context.Context, cfg config.Config, db *sqlitevec.DB) (*Relevance, error) {
+	e, err := New(cfg.Embeddings)
+	if err != nil || e == nil { return nil, err }
+	in := cfg.Interests
+	if len(in.Topics) == 0 && len(in.Keywords) == 0 { return nil, nil }
+	floor := cfg.Embeddings.MinSimilarity
+	if floor == 0 { floor = 0.1 }
+	return NewRelevance(ctx, &Cache{Embedder: e, DB: db}, in.Topics, in.Keywords, in.Weights, floor)
+}
@@ -0,0 +1,141 @@
+package embed
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+type entry struct {
+	b int
+	v float64
+}
+
+// topRightSingular returns the k leading right singular vectors (each of length cols)
+// of the sparse matrix rows, scaled by their singular value relative to the first, by
+// randomized subspace iteration: a random cols x (k+10) basis is pushed through XᵀX
+// iter times and orthonormalized, then the small Gram matrix of X·Q is diagonalized to
+// rotate the basis onto the singular vectors.
+func topRightSingular(rows [][]entry, cols, k, iter int, seed int64) [][]float64 {
+	l := k + 10
+	if l > len(rows) { l = len(rows) }
+	if l > cols { l = cols }
+	rng := rand.New(rand.NewSource(seed))
+	q := make([][]float64, l)
+	for j := range q {
+		q[j] = make([]float64, cols)
+		for b := range q[j] { q[j][b] = rng.NormFloat64() }
+	}
+	orthonormalize(q)
+	for it := 0; it < iter; it++ {
+		z := multiply(rows, q)
+		for j := range q {
+			for b := range q[j] { q[j][b] = 0 }
+		}
+		for i, r := range rows {
+			for _, e := range r {
+				for j := range q { q[j][e.b] += e.v * z[i][j] }
+			}
+		}
+		orthonormalize(q)
+	}
+	z := multiply(rows, q)
+	g := make([][]float64, l)
+	for a := range g {
+		g[a] = make([]float64, l)
+		for b := range g[a] {
+			for i := range z { g[a][b] += z[i][a] * z[i][b] }
+		}
+	}
+	vals, vecs := jacobi(g)
+	order := make([]int, l)
+	for i := range order { order[i] = i }
+	sort.Slice(order, func(a, b int) bool { return vals[order[a]] > vals[order[b]] })
+	if k > l { k = l }
+	out := make([][]float64, k)
+	top := math.Sqrt(math.Max(vals[order[0]], 0))
+	for c := 0; c < k; c++ {
+		scale := 0.0
+		if top > 0 { scale = math.Sqrt(math.Max(vals[order[c]], 0)) / top }
+		col := make([]float64, cols)
+		for j := range q {
+			w := vecs[j][order[c]] * scale
+			for b := range col { col[b] += w * q[j][b] }
+		}
+		out[c] = col
+	}
+	return out
+}
+
+// multiply returns X·Q as len(rows) x len(q), with q given as columns.
+func multiply(rows [][]entry, q [][]float64) [][]float64 {
+	z := make([][]float64, len(rows))
+	for i, r := range rows {
+		z[i] = make([]float64, len(q))
+		for _, e := range r {
+			for j := range q { z[i][j] += e.v * q[j][e.b] }
+		}
+	}
+	return z
+}
+
+// orthonormalize applies modified Gram-Schmidt to the columns in place; columns that
+// collapse to zero stay zero.
+func orthonormalize(q [][]float64) {
+	for j := range q {
+		for p := 0; p < j; p++ {
+			dot := 0.0
+			for b := range q[j] { dot += q[j][b] * q[p][b] }
+			for b := range q[j] { q[j][b] -= dot * q[p][b] }
+		}
+		n := 0.0
+		for _, x := range q[j] { n += x * x }
+		if n = math.Sqrt(n); n > 1e-12 {
+			for b := range q[j] { q[j][b] /= n }
+		} else {
+			for b := range q[j] { q[j][b] = 0 }
+		}
+	}
+}
+
+// jacobi diagonalizes the symmetric matrix a (destroyed) with cyclic Jacobi rotations,
+// returning the eigenvalues and the eigenvectors as columns.
+func jacobi(a [][]float64) ([]float64, [][]float64) {
+	n := len(a)
+	v := make([][]float64, n)
+	for i := range v {
+		v[i] = make([]float64, n)
+		v[i][i] = 1
+	}
+	for sweep := 0; sweep < 100; sweep++ {
+		off := 0.0
+		for p := 0; p < n; p++ {
+			for r := p + 1; r < n; r++ { off += a[p][r] * a[p][r] }
+		}
+		if off < 1e-18 { break }
+		for p := 0; p < n; p++ {
+			for r := p + 1; r < n; r++ {
+				if math.Abs(a[p][r]) < 1e-300 { continue }
+				theta := (a[r][r] - a[p][p]) / (2 * a[p][r])
+				t := math.Copysign(1, theta) / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for i := 0; i < n; i++ {
+					aip, air := a[i][p], a[i][r]
+					a[i][p], a[i][r] = c*aip-s*air, s*aip+c*air
+				}
+				for i := 0; i < n; i++ {
+					api, ari := a[p][i], a[r][i]
+					a[p][i], a[r][i] = c*api-s*ari, s*api+c*ari
+				}
+				for i := 0; i < n; i++ {
+					vip, vir := v[i][p], v[i][r]
+					v[i][p], v[i][r] = c*vip-s*vir, s*vip+c*vir
+				}
+			}
+		}
+	}
+	vals := make([]float64, n)
+	for i := range vals { vals[i] = a[i][i] }
+	return vals, v
+}
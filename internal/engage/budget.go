@@ -2,53 +2,206 @@ package engage
 
 import (
 	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
 	"time"
 
 	"starseed/internal/config"
 	"starseed/internal/store/sqlitevec"
 )
 
-// ShouldAllowEngage checks hourly/daily budgets before engaging.
-func ShouldAllowEngage(ctx context.Context, db *sqlitevec.DB, cfg config.EngagementConfig, now time.Time) (bool, error) {
-	startHour := time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 0, 0, 0, time.UTC)
-	startDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
-	hourCount, err := db.CountActionsWithin(ctx, startHour, startHour.Add(time.Hour), "engage")
-	if err != nil {
-		return false, err
+// Verdict is the budget engine's answer for one action.
+type Verdict struct {
+	Allowed bool
+	// Earliest time the action is allowed (now when Allowed)
+	Next time.Time
+	// Binding constraint when not allowed, e.g. "reply limit 25 per hour"
+	Reason string
+}
+
+// Budget evaluates global and per-type budgets over rolling windows (last hour, last
+// 24h), with minimum spacing between actions and deterministic jitter. The global limits
+// count actions of every type. In DryRun mode Record keeps actions in memory so a
+// simulated run sees its own planned actions without touching the database.
+type Budget struct {
+	DryRun bool
+
+	db      *sqlitevec.DB
+	cfg     config.EngagementConfig
+	planned []plannedAction
+}
+
+type plannedAction struct {
+	ts     time.Time
+	typ    string
+	target Target
+}
+
+// Target identifies who an action is aimed at, for cooldown rules.
+type Target struct {
+	UserID         string
+	ConversationID string
+}
+
+// NewBudget creates a budget engine over the actions table.
+func NewBudget(db *sqlitevec.DB, cfg config.EngagementConfig) *Budget {
+	return &Budget{db: db, cfg: cfg}
+}
+
+// times returns timestamps of actions matching f in the span before now (inclusive),
+// ascending, including planned dry-run actions.
+func (b *Budget) times(ctx context.Context, f sqlitevec.ActionFilter, span time.Duration, now time.Time) ([]time.Time, error) {
+	ts, err := b.db.ActionTimesWhere(ctx, now.Add(-span+time.Second), now.Add(time.Second), f)
+	if err != nil { return nil, err }
+	for _, p := range b.planned {
+		if p.ts.After(now) || !p.ts.After(now.Add(-span)) { continue }
+		if f.Type != "" && p.typ != f.Type { continue }
+		if f.TargetUser != "" && p.target.UserID != f.TargetUser { continue }
+		if f.ConversationID != "" && p.target.ConversationID != f.ConversationID { continue }
+		ts = append(ts, p.ts)
 	}
-	dayCount, err := db.CountActionsWithin(ctx, startDay, startDay.Add(24*time.Hour), "engage")
-	if err != nil {
-		return false, err
+	sort.Slice(ts, func(i, j int) bool { return ts[i].Before(ts[j]) })
+	return ts, nil
+}
+
+// rollingNext returns when fewer than max of the sorted timestamps fall inside the
+// window ending at now, or the zero time if that is already the case.
+func rollingNext(ts []time.Time, max int, window time.Duration, now time.Time) time.Time {
+	if max <= 0 { return time.Time{} }
+	i := sort.Search(len(ts), func(i int) bool { return ts[i].After(now.Add(-window)) })
+	in := ts[i:]
+	if len(in) < max { return time.Time{} }
+	return in[len(in)-max].Add(window)
+}
+
+// jitter derives a stable extra delay in [0, max) from the last action, so repeated
+// checks agree on the same answer.
+func jitter(last time.Time, typ string, max int) time.Duration {
+	if max <= 0 { return 0 }
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%d/%s", last.Unix(), typ)
+	return time.Duration(h.Sum32()%uint32(max)) * time.Second
+}
+
+// Check decides whether one action of typ may happen at now.
+func (b *Budget) Check(ctx context.Context, typ string, now time.Time) (Verdict, error) {
+	v := Verdict{Next: now}
+	consider := func(t time.Time, why string) {
+		if t.After(v.Next) { v.Next, v.Reason = t, why }
 	}
-	if cfg.MaxPerHour > 0 && hourCount >= cfg.MaxPerHour {
-		return false, nil
+	limits := func(label string, ts []time.Time, perHour, perDay, spacing int) {
+		consider(rollingNext(ts, perHour, time.Hour, now), fmt.Sprintf("%s limit %d per hour", label, perHour))
+		consider(rollingNext(ts, perDay, 24*time.Hour, now), fmt.Sprintf("%s limit %d per day", label, perDay))
+		if spacing > 0 && len(ts) > 0 {
+			last := ts[len(ts)-1]
+			consider(last.Add(time.Duration(spacing)*time.Second+jitter(last, typ, b.cfg.JitterSeconds)), label+" min spacing")
+		}
 	}
-	if cfg.MaxPerDay > 0 && dayCount >= cfg.MaxPerDay {
-		return false, nil
+	all, err := b.times(ctx, sqlitevec.ActionFilter{}, 24*time.Hour, now)
+	if err != nil { return v, err }
+	limits("global", all, b.cfg.MaxPerHour, b.cfg.MaxPerDay, b.cfg.MinSpacingSeconds)
+	if pt, ok := b.cfg.PerType[typ]; ok {
+		own, err := b.times(ctx, sqlitevec.ActionFilter{Type: typ}, 24*time.Hour, now)
+		if err != nil { return v, err }
+		limits(typ, own, pt.MaxPerHour, pt.MaxPerDay, pt.MinSpacingSeconds)
 	}
-	return true, nil
+	v.Allowed = !v.Next.After(now)
+	return v, nil
+}
+
+// Counts reports actions in the last hour and day, globally and for typ, for audit
+// records ("global.hour", "global.day", "<typ>.hour", "<typ>.day").
+func (b *Budget) Counts(ctx context.Context, typ string, now time.Time) (map[string]int, error) {
+	out := map[string]int{}
+	count := func(label string, f sqlitevec.ActionFilter) error {
+		ts, err := b.times(ctx, f, 24*time.Hour, now)
+		if err != nil { return err }
+		out[label+".day"] = len(ts)
+		out[label+".hour"] = len(ts) - sort.Search(len(ts), func(i int) bool { return ts[i].After(now.Add(-time.Hour)) })
+		return nil
+	}
+	if err := count("global", sqlitevec.ActionFilter{}); err != nil { return out, err }
+	if typ != "" {
+		if err := count(typ, sqlitevec.ActionFilter{Type: typ}); err != nil { return out, err }
+	}
+	return out, nil
+}
+
+// CheckTarget applies the cooldown rules for an action of typ aimed at t; rules whose
+// scope has no ID in t are skipped.
+func (b *Budget) CheckTarget(ctx context.Context, typ string, t Target, now time.Time) (Verdict, error) {
+	v := Verdict{Next: now}
+	for _, r := range b.cfg.Cooldowns {
+		if r.Type != "" && r.Type != typ { continue }
+		f := sqlitevec.ActionFilter{Type: r.Type}
+		switch {
+		case r.Scope == "author" && t.UserID != "":
+			f.TargetUser = t.UserID
+		case r.Scope == "conversation" && t.ConversationID != "":
+			f.ConversationID = t.ConversationID
+		default:
+			continue
+		}
+		window := time.Duration(r.WindowHours) * time.Hour
+		ts, err := b.times(ctx, f, window, now)
+		if err != nil { return v, err }
+		if next := rollingNext(ts, r.Max, window, now); next.After(v.Next) {
+			what := r.Type
+			if what == "" { what = "action" }
+			v.Next, v.Reason = next, fmt.Sprintf("%s cooldown: %d %s per %dh", r.Scope, r.Max, plural(what, r.Max), r.WindowHours)
+		}
+	}
+	v.Allowed = !v.Next.After(now)
+	return v, nil
 }
 
-// RecordEngage logs an engagement action.
-func RecordEngage(ctx context.Context, db *sqlitevec.DB, now time.Time) error {
-	return db.PutAction(ctx, now, "engage")
+// plural is word for n items: "1 reply", "2 replies", "3 actions".
+func plural(word string, n int) string {
+	switch {
+	case n == 1:
+		return word
+	case len(word) > 1 && strings.HasSuffix(word, "y") && !strings.ContainsRune("aeiou", rune(word[len(word)-2])):
+		return word[:len(word)-1] + "ies"
+	}
+	return word + "s"
+}
+
+// NextAllowed returns the earliest time at or after now when an action of typ fits the
+// budgets, assuming no other actions happen in between.
+func (b *Budget) NextAllowed(ctx context.Context, typ string, now time.Time) (time.Time, error) {
+	v, err := b.Check(ctx, typ, now)
+	return v.Next, err
+}
+
+// Fits reports whether an action of typ at t is allowed and leaves every action planned
+// after t within the budgets too, so a dry run can plan slots out of chronological order.
+func (b *Budget) Fits(ctx context.Context, typ string, t time.Time) (bool, error) {
+	v, err := b.Check(ctx, typ, t)
+	if err != nil || !v.Allowed { return false, err }
+	for i, p := range b.planned {
+		if !p.ts.After(t) { continue }
+		// Re-check p as if t were planned, without counting p itself
+		trial := &Budget{DryRun: true, db: b.db, cfg: b.cfg, planned: make([]plannedAction, 0, len(b.planned))}
+		trial.planned = append(append(trial.planned, b.planned[:i]...), b.planned[i+1:]...)
+		trial.planned = append(trial.planned, plannedAction{ts: t, typ: typ})
+		v, err := trial.Check(ctx, p.typ, p.ts)
+		if err != nil || !v.Allowed { return false, err }
+	}
+	return true, nil
 }
 
-// ShouldAllowByType enforces per-type budgets if configured.
-func ShouldAllowByType(ctx context.Context, db *sqlitevec.DB, cfg config.EngagementConfig, typ string, now time.Time) (bool, error) {
-    b, ok := cfg.PerType[typ]
-    if !ok { return true, nil }
-    startHour := time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 0, 0, 0, time.UTC)
-    startDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
-    hourCount, err := db.CountActionsWithin(ctx, startHour, startHour.Add(time.Hour), typ)
-    if err != nil { return false, err }
-    dayCount, err := db.CountActionsWithin(ctx, startDay, startDay.Add(24*time.Hour), typ)
-    if err != nil { return false, err }
-    if b.MaxPerHour > 0 && hourCount >= b.MaxPerHour { return false, nil }
-    if b.MaxPerDay > 0 && dayCount >= b.MaxPerDay { return false, nil }
-    return true, nil
+// Record logs an action of typ (in memory when DryRun).
+func (b *Budget) Record(ctx context.Context, typ string, now time.Time) error {
+	return b.RecordTarget(ctx, typ, Target{}, now)
 }
 
-func RecordByType(ctx context.Context, db *sqlitevec.DB, typ string, now time.Time) error {
-    return db.PutAction(ctx, now, typ)
+// RecordTarget logs an action of typ aimed at t (in memory when DryRun).
+func (b *Budget) RecordTarget(ctx context.Context, typ string, t Target, now time.Time) error {
+	if b.DryRun {
+		b.planned = append(b.planned, plannedAction{ts: now, typ: typ, target: t})
+		return nil
+	}
+	return b.db.PutActionTarget(ctx, now, typ, t.UserID, t.ConversationID)
 }
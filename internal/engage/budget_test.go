@@ -9,25 +9,25 @@ import (
 	"starseed/internal/store/sqlitevec"
 )
 
-func TestShouldAllowEngageRespectsBudgets(t *testing.T) {
+func TestGlobalBudgets(t *testing.T) {
 	db, err := sqlitevec.Open(":memory:")
 	if err != nil { t.Fatal(err) }
 	defer db.Close()
 	ctx := context.Background()
 	now := time.Date(2025,1,1,12,0,0,0,time.UTC)
-	cfg := config.EngagementConfig{MaxPerHour: 2, MaxPerDay: 3}
+	b := NewBudget(db, config.EngagementConfig{MaxPerHour: 2, MaxPerDay: 3})
 	// No actions yet
-	ok, err := ShouldAllowEngage(ctx, db, cfg, now)
-	if err != nil || !ok { t.Fatalf("expected allowed, got %v %v", ok, err) }
+	v, err := b.Check(ctx, "engage", now)
+	if err != nil || !v.Allowed { t.Fatalf("expected allowed, got %+v %v", v, err) }
 	// Record two actions in hour
-	_ = RecordEngage(ctx, db, now)
-	_ = RecordEngage(ctx, db, now.Add(5*time.Minute))
-	ok, _ = ShouldAllowEngage(ctx, db, cfg, now.Add(10*time.Minute))
-	if ok { t.Fatalf("expected blocked by hourly budget") }
+	_ = b.Record(ctx, "engage", now)
+	_ = b.Record(ctx, "engage", now.Add(5*time.Minute))
+	v, _ = b.Check(ctx, "engage", now.Add(10*time.Minute))
+	if v.Allowed { t.Fatalf("expected blocked by hourly budget") }
 	// Another action next hour, but daily limit 3 blocks
-	_ = RecordEngage(ctx, db, now.Add(65*time.Minute))
-	ok, _ = ShouldAllowEngage(ctx, db, cfg, now.Add(70*time.Minute))
-	if ok { t.Fatalf("expected blocked by daily budget") }
+	_ = b.Record(ctx, "engage", now.Add(65*time.Minute))
+	v, _ = b.Check(ctx, "engage", now.Add(70*time.Minute))
+	if v.Allowed { t.Fatalf("expected blocked by daily budget") }
 }
 
 func TestPerTypeBudgets(t *testing.T) {
@@ -35,10 +35,100 @@ func TestPerTypeBudgets(t *testing.T) {
     defer db.Close()
     ctx := context.Background()
     now := time.Date(2025,1,1,12,0,0,0,time.UTC)
-    cfg := config.EngagementConfig{PerType: map[string]config.ActionBudget{"reply": {MaxPerHour: 1, MaxPerDay: 2}}}
-    ok, _ := ShouldAllowByType(ctx, db, cfg, "reply", now)
-    if !ok { t.Fatalf("expected allowed") }
-    _ = RecordByType(ctx, db, "reply", now)
-    ok, _ = ShouldAllowByType(ctx, db, cfg, "reply", now.Add(10*time.Minute))
-    if ok { t.Fatalf("expected blocked by per-hour budget") }
+    b := NewBudget(db, config.EngagementConfig{PerType: map[string]config.ActionBudget{"reply": {MaxPerHour: 1, MaxPerDay: 2}}})
+    v, _ := b.Check(ctx, "reply", now)
+    if !v.Allowed { t.Fatalf("expected allowed") }
+    _ = b.Record(ctx, "reply", now)
+    v, _ = b.Check(ctx, "reply", now.Add(10*time.Minute))
+    if v.Allowed { t.Fatalf("expected blocked by per-hour budget") }
+}
+
+func TestRollingWindowPreventsBoundaryBursts(t *testing.T) {
+    db, _ := sqlitevec.Open(":memory:")
+    defer db.Close()
+    ctx := context.Background()
+    b := NewBudget(db, config.EngagementConfig{MaxPerHour: 2})
+    t0 := time.Date(2025,1,1,12,50,0,0,time.UTC)
+    _ = b.Record(ctx, "reply", t0)
+    _ = b.Record(ctx, "like", t0.Add(5*time.Minute))
+    // A new calendar hour no longer resets the budget; the global limit counts every type
+    v, _ := b.Check(ctx, "reply", t0.Add(15*time.Minute))
+    if v.Allowed || !v.Next.Equal(t0.Add(time.Hour)) || v.Reason != "global limit 2 per hour" { t.Fatalf("unexpected %+v", v) }
+    next, _ := b.NextAllowed(ctx, "reply", t0.Add(15*time.Minute))
+    if v, _ := b.Check(ctx, "reply", next); !v.Allowed { t.Fatalf("expected allowed at %s, got %+v", next, v) }
+}
+
+func TestSpacingJitterAndPerType(t *testing.T) {
+    db, _ := sqlitevec.Open(":memory:")
+    defer db.Close()
+    ctx := context.Background()
+    now := time.Date(2025,1,1,12,0,0,0,time.UTC)
+    cfg := config.EngagementConfig{MinSpacingSeconds: 60, JitterSeconds: 30, PerType: map[string]config.ActionBudget{"reply": {MaxPerDay: 1, MinSpacingSeconds: 600}}}
+    b := NewBudget(db, cfg)
+    _ = b.Record(ctx, "like", now)
+    v, _ := b.Check(ctx, "like", now.Add(30*time.Second))
+    if v.Allowed || v.Next.Before(now.Add(60*time.Second)) || v.Next.After(now.Add(90*time.Second)) { t.Fatalf("expected spacing with jitter, got %+v", v) }
+    if again, _ := b.Check(ctx, "like", now.Add(40*time.Second)); !again.Next.Equal(v.Next) { t.Fatalf("jitter must be stable: %s vs %s", again.Next, v.Next) }
+    _ = b.Record(ctx, "reply", now.Add(2*time.Minute))
+    v, _ = b.Check(ctx, "reply", now.Add(20*time.Minute))
+    if v.Allowed || !v.Next.Equal(now.Add(2*time.Minute+24*time.Hour)) || v.Reason != "reply limit 1 per day" { t.Fatalf("expected daily reply limit, got %+v", v) }
+    if v, _ := b.Check(ctx, "like", now.Add(20*time.Minute)); !v.Allowed { t.Fatalf("likes are not limited by the reply budget") }
+}
+
+func TestDryRunRecordsInMemory(t *testing.T) {
+    db, _ := sqlitevec.Open(":memory:")
+    defer db.Close()
+    ctx := context.Background()
+    now := time.Date(2025,1,1,12,0,0,0,time.UTC)
+    b := NewBudget(db, config.EngagementConfig{MaxPerHour: 1})
+    b.DryRun = true
+    _ = b.Record(ctx, "reply", now)
+    if v, _ := b.Check(ctx, "reply", now); v.Allowed { t.Fatalf("planned action should count") }
+    if n, _ := db.CountActionsWithin(ctx, now.Add(-time.Hour), now.Add(time.Hour), ""); n != 0 { t.Fatalf("dry run wrote %d actions", n) }
+}
+
+func TestCooldownRulesPerAuthorAndConversation(t *testing.T) {
+    db, _ := sqlitevec.Open(":memory:")
+    defer db.Close()
+    ctx := context.Background()
+    now := time.Date(2025,1,1,12,0,0,0,time.UTC)
+    cfg := config.EngagementConfig{Cooldowns: []config.CooldownRule{
+        {Scope: "author", Type: "reply", Max: 1, WindowHours: 6},
+        {Scope: "author", Max: 3, WindowHours: 168},
+        {Scope: "conversation", Type: "reply", Max: 1, WindowHours: 24},
+    }}
+    b := NewBudget(db, cfg)
+    alice := Target{UserID: "alice", ConversationID: "c1"}
+    _ = b.RecordTarget(ctx, "reply", alice, now)
+    v, _ := b.CheckTarget(ctx, "reply", Target{UserID: "alice", ConversationID: "c2"}, now.Add(time.Hour))
+    if v.Allowed || !v.Next.Equal(now.Add(6*time.Hour)) || v.Reason != "author cooldown: 1 reply per 6h" { t.Fatalf("unexpected %+v", v) }
+    v, _ = b.CheckTarget(ctx, "reply", Target{UserID: "bob", ConversationID: "c1"}, now.Add(7*time.Hour))
+    if v.Allowed || !v.Next.Equal(now.Add(24*time.Hour)) { t.Fatalf("expected conversation cooldown, got %+v", v) }
+    if v, _ := b.CheckTarget(ctx, "like", alice, now.Add(time.Minute)); !v.Allowed { t.Fatalf("likes only count toward the weekly cap, got %+v", v) }
+    _ = b.RecordTarget(ctx, "like", alice, now.Add(time.Hour))
+    _ = b.RecordTarget(ctx, "like", alice, now.Add(2*time.Hour))
+    v, _ = b.CheckTarget(ctx, "like", alice, now.Add(48*time.Hour))
+    if v.Allowed || !v.Next.Equal(now.Add(168*time.Hour)) || v.Reason != "author cooldown: 3 actions per 168h" { t.Fatalf("expected weekly cap, got %+v", v) }
+    ts, _ := db.ActionTimesWhere(ctx, now, now.Add(time.Hour), sqlitevec.ActionFilter{TargetUser: "alice", ConversationID: "c1"})
+    if len(ts) != 1 { t.Fatalf("expected target columns stored, got %v", ts) }
+}
+
+func TestCountsRollingWindows(t *testing.T) {
+	db, err := sqlitevec.Open(":memory:")
+	if err != nil { t.Fatal(err) }
+	defer db.Close()
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	_ = db.PutAction(ctx, now.Add(-3*time.Hour), "reply")
+	_ = db.PutAction(ctx, now.Add(-30*time.Minute), "like")
+	_ = db.PutAction(ctx, now.Add(-25*time.Hour), "reply")
+	b := NewBudget(db, config.EngagementConfig{})
+	b.DryRun = true
+	_ = b.Record(ctx, "reply", now.Add(-10*time.Minute))
+	got, err := b.Counts(ctx, "reply", now)
+	if err != nil { t.Fatal(err) }
+	want := map[string]int{"global.hour": 2, "global.day": 3, "reply.hour": 1, "reply.day": 2}
+	for k, v := range want {
+		if got[k] != v { t.Errorf("%s = %d, want %d (%v)", k, got[k], v, got) }
+	}
 }
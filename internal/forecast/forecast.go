@@ -0,0 +1,145 @@
+package forecast
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"starseed/internal/engage"
+	"starseed/internal/nn"
+	"starseed/internal/store/sqlitevec"
+)
+
+// Window is an upcoming 15-minute slot and its predicted engagement value.
+type Window struct {
+	Start time.Time
+	Score float32
+}
+
+// Scorer predicts values for feature vectors (first output is used).
+type Scorer func(samples []nn.FeatureVector) ([][]float32, error)
+
+// ModelScorer scores with the Rust model via nn.Infer.
+func ModelScorer(binaryPath, modelPath string) Scorer {
+	return func(samples []nn.FeatureVector) ([][]float32, error) { return nn.Infer(binaryPath, modelPath, samples) }
+}
+
+// Options controls the forecast horizon and history used for projection.
+type Options struct {
+	Horizon time.Duration          // default 24h
+	Weeks   int                    // past weeks of same hour-of-week windows (default 4)
+	Skip    func(t time.Time) bool // e.g., quiet hours; skipped windows are not scored
+}
+
+const step = 15 * time.Minute
+
+// Forecast projects features for every 15-minute window in the horizon, scores them,
+// and returns them ranked best first (ties keep chronological order).
+func Forecast(ctx context.Context, db *sqlitevec.DB, score Scorer, now time.Time, opts Options) ([]Window, error) {
+	if opts.Horizon <= 0 { opts.Horizon = 24 * time.Hour }
+	if opts.Weeks <= 0 { opts.Weeks = 4 }
+	var starts []time.Time
+	var samples []nn.FeatureVector
+	for ws := now.Truncate(step).Add(step); ws.Before(now.Add(opts.Horizon)); ws = ws.Add(step) {
+		if opts.Skip != nil && opts.Skip(ws) { continue }
+		fv, err := ProjectFeatures(ctx, db, ws, now, opts.Weeks)
+		if err != nil { return nil, err }
+		starts = append(starts, ws)
+		samples = append(samples, fv)
+	}
+	if len(samples) == 0 { return nil, nil }
+	preds, err := score(samples)
+	if err != nil { return nil, err }
+	out := make([]Window, len(starts))
+	for i, ws := range starts {
+		out[i] = Window{Start: ws}
+		if i < len(preds) && len(preds[i]) > 0 { out[i].Score = preds[i][0] }
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	return out, nil
+}
+
+// ProjectFeatures builds a feature vector for a future window: volume slots come from the
+// same hour-of-week in past weeks, rolling slots from the most recent hour of history,
+// time-of-week from the window itself, and meta slots from the latest stored window.
+func ProjectFeatures(ctx context.Context, db *sqlitevec.DB, windowStart, now time.Time, weeks int) (nn.FeatureVector, error) {
+	fv := nn.BuildFeatures(windowStart, nil, nil)
+	var past [][]float32
+	for k := 1; k <= weeks; k++ {
+		ws := windowStart.Add(-time.Duration(k) * 7 * 24 * time.Hour)
+		_, X, _, err := db.LoadFeatures(ctx, ws, ws.Add(step))
+		if err != nil { return fv, err }
+		past = append(past, X...)
+	}
+	if len(past) == 0 {
+		// No weekly history yet: fall back to the same hour of day over the past week.
+		for d := 1; d <= 7; d++ {
+			ws := windowStart.Add(-time.Duration(d) * 24 * time.Hour)
+			if ws.After(now) { continue }
+			_, X, _, err := db.LoadFeatures(ctx, ws, ws.Add(step))
+			if err != nil { return fv, err }
+			past = append(past, X...)
+		}
+	}
+	copyMean(fv.X, past, 0, 8)
+	_, recent, _, err := db.LoadFeatures(ctx, now.Add(-time.Hour), now)
+	if err != nil { return fv, err }
+	if len(recent) > 0 {
+		var meanCount, meanAvgLikes float32
+		for _, v := range recent {
+			if len(v) >= 6 { meanCount += v[0]; meanAvgLikes += v[5] }
+		}
+		n := float32(len(recent))
+		for i := 0; i < 4; i++ {
+			fv.X[8+2*i] = meanCount / n
+			fv.X[8+2*i+1] = meanAvgLikes / n
+		}
+	}
+	_, day, _, err := db.LoadFeatures(ctx, now.Add(-24*time.Hour), now)
+	if err != nil { return fv, err }
+	if len(day) > 0 {
+		last := day[len(day)-1]
+		for i := 18; i < len(fv.X) && i < len(last); i++ { fv.X[i] = last[i] }
+	}
+	return fv, nil
+}
+
+func copyMean(dst []float32, rows [][]float32, from, to int) {
+	if len(rows) == 0 { return }
+	for j := from; j < to && j < len(dst); j++ {
+		sum, n := float32(0), 0
+		for _, r := range rows {
+			if j < len(r) { sum += r[j]; n++ }
+		}
+		if n > 0 { dst[j] = sum / float32(n) }
+	}
+}
+
+// Assign hands out up to n slots of typ from ranked windows, best first, one per window.
+// A window is taken when the action fits b there without pushing a slot taken earlier
+// over a rolling limit, spacing or per-type budget; taken slots are recorded in b, which
+// should be a DryRun budget. The returned times are in rank order and may be fewer than
+// n when budgets run out.
+func Assign(ctx context.Context, b *engage.Budget, typ string, ranked []Window, n int) ([]time.Time, error) {
+	var out []time.Time
+	for _, w := range ranked {
+		if len(out) >= n { break }
+		ok, err := b.Fits(ctx, typ, w.Start)
+		if err != nil { return out, err }
+		if !ok { continue }
+		if err := b.Record(ctx, typ, w.Start); err != nil { return out, err }
+		out = append(out, w.Start)
+	}
+	return out, nil
+}
+
+// Chronological lists the windows in the horizon after now in time order, all scored
+// zero, skipping those skip rejects: the fallback when there is no forecast.
+func Chronological(now time.Time, horizon time.Duration, skip func(time.Time) bool) []Window {
+	var out []Window
+	for ws := now.Truncate(step).Add(step); ws.Before(now.Add(horizon)); ws = ws.Add(step) {
+		if skip != nil && skip(ws) { continue }
+		out = append(out, Window{Start: ws})
+	}
+	return out
+}
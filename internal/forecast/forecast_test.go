@@ -0,0 +1,68 @@
+package forecast
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"starseed/internal/config"
+	"starseed/internal/engage"
+	"starseed/internal/nn"
+	"starseed/internal/store/sqlitevec"
+)
+
+func TestForecastRanksByHourOfWeekHistory(t *testing.T) {
+	db, _ := sqlitevec.Open(":memory:")
+	defer db.Close()
+	ctx := context.Background()
+	now := time.Date(2024, 5, 6, 9, 7, 0, 0, time.UTC)
+	// Last week 14:00 was busy, everything else quiet.
+	busy := time.Date(2024, 4, 29, 14, 0, 0, 0, time.UTC)
+	vec := make([]float32, 23)
+	vec[0] = 5
+	if err := db.PutFeature(ctx, busy, vec, nil, nil); err != nil { t.Fatal(err) }
+	score := func(samples []nn.FeatureVector) ([][]float32, error) {
+		out := make([][]float32, len(samples))
+		for i, s := range samples { out[i] = []float32{s.X[0]} }
+		return out, nil
+	}
+	skip := func(ts time.Time) bool { return ts.Hour() == 3 }
+	ranked, err := Forecast(ctx, db, score, now, Options{Skip: skip})
+	if err != nil { t.Fatal(err) }
+	if len(ranked) != 96-4 { t.Fatalf("expected 92 windows, got %d", len(ranked)) }
+	if !ranked[0].Start.Equal(time.Date(2024, 5, 6, 14, 0, 0, 0, time.UTC)) || ranked[0].Score != 5 {
+		t.Fatalf("unexpected best window %+v", ranked[0])
+	}
+	if !ranked[1].Start.Equal(time.Date(2024, 5, 6, 9, 15, 0, 0, time.UTC)) { t.Fatalf("ties should stay chronological, got %v", ranked[1].Start) }
+	for _, w := range ranked {
+		if w.Start.Hour() == 3 { t.Fatalf("skipped window %v was scored", w.Start) }
+	}
+}
+
+func TestAssignRespectsBudgets(t *testing.T) {
+	db, _ := sqlitevec.Open(":memory:")
+	defer db.Close()
+	ctx := context.Background()
+	base := time.Date(2024, 5, 6, 10, 0, 0, 0, time.UTC)
+	ranked := []Window{
+		{Start: base.Add(30 * time.Minute), Score: 0.9},
+		{Start: base, Score: 0.8},
+		{Start: base.Add(15 * time.Minute), Score: 0.7},
+		{Start: base.Add(2 * time.Hour), Score: 0.5},
+	}
+	check := func(b *engage.Budget, want ...time.Time) {
+		t.Helper()
+		b.DryRun = true
+		got, err := Assign(ctx, b, "reply", ranked, 5)
+		if err != nil { t.Fatal(err) }
+		if len(got) != len(want) { t.Fatalf("expected %v, got %v", want, got) }
+		for i := range want {
+			if !got[i].Equal(want[i]) { t.Fatalf("slot %d: got %v want %v", i, got[i], want[i]) }
+		}
+	}
+	// 10:15 would push the 10:30 slot taken first over the rolling hourly limit
+	check(engage.NewBudget(db, config.EngagementConfig{MaxPerHour: 2, MaxPerDay: 3}), base.Add(30*time.Minute), base, base.Add(2*time.Hour))
+	// A reply already posted at 9:55 spaces out the windows right after it
+	if err := db.PutAction(ctx, base.Add(-5*time.Minute), "reply"); err != nil { t.Fatal(err) }
+	check(engage.NewBudget(db, config.EngagementConfig{PerType: map[string]config.ActionBudget{"reply": {MinSpacingSeconds: 1800}}}), base.Add(30*time.Minute), base.Add(2*time.Hour))
+}
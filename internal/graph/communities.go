@@ -0,0 +1,304 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"starseed/internal/store/sqlitevec"
+	"starseed/internal/util"
+)
+
+// CommunityOptions control Communities; zero values take defaults.
+type CommunityOptions struct {
+	MaxIter int   // label propagation sweeps (default 20)
+	Seed    int64 // node order seed (default 1), so runs are reproducible
+	// Edge weight per cached reply between two accounts (default 0.5)
+	InteractionWeight float64
+	MinSize           int       // smaller communities are reported as unclustered (default 3)
+	Keywords          int       // label terms per community (default 5)
+	Since             time.Time // engagement events counted from (zero: 30 days before now)
+}
+
+func (o CommunityOptions) withDefaults() CommunityOptions {
+	if o.MaxIter <= 0 { o.MaxIter = 20 }
+	if o.Seed == 0 { o.Seed = 1 }
+	if o.InteractionWeight <= 0 { o.InteractionWeight = 0.5 }
+	if o.MinSize <= 0 { o.MinSize = 3 }
+	if o.Keywords <= 0 { o.Keywords = 5 }
+	return o
+}
+
+// Community is a cluster of the accounts we follow.
+type Community struct {
+	ID         int        `json:"id"`
+	Members    []string   `json:"members"`
+	Keywords   []string   `json:"keywords"`
+	Engagement Engagement `json:"engagement"`
+}
+
+// Engagement summarizes stored events involving a community's members.
+type Engagement struct {
+	Outbound int `json:"outbound"` // our likes, replies and retweets of their tweets
+	Inbound  int `json:"inbound"`  // their replies to and quotes of our tweets
+	Seen     int `json:"seen"`     // their tweets in our home timeline
+	Engaged  int `json:"engaged"`  // members we engaged with at least once
+	// Share of our outbound engagement over the community's share of followings; below 1
+	// the circle gets less of our attention than its size suggests
+	Served float64 `json:"served"`
+}
+
+// UnderservedBelow is the Served value under which a community counts as under-served.
+const UnderservedBelow = 1.0
+
+// CommunityReport is the result of Communities.
+type CommunityReport struct {
+	Communities []Community `json:"communities"`
+	// Followings in communities smaller than MinSize (or without stored edges)
+	Unclustered []string `json:"unclustered"`
+	// Our outbound engagement with all followings in the window
+	Outbound int `json:"outbound"`
+}
+
+// Remove drops id and its edges from the graph.
+func (g *Graph) Remove(id string) {
+	for dst := range g.out[id] { delete(g.in[dst], id) }
+	for src := range g.in[id] { delete(g.out[src], id) }
+	delete(g.out, id)
+	delete(g.in, id)
+}
+
+// LabelPropagation clusters the graph, treating edges as undirected: every node starts
+// in its own community and repeatedly adopts the label with the most edge weight among
+// its neighbors (keeping its own on ties) until no label changes. Communities are
+// numbered from 0 by decreasing size.
+func LabelPropagation(g *Graph, opts CommunityOptions) map[string]int {
+	opts = opts.withDefaults()
+	nodes := g.Nodes()
+	nbr := make(map[string]map[string]float64, len(nodes))
+	label := make(map[string]int, len(nodes))
+	for i, n := range nodes {
+		label[n] = i
+		m := map[string]float64{}
+		for v, w := range g.out[n] { m[v] += w }
+		for v, w := range g.in[n] { m[v] += w }
+		nbr[n] = m
+	}
+	rng := rand.New(rand.NewSource(opts.Seed))
+	order := append([]string(nil), nodes...)
+	for it := 0; it < opts.MaxIter; it++ {
+		rng.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+		changed := false
+		for _, n := range order {
+			if len(nbr[n]) == 0 { continue }
+			tally := map[int]float64{}
+			for v, w := range nbr[n] { tally[label[v]] += w }
+			best, bestW := label[n], tally[label[n]]
+			for l, w := range tally {
+				if w > bestW || w == bestW && best != label[n] && l < best { best, bestW = l, w }
+			}
+			if best != label[n] { label[n], changed = best, true }
+		}
+		if !changed { break }
+	}
+	// renumber by size, then by first member
+	members := map[int][]string{}
+	for _, n := range nodes { members[label[n]] = append(members[label[n]], n) }
+	ls := make([]int, 0, len(members))
+	for l := range members { ls = append(ls, l) }
+	sort.Slice(ls, func(i, j int) bool {
+		a, b := members[ls[i]], members[ls[j]]
+		if len(a) != len(b) { return len(a) > len(b) }
+		return a[0] < b[0]
+	})
+	out := make(map[string]int, len(nodes))
+	for i, l := range ls {
+		for _, n := range members[l] { out[n] = i }
+	}
+	return out
+}
+
+// Communities clusters the accounts me follows by label propagation over the stored
+// follow graph (without me, so accounts two hops out bridge our followings) plus reply
+// edges between cached tweets. Each community is labeled with the terms that set its
+// members' bios and cached tweets apart, and gets engagement stats from the events since
+// opts.Since. Communities are ordered by size.
+func Communities(ctx context.Context, db *sqlitevec.DB, me string, opts CommunityOptions, now time.Time) (CommunityReport, error) {
+	opts = opts.withDefaults()
+	var rep CommunityReport
+	follows, err := db.Following(ctx, me)
+	if err != nil || len(follows) == 0 { return rep, err }
+	g, err := Load(ctx, db)
+	if err != nil { return rep, err }
+	replies, err := db.ReplyEdges(ctx)
+	if err != nil { return rep, err }
+	for _, e := range replies { g.AddEdge(e.Src, e.Dst, opts.InteractionWeight*float64(e.Count)) }
+	g.Remove(me)
+	labels := LabelPropagation(g, opts)
+	groups := map[int][]string{}
+	for _, id := range follows {
+		l, ok := labels[id]
+		if !ok { rep.Unclustered = append(rep.Unclustered, id); continue }
+		groups[l] = append(groups[l], id)
+	}
+	ls := make([]int, 0, len(groups))
+	for l, m := range groups {
+		if len(m) < opts.MinSize { rep.Unclustered = append(rep.Unclustered, m...); continue }
+		ls = append(ls, l)
+	}
+	sort.Slice(ls, func(i, j int) bool {
+		if len(groups[ls[i]]) != len(groups[ls[j]]) { return len(groups[ls[i]]) > len(groups[ls[j]]) }
+		return ls[i] < ls[j]
+	})
+	sort.Strings(rep.Unclustered)
+	terms, err := memberTerms(ctx, db, follows)
+	if err != nil { return rep, err }
+	var sets [][]string
+	for i, l := range ls {
+		sort.Strings(groups[l])
+		rep.Communities = append(rep.Communities, Community{ID: i + 1, Members: groups[l]})
+		sets = append(sets, groups[l])
+	}
+	for i, kw := range labelTerms(sets, terms, opts.Keywords) { rep.Communities[i].Keywords = kw }
+	since := opts.Since
+	if since.IsZero() { since = now.Add(-30 * 24 * time.Hour) }
+	return rep, engagement(ctx, db, &rep, follows, since, now)
+}
+
+// memberTerms returns the distinct label terms in each account's bio and cached tweets.
+func memberTerms(ctx context.Context, db *sqlitevec.DB, ids []string) (map[string]map[string]bool, error) {
+	users, err := db.GetUsers(ctx, ids)
+	if err != nil { return nil, err }
+	out := make(map[string]map[string]bool, len(ids))
+	for _, id := range ids {
+		text := users[id].Description
+		tweets, err := db.TweetsByAuthor(ctx, id, 20)
+		if err != nil { return nil, err }
+		for _, t := range tweets { text += " " + t.Text }
+		set := map[string]bool{}
+		for _, tok := range util.Tokenize(text) {
+			if tok = labelTerm(tok); tok != "" { set[tok] = true }
+		}
+		out[id] = set
+	}
+	return out, nil
+}
+
+var stopwords = map[string]bool{}
+
+func init() {
+	for _, w := range strings.Fields(`the and for with that this from you your are was were have has had not but all any can will just get got out about into over more most some what when where who why how than then them they their there here its it's ours also been being one two new now via amp like make made much many very really would could should i'm don't can't let's yes our day days today time year years people thing things way see know think want need good great love thanks thank rt`) {
+		stopwords[w] = true
+	}
+}
+
+// labelTerm normalizes a token for community labels ("" when it is not a useful term).
+func labelTerm(tok string) string {
+	if strings.HasPrefix(tok, "@") || strings.HasPrefix(tok, "http") { return "" }
+	tok = strings.Trim(tok, "#\"'“”‘’-_/|*&…")
+	if len([]rune(tok)) < 3 || stopwords[tok] { return "" }
+	if strings.Trim(tok, "0123456789") == "" { return "" }
+	return tok
+}
+
+// labelTerms picks up to n terms per community, scoring each by the share of members
+// using it times its inverse frequency across all members; a term must be used by at
+// least two members.
+func labelTerms(communities [][]string, terms map[string]map[string]bool, n int) [][]string {
+	df, total := map[string]int{}, 0
+	for _, m := range communities {
+		for _, id := range m {
+			total++
+			for t := range terms[id] { df[t]++ }
+		}
+	}
+	out := make([][]string, len(communities))
+	for i, m := range communities {
+		count := map[string]int{}
+		for _, id := range m {
+			for t := range terms[id] { count[t]++ }
+		}
+		type scored struct { term string; score float64 }
+		var cands []scored
+		for t, c := range count {
+			if c < 2 { continue }
+			cands = append(cands, scored{t, float64(c) / float64(len(m)) * math.Log(1+float64(total)/float64(df[t]))})
+		}
+		sort.Slice(cands, func(a, b int) bool {
+			if cands[a].score != cands[b].score { return cands[a].score > cands[b].score }
+			return cands[a].term < cands[b].term
+		})
+		for j := 0; j < len(cands) && j < n; j++ { out[i] = append(out[i], cands[j].term) }
+	}
+	return out
+}
+
+// engagement fills per-community stats from events in [since, now). Our replies are
+// attributed to the account recorded on the event, else the author of the cached tweet
+// they answer, else the draft or targeted reply action for that tweet or conversation.
+func engagement(ctx context.Context, db *sqlitevec.DB, rep *CommunityReport, follows []string, since, now time.Time) error {
+	evts, err := db.LoadEventsRange(ctx, since, now, "")
+	if err != nil { return err }
+	type payload struct {
+		AuthorID    string `json:"author_id"`
+		ReplyTo     string `json:"reply_to_id"`
+		ReplyToUser string `json:"reply_to_user_id"`
+		Convo       string `json:"conversation_id"`
+	}
+	ps := make([]payload, len(evts))
+	var parents, convos []string
+	for i, e := range evts {
+		_ = json.Unmarshal([]byte(e.Payload), &ps[i])
+		if e.Type != "out_reply" || ps[i].ReplyToUser != "" { continue }
+		if ps[i].ReplyTo != "" { parents = append(parents, ps[i].ReplyTo) }
+		if ps[i].Convo != "" { convos = append(convos, ps[i].Convo) }
+	}
+	authors, err := db.TweetAuthors(ctx, parents)
+	if err != nil { return err }
+	drafted, targeted, err := db.ReplyTargets(ctx, parents, convos)
+	if err != nil { return err }
+	replyTarget := func(p payload) string {
+		for _, id := range []string{p.ReplyToUser, authors[p.ReplyTo], drafted[p.ReplyTo], targeted[p.Convo]} {
+			if id != "" { return id }
+		}
+		return ""
+	}
+	member := map[string]int{}
+	for _, id := range follows { member[id] = -1 }
+	for i, c := range rep.Communities {
+		for _, id := range c.Members { member[id] = i }
+	}
+	engaged := make([]map[string]bool, len(rep.Communities))
+	for i := range engaged { engaged[i] = map[string]bool{} }
+	for i, e := range evts {
+		author := ps[i].AuthorID
+		if e.Type == "out_reply" { author = replyTarget(ps[i]) }
+		c, ok := member[author]
+		if !ok { continue }
+		out := e.Type == "like" || e.Type == "retweet" || e.Type == "out_reply"
+		if out { rep.Outbound++ }
+		if c < 0 { continue }
+		st := &rep.Communities[c].Engagement
+		switch e.Type {
+		case "like", "retweet", "out_reply":
+			st.Outbound++
+			engaged[c][author] = true
+		case "reply", "quote":
+			st.Inbound++
+		case "home":
+			st.Seen++
+		}
+	}
+	for i := range rep.Communities {
+		st := &rep.Communities[i].Engagement
+		st.Engaged = len(engaged[i])
+		if rep.Outbound > 0 {
+			st.Served = (float64(st.Outbound) / float64(rep.Outbound)) / (float64(len(rep.Communities[i].Members)) / float64(len(follows)))
+		}
+	}
+	return nil
+}
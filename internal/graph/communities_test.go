@@ -0,0 +1,90 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"starseed/internal/model"
+)
+
+// cliques returns two 4-cliques a0..a3 and b0..b3 joined by the single edge a0->b0.
+func cliques() *Graph {
+	g := New()
+	for _, p := range []string{"a", "b"} {
+		for i := 0; i < 4; i++ {
+			for j := 0; j < 4; j++ {
+				if i != j { g.AddEdge(fmt.Sprint(p, i), fmt.Sprint(p, j), 1) }
+			}
+		}
+	}
+	g.AddEdge("a0", "b0", 1)
+	return g
+}
+
+func TestLabelPropagationSplitsCliques(t *testing.T) {
+	for seed := int64(1); seed <= 5; seed++ {
+		l := LabelPropagation(cliques(), CommunityOptions{Seed: seed})
+		for i := 1; i < 4; i++ {
+			if l[fmt.Sprint("a", i)] != l["a0"] || l[fmt.Sprint("b", i)] != l["b0"] { t.Fatalf("seed %d: clique split: %v", seed, l) }
+		}
+		if l["a0"] == l["b0"] { t.Fatalf("seed %d: cliques merged: %v", seed, l) }
+	}
+	g := cliques()
+	g.Remove("a0")
+	if l := LabelPropagation(g, CommunityOptions{}); len(l) != 7 { t.Fatalf("removed node still labeled: %v", l) }
+}
+
+func TestCommunitiesLabelsAndEngagement(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	var all []string
+	var users []model.User
+	bios := map[string]string{"a": "golang kubernetes backend engineer", "b": "watercolor painting illustration artist"}
+	for _, p := range []string{"a", "b"} {
+		for i := 0; i < 4; i++ {
+			id := fmt.Sprint(p, i)
+			all = append(all, id)
+			users = append(users, model.User{ID: id, Username: "user_" + id, Description: bios[p]})
+			var dsts []string
+			for j := 0; j < 4; j++ {
+				if j != i { dsts = append(dsts, fmt.Sprint(p, j)) }
+			}
+			if _, err := db.ReplaceFollowing(ctx, id, dsts, 0, now); err != nil { t.Fatal(err) }
+		}
+	}
+	all = append(all, "loner")
+	if _, err := db.ReplaceFollowing(ctx, "me", all, 0, now); err != nil { t.Fatal(err) }
+	if err := db.PutUsers(ctx, users, now); err != nil { t.Fatal(err) }
+	// b1's tweet we replied to, and a reply edge that must not merge the two circles
+	tweets := []model.Tweet{{ID: "t1", AuthorID: "b1", Text: "new painting", CreatedAt: now.Add(-time.Hour)}, {ID: "t2", AuthorID: "a1", Text: "nice", ReplyToID: "t1", CreatedAt: now.Add(-time.Hour)}}
+	if err := db.PutTweets(ctx, tweets, now); err != nil { t.Fatal(err) }
+	for i, id := range []string{"a0", "a1", "a2", "a0"} {
+		_ = db.PutEventRef(ctx, now.Add(-time.Hour), "like", fmt.Sprint("l", i), map[string]any{"tweet_id": fmt.Sprint("l", i), "author_id": id})
+	}
+	_ = db.PutEventRef(ctx, now.Add(-time.Hour), "out_reply", "r1", map[string]any{"tweet_id": "r1", "reply_to_id": "t1"})
+	// replies to uncached tweets: one posted from the queue carries the account, one is
+	// matched to its targeted reply action by conversation
+	_ = db.PutEventRef(ctx, now.Add(-time.Hour), "out_reply", "r3", map[string]any{"tweet_id": "r3", "reply_to_id": "t8", "reply_to_user_id": "b2"})
+	_ = db.PutActionTarget(ctx, now.Add(-time.Hour), "reply", "b3", "c9")
+	_ = db.PutEventRef(ctx, now.Add(-time.Hour), "out_reply", "r4", map[string]any{"tweet_id": "r4", "reply_to_id": "t9", "conversation_id": "c9"})
+	_ = db.PutEventRef(ctx, now.Add(-time.Hour), "reply", "r2", map[string]any{"tweet_id": "r2", "author_id": "b2"})
+	_ = db.PutEventRef(ctx, now.Add(-time.Hour), "like", "l9", map[string]any{"tweet_id": "l9", "author_id": "b3"})
+	_ = db.PutEventRef(ctx, now.Add(-40*24*time.Hour), "like", "older", map[string]any{"tweet_id": "older", "author_id": "b3"})
+
+	rep, err := Communities(ctx, db, "me", CommunityOptions{Since: now.Add(-7 * 24 * time.Hour), Keywords: 2}, now)
+	if err != nil { t.Fatal(err) }
+	if len(rep.Communities) != 2 || len(rep.Unclustered) != 1 || rep.Unclustered[0] != "loner" { t.Fatalf("report %+v", rep) }
+	byFirst := map[string]Community{}
+	for _, c := range rep.Communities { byFirst[c.Members[0]] = c }
+	a, b := byFirst["a0"], byFirst["b0"]
+	if len(a.Members) != 4 || len(a.Keywords) != 2 || a.Keywords[0] != "backend" { t.Fatalf("a community %+v", a) }
+	if b.Keywords[0] != "artist" { t.Fatalf("b keywords %v", b.Keywords) }
+	if a.Engagement.Outbound != 4 || a.Engagement.Engaged != 3 || b.Engagement.Outbound != 4 || b.Engagement.Engaged != 3 || b.Engagement.Inbound != 1 || rep.Outbound != 8 {
+		t.Fatalf("engagement a=%+v b=%+v total=%d", a.Engagement, b.Engagement, rep.Outbound)
+	}
+	// a has 4 of 9 followings and 4 of 8 engagements
+	if want := (4.0 / 8) / (4.0 / 9); a.Engagement.Served != want { t.Fatalf("served %v want %v", a.Engagement.Served, want) }
+}
@@ -0,0 +1,57 @@
+package graph
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPersonalizedPageRankTwoCycle(t *testing.T) {
+	g := New()
+	g.AddEdge("a", "b", 1)
+	g.AddEdge("b", "a", 1)
+	r := PersonalizedPageRank(g, map[string]float64{"a": 1}, RankOptions{Damping: 0.85, MaxIter: 500, Tolerance: 1e-12})
+	// r_a = (1-d) + d*r_b, r_b = d*r_a
+	if want := 1 / 1.85; math.Abs(r["a"]-want) > 1e-9 { t.Fatalf("a=%v want %v", r["a"], want) }
+	if math.Abs(r["a"]+r["b"]-1) > 1e-9 { t.Fatalf("sum %v", r["a"]+r["b"]) }
+}
+
+func TestPersonalizedPageRankSyntheticGraph(t *testing.T) {
+	g := New()
+	for _, e := range [][2]string{{"a", "x"}, {"b", "x"}, {"a", "y"}, {"x", "z"}, {"p", "q"}} { g.AddEdge(e[0], e[1], 1) }
+	r := PersonalizedPageRank(g, map[string]float64{"a": 1, "b": 1}, RankOptions{})
+	if !(r["x"] > r["y"] && r["y"] > 0 && r["z"] > 0) { t.Fatalf("want x > y > 0, z > 0: %v", r) }
+	if r["q"] != 0 || r["p"] != 0 { t.Fatalf("disconnected nodes scored: %v", r) }
+	sum := 0.0
+	for _, v := range r { sum += v }
+	if math.Abs(sum-1) > 1e-6 { t.Fatalf("sum %v", sum) }
+	// a heavier edge shifts the walk
+	g.AddEdge("a", "y", 5)
+	if r2 := PersonalizedPageRank(g, map[string]float64{"a": 1, "b": 1}, RankOptions{}); r2["y"] <= r["y"] { t.Fatalf("weight ignored: %v vs %v", r2["y"], r["y"]) }
+	if len(PersonalizedPageRank(g, nil, RankOptions{})) != 0 { t.Fatal("no seeds should give no scores") }
+}
+
+func TestSALSAFavorsSharedFollows(t *testing.T) {
+	g := New()
+	// our three hubs all follow x; one follows niche y and celebrity c, which many outsiders follow
+	for _, h := range []string{"h1", "h2", "h3"} { g.AddEdge(h, "x", 1) }
+	g.AddEdge("h1", "y", 1)
+	g.AddEdge("h1", "c", 1)
+	for _, p := range []string{"p1", "p2", "p3", "p4", "p5", "p6"} { g.AddEdge(p, "c", 1) }
+	a := SALSA(g, map[string]float64{"h1": 1, "h2": 1, "h3": 1}, RankOptions{})
+	if !(a["x"] > a["c"] && a["x"] > a["y"]) { t.Fatalf("x should lead: %v", a) }
+	if a["h1"] != 0 { t.Fatalf("hubs without followers are not authorities: %v", a) }
+}
+
+func TestLoadStoredGraph(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := db.ReplaceFollowing(ctx, "me", []string{"a", "b"}, 0, now); err != nil { t.Fatal(err) }
+	if _, err := db.ReplaceFollowing(ctx, "a", []string{"c"}, 0, now); err != nil { t.Fatal(err) }
+	g, err := Load(ctx, db)
+	if err != nil { t.Fatal(err) }
+	if out := g.Out("me"); len(out) != 2 || out["a"] != 1 { t.Fatalf("me out %v", out) }
+	if n := g.Nodes(); len(n) != 4 { t.Fatalf("nodes %v", n) }
+}
@@ -0,0 +1,77 @@
+package graph
+
+import (
+	"context"
+	"time"
+
+	"starseed/internal/model"
+	"starseed/internal/store/sqlitevec"
+)
+
+// FollowingGetter lists the accounts a user follows (xclient.HTTPClient, Stored).
+type FollowingGetter interface {
+	GetFollowing(ctx context.Context, userID string, limit int) ([]model.User, error)
+}
+
+// Stored serves following lists from the edges table, so graph discovery runs without
+// API calls. Adjacency lists that were never synced come from Fallback (when set and
+// within Budget) and are stored on the way through; otherwise they are empty.
+type Stored struct {
+	DB       *sqlitevec.DB
+	Fallback FollowingGetter
+	// Budget caps Fallback fetches, counting down (nil = no cap); lists beyond it are
+	// left to starseed graph sync
+	Budget *int
+	// PerUser caps each fetched list (default 1000, as Sync) so the stored list is not
+	// cut to a caller's limit
+	PerUser int
+	// Now stamps write-through edges (time.Now when nil)
+	Now func() time.Time
+}
+
+// GetFollowing returns up to limit accounts userID follows.
+func (s Stored) GetFollowing(ctx context.Context, userID string, limit int) ([]model.User, error) {
+	synced, err := s.DB.AdjacencySyncedAt(ctx, []string{userID})
+	if err != nil { return nil, err }
+	if _, ok := synced[userID]; !ok && s.Fallback != nil && (s.Budget == nil || *s.Budget > 0) {
+		if s.Budget != nil { *s.Budget-- }
+		perUser := s.PerUser
+		if perUser <= 0 { perUser = 1000 }
+		users, err := s.Fallback.GetFollowing(ctx, userID, perUser)
+		if err != nil { return nil, err }
+		if _, err := store(ctx, s.DB, userID, users, perUser, s.now()); err != nil { return nil, err }
+		if limit > 0 && len(users) > limit { users = users[:limit] }
+		return users, nil
+	}
+	ids, err := s.DB.Following(ctx, userID)
+	if err != nil { return nil, err }
+	if limit > 0 && len(ids) > limit { ids = ids[:limit] }
+	return s.users(ctx, ids)
+}
+
+// users resolves IDs to stored profiles, keeping order; unknown profiles carry only the ID.
+func (s Stored) users(ctx context.Context, ids []string) ([]model.User, error) {
+	known, err := s.DB.GetUsers(ctx, ids)
+	if err != nil { return nil, err }
+	out := make([]model.User, len(ids))
+	for i, id := range ids {
+		u, ok := known[id]
+		if !ok { u = model.User{ID: id} }
+		out[i] = u
+	}
+	return out, nil
+}
+
+func (s Stored) now() time.Time {
+	if s.Now != nil { return s.Now() }
+	return time.Now().UTC()
+}
+
+// store saves profiles and src's following list fetched with limit, returning the edge
+// changes.
+func store(ctx context.Context, db *sqlitevec.DB, src string, users []model.User, limit int, now time.Time) ([]sqlitevec.EdgeChange, error) {
+	if err := db.PutUsers(ctx, users, now); err != nil { return nil, err }
+	ids := make([]string, len(users))
+	for i, u := range users { ids[i] = u.ID }
+	return db.ReplaceFollowing(ctx, src, ids, limit, now)
+}
@@ -0,0 +1,94 @@
+package graph
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"starseed/internal/store/sqlitevec"
+)
+
+// SyncOptions bound one sync run.
+type SyncOptions struct {
+	// Budget is the number of following lists fetched per run (default 15, the X API's
+	// per-15-minute limit for this endpoint)
+	Budget int
+	// Depth 1 syncs the roots; 2 also syncs the accounts they follow (default 2)
+	Depth int
+	// MaxAge skips lists synced more recently than this (default 24h)
+	MaxAge time.Duration
+	// PerUser caps each following list (default 1000)
+	PerUser int
+}
+
+// SyncReport summarizes a sync run.
+type SyncReport struct {
+	Synced    int
+	Failed    int
+	Pending   int // stale or never-synced lists left for the next run
+	Follows   int
+	Unfollows int
+	Changes   []sqlitevec.EdgeChange
+}
+
+// Sync refreshes stored following lists for roots and, with Depth 2, the accounts they
+// follow. Roots go first, so the accounts they follow are known from the lists fetched in
+// the same run; within each level never-synced lists go first, then the oldest. Lists
+// fresher than MaxAge are skipped, and at most Budget are fetched. Follow and unfollow
+// changes are recorded between complete lists; one that fills PerUser may be truncated
+// and records none.
+func Sync(ctx context.Context, db *sqlitevec.DB, client FollowingGetter, roots []string, opts SyncOptions, now time.Time) (SyncReport, error) {
+	if opts.Budget <= 0 { opts.Budget = 15 }
+	if opts.Depth <= 0 { opts.Depth = 2 }
+	if opts.MaxAge <= 0 { opts.MaxAge = 24 * time.Hour }
+	if opts.PerUser <= 0 { opts.PerUser = 1000 }
+	var rep SyncReport
+	seen := map[string]bool{}
+	// level syncs the due lists among ids, never-synced then oldest, within the budget left
+	level := func(ids []string) error {
+		var cands []string
+		for _, id := range ids {
+			if id == "" || seen[id] { continue }
+			seen[id] = true
+			cands = append(cands, id)
+		}
+		synced, err := db.AdjacencySyncedAt(ctx, cands)
+		if err != nil { return err }
+		var due []string
+		for _, id := range cands {
+			if t, ok := synced[id]; !ok || now.Sub(t) >= opts.MaxAge { due = append(due, id) }
+		}
+		sort.SliceStable(due, func(i, j int) bool { return synced[due[i]].Before(synced[due[j]]) })
+		for i, id := range due {
+			if rep.Synced+rep.Failed >= opts.Budget {
+				rep.Pending += len(due) - i
+				break
+			}
+			users, err := client.GetFollowing(ctx, id, opts.PerUser)
+			if err != nil {
+				rep.Failed++
+				continue
+			}
+			changes, err := store(ctx, db, id, users, opts.PerUser, now)
+			if err != nil { return err }
+			rep.Synced++
+			for _, c := range changes {
+				if c.Kind == sqlitevec.EdgeFollow { rep.Follows++ } else { rep.Unfollows++ }
+			}
+			rep.Changes = append(rep.Changes, changes...)
+		}
+		return nil
+	}
+	if err := level(roots); err != nil { return rep, err }
+	if opts.Depth >= 2 {
+		// Whom the roots follow per the stored graph, including lists just fetched
+		var next []string
+		for _, r := range roots {
+			ids, err := db.Following(ctx, r)
+			if err != nil { return rep, err }
+			next = append(next, ids...)
+		}
+		if err := level(next); err != nil { return rep, err }
+	}
+	return rep, nil
+}
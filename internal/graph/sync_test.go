@@ -0,0 +1,112 @@
+package graph
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"starseed/internal/model"
+	"starseed/internal/store/sqlitevec"
+)
+
+// fakeFollowing serves following lists from a map and counts calls per user.
+type fakeFollowing struct {
+	lists map[string][]string
+	calls map[string]int
+}
+
+func (f *fakeFollowing) GetFollowing(ctx context.Context, userID string, limit int) ([]model.User, error) {
+	if f.calls == nil { f.calls = map[string]int{} }
+	f.calls[userID]++
+	var out []model.User
+	for _, id := range f.lists[userID] {
+		if limit > 0 && len(out) == limit { break }
+		out = append(out, model.User{ID: id, Username: "u" + id})
+	}
+	return out, nil
+}
+
+func openDB(t *testing.T) *sqlitevec.DB {
+	t.Helper()
+	db, err := sqlitevec.Open(":memory:")
+	if err != nil { t.Fatal(err) }
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestSyncBudgetOrderAndChanges(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	f := &fakeFollowing{lists: map[string][]string{"me": {"a", "b", "c"}, "a": {"b"}, "b": {"x"}, "c": {"y"}}}
+	rep, err := Sync(ctx, db, f, []string{"me"}, SyncOptions{Budget: 2}, now)
+	if err != nil { t.Fatal(err) }
+	// first run: the root goes first and whom it follows is known at once; a fits the
+	// budget, b and c wait; first syncs record no changes
+	if rep.Synced != 2 || rep.Pending != 2 || len(rep.Changes) != 0 || f.calls["a"] != 1 { t.Fatalf("run 1 = %+v calls=%v", rep, f.calls) }
+	rep, err = Sync(ctx, db, f, []string{"me"}, SyncOptions{Budget: 2}, now.Add(time.Hour))
+	if err != nil { t.Fatal(err) }
+	// root and a are fresh; b and c fit the budget
+	if rep.Synced != 2 || rep.Pending != 0 || f.calls["c"] != 1 || f.calls["me"] != 1 { t.Fatalf("run 2 = %+v calls=%v", rep, f.calls) }
+	rep, err = Sync(ctx, db, f, []string{"me"}, SyncOptions{Budget: 2}, now.Add(2*time.Hour))
+	if err != nil || rep.Synced != 0 || rep.Pending != 0 { t.Fatalf("run 3 = %+v %v calls=%v", rep, err, f.calls) }
+
+	// a day later "me" unfollows b and follows d
+	f.lists["me"] = []string{"a", "c", "d"}
+	rep, err = Sync(ctx, db, f, []string{"me"}, SyncOptions{Budget: 1}, now.Add(25*time.Hour))
+	if err != nil { t.Fatal(err) }
+	if rep.Follows != 1 || rep.Unfollows != 1 { t.Fatalf("changes = %+v", rep) }
+	changes, err := db.EdgeChanges(ctx, now, 0)
+	if err != nil || len(changes) != 2 { t.Fatalf("stored changes = %+v %v", changes, err) }
+	ids, _ := db.Following(ctx, "me")
+	if len(ids) != 3 || ids[2] != "d" { t.Fatalf("following = %v", ids) }
+}
+
+func TestStoredServesGraphAndFallsBack(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	f := &fakeFollowing{lists: map[string][]string{"me": {"a", "b"}, "a": {"c"}}}
+	if _, err := Sync(ctx, db, f, []string{"me"}, SyncOptions{Depth: 1}, now); err != nil { t.Fatal(err) }
+	s := Stored{DB: db}
+	got, err := s.GetFollowing(ctx, "me", 10)
+	if err != nil || len(got) != 2 || got[0].Username != "ua" { t.Fatalf("stored = %+v %v", got, err) }
+	if got, _ := s.GetFollowing(ctx, "a", 10); len(got) != 0 { t.Fatalf("unsynced without fallback = %+v", got) }
+	s.Fallback = f
+	got, err = s.GetFollowing(ctx, "a", 10)
+	if err != nil || len(got) != 1 || f.calls["a"] != 1 { t.Fatalf("fallback = %+v %v", got, err) }
+	if _, err := s.GetFollowing(ctx, "a", 10); err != nil || f.calls["a"] != 1 { t.Fatalf("write-through not reused: calls=%v", f.calls) }
+}
+
+func TestStoredFallbackBudgetAndFullLists(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	f := &fakeFollowing{lists: map[string][]string{"a": {"c", "d", "e"}, "b": {"c"}}}
+	budget := 1
+	s := Stored{DB: db, Fallback: f, Budget: &budget}
+	got, err := s.GetFollowing(ctx, "a", 1)
+	if err != nil || len(got) != 1 || got[0].ID != "c" { t.Fatalf("fallback = %+v %v", got, err) }
+	// the whole list is stored, not just the caller's limit
+	if ids, _ := db.Following(ctx, "a"); len(ids) != 3 { t.Fatalf("stored = %v", ids) }
+	if got, err := s.GetFollowing(ctx, "b", 10); err != nil || len(got) != 0 || f.calls["b"] != 0 { t.Fatalf("over budget = %+v %v calls=%v", got, err, f.calls) }
+}
+
+func TestSyncTruncatedListsRecordNoChanges(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	f := &fakeFollowing{lists: map[string][]string{"me": {"a", "b", "c"}}}
+	opts := SyncOptions{Depth: 1, PerUser: 2}
+	if _, err := Sync(ctx, db, f, []string{"me"}, opts, now); err != nil { t.Fatal(err) }
+	// the capped list now shows b and c; a may just be past the cap
+	f.lists["me"] = []string{"b", "c", "d"}
+	rep, err := Sync(ctx, db, f, []string{"me"}, opts, now.Add(25*time.Hour))
+	if err != nil || len(rep.Changes) != 0 { t.Fatalf("truncated changes = %+v %v", rep, err) }
+	if ids, _ := db.Following(ctx, "me"); len(ids) != 3 { t.Fatalf("truncated list dropped edges: %v", ids) }
+	// a complete list after a truncated one still records nothing
+	opts.PerUser = 10
+	if rep, err = Sync(ctx, db, f, []string{"me"}, opts, now.Add(50*time.Hour)); err != nil || len(rep.Changes) != 0 { t.Fatalf("after truncated = %+v %v", rep, err) }
+	// two complete lists do
+	f.lists["me"] = []string{"c", "d"}
+	if rep, err = Sync(ctx, db, f, []string{"me"}, opts, now.Add(75*time.Hour)); err != nil || rep.Unfollows != 1 || rep.Follows != 0 { t.Fatalf("complete = %+v %v", rep, err) }
+}
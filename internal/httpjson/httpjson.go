@@ -0,0 +1,88 @@
+// Package httpjson posts JSON requests to model endpoints (LLM and embedding APIs),
+// retrying transient failures.
+package httpjson
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RetryBackoff is the first wait between attempts; it doubles each retry.
+var RetryBackoff = 500 * time.Millisecond
+
+// Options configure Post.
+type Options struct {
+	// Name prefixes errors, e.g. "llm" or "embeddings" (default "request")
+	Name string
+	// Per-attempt timeout (0: none beyond ctx)
+	Timeout time.Duration
+	// Extra attempts after network errors, 429 and 5xx
+	Retries int
+	// Response size limit in bytes (default 4 MiB)
+	MaxBytes int64
+	// Client sends the requests (default http.DefaultClient)
+	Client *http.Client
+}
+
+// Post sends body as JSON to url and decodes the response into out, retrying network
+// errors, 429 and 5xx up to opts.Retries times. Error statuses report a truncated body.
+func Post(ctx context.Context, opts Options, url string, headers map[string]string, body, out any) error {
+	if opts.Name == "" { opts.Name = "request" }
+	if opts.MaxBytes <= 0 { opts.MaxBytes = 4 << 20 }
+	if opts.Client == nil { opts.Client = http.DefaultClient }
+	if opts.Retries < 0 { opts.Retries = 0 }
+	payload, err := json.Marshal(body)
+	if err != nil { return err }
+	backoff := RetryBackoff
+	var lastErr error
+	for attempt := 0; attempt <= opts.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			backoff *= 2
+		}
+		retry, err := postOnce(ctx, opts, url, headers, payload, out)
+		if err == nil { return nil }
+		lastErr = err
+		if !retry { return err }
+	}
+	return fmt.Errorf("%s request failed after %d attempts: %w", opts.Name, opts.Retries+1, lastErr)
+}
+
+func postOnce(ctx context.Context, opts Options, url string, headers map[string]string, payload []byte, out any) (bool, error) {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payload))
+	if err != nil { return false, err }
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers { req.Header.Set(k, v) }
+	resp, err := opts.Client.Do(req)
+	if err != nil { return ctx.Err() == nil || ctx.Err() == context.DeadlineExceeded, err }
+	defer resp.Body.Close()
+	b, err := io.ReadAll(io.LimitReader(resp.Body, opts.MaxBytes))
+	if err != nil { return true, err }
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return true, fmt.Errorf("%s status %d: %s", opts.Name, resp.StatusCode, snippet(b))
+	}
+	if resp.StatusCode >= 400 { return false, fmt.Errorf("%s status %d: %s", opts.Name, resp.StatusCode, snippet(b)) }
+	if err := json.Unmarshal(bytes.TrimSpace(b), out); err != nil { return false, fmt.Errorf("%s response: %w", opts.Name, err) }
+	return false, nil
+}
+
+func snippet(b []byte) string {
+	s := strings.TrimSpace(string(b))
+	if len(s) > 200 { s = s[:200] + "…" }
+	return s
+}
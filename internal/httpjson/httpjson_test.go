@@ -0,0 +1,40 @@
+package httpjson
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPostRetriesTransientErrorsOnly(t *testing.T) {
+	prev := RetryBackoff
+	RetryBackoff = time.Millisecond
+	t.Cleanup(func() { RetryBackoff = prev })
+	var calls int32
+	status := 503
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" || r.Header.Get("X-Key") != "k" { t.Errorf("headers %v", r.Header) }
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(strings.Repeat("x", 500)))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+	var out struct{ OK bool }
+	opts := Options{Name: "embeddings", Retries: 2}
+	if err := Post(context.Background(), opts, srv.URL, map[string]string{"X-Key": "k"}, map[string]any{"a": 1}, &out); err != nil || !out.OK || calls != 3 {
+		t.Fatalf("expected success after retries: %+v %v calls=%d", out, err, calls)
+	}
+
+	atomic.StoreInt32(&calls, 0)
+	status = 400
+	err := Post(context.Background(), opts, srv.URL, map[string]string{"X-Key": "k"}, nil, &out)
+	if err == nil || calls != 1 { t.Fatalf("4xx should not be retried: %v calls=%d", err, calls) }
+	if !strings.HasPrefix(err.Error(), "embeddings status 400: ") || len(err.Error()) > 250 { t.Fatalf("error not prefixed and truncated: %q", err) }
+}
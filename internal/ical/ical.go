@@ -0,0 +1,89 @@
+package ical
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Event is a single VEVENT. UID must stay the same across exports so calendar
+// clients update the entry instead of adding a duplicate.
+type Event struct {
+	UID         string
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	URL         string
+}
+
+// UID derives a stable identifier from the parts that identify an event.
+func UID(parts ...string) string {
+	h := sha1.Sum([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(h[:10]) + "@starseed"
+}
+
+// Write renders events as an RFC 5545 calendar named name.
+func Write(w io.Writer, name string, events []Event) error {
+	bw := bufio.NewWriter(w)
+	stamp := time.Now().UTC().Format(stampLayout)
+	line := func(s string) { writeFolded(bw, s) }
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//starseed//engagement plan//EN")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	if name != "" { line("X-WR-CALNAME:" + escape(name)) }
+	for _, e := range events {
+		end := e.End
+		if !end.After(e.Start) { end = e.Start.Add(15 * time.Minute) }
+		line("BEGIN:VEVENT")
+		line("UID:" + e.UID)
+		line("DTSTAMP:" + stamp)
+		line("DTSTART:" + e.Start.UTC().Format(stampLayout))
+		line("DTEND:" + end.UTC().Format(stampLayout))
+		line("SUMMARY:" + escape(e.Summary))
+		if e.Description != "" { line("DESCRIPTION:" + escape(e.Description)) }
+		if e.URL != "" { line("URL:" + e.URL) }
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return bw.Flush()
+}
+
+// Handler serves the calendar returned by feed, which it calls on every request; any
+// caching of the events is left to feed.
+func Handler(name string, feed func(r *http.Request) ([]Event, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		events, err := feed(r)
+		if err != nil { http.Error(w, err.Error(), http.StatusInternalServerError); return }
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		_ = Write(w, name, events)
+	})
+}
+
+const stampLayout = "20060102T150405Z"
+
+func escape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+	return r.Replace(s)
+}
+
+// writeFolded ends s with CRLF, folding it at 75 octets without splitting UTF-8 sequences.
+func writeFolded(w *bufio.Writer, s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8Start(s[cut]) { cut-- }
+		_, _ = w.WriteString(s[:cut] + "\r\n ")
+		s = s[cut:]
+		limit = 74 // continuation lines start with a space
+	}
+	_, _ = w.WriteString(s + "\r\n")
+}
+
+func utf8Start(b byte) bool { return b&0xC0 != 0x80 }
@@ -0,0 +1,37 @@
+package ical
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestWriteEscapesAndFolds(t *testing.T) {
+	start := time.Date(2024, 5, 6, 14, 0, 0, 0, time.UTC)
+	desc := "Draft: great point; thanks, really\nhttps://x.com/i/web/status/1 " + strings.Repeat("é", 60)
+	var buf bytes.Buffer
+	if err := Write(&buf, "plan", []Event{{UID: UID("reply", "1"), Start: start, Summary: "Reply", Description: desc}}); err != nil { t.Fatal(err) }
+	out := buf.String()
+	for _, want := range []string{"DTSTART:20240506T140000Z", "DTEND:20240506T141500Z", `great point\; thanks\, really\nhttps`} {
+		if !strings.Contains(strings.ReplaceAll(out, "\r\n ", ""), want) { t.Fatalf("missing %q in\n%s", want, out) }
+	}
+	for _, l := range strings.Split(out, "\r\n") {
+		if len(l) > 75 { t.Fatalf("line not folded (%d octets): %q", len(l), l) }
+		if !utf8.ValidString(l) { t.Fatalf("fold split a UTF-8 sequence: %q", l) }
+	}
+	if UID("reply", "1") != UID("reply", "1") || UID("reply", "1") == UID("reply", "2") { t.Fatalf("UIDs must be stable and distinct") }
+}
+
+func TestHandlerServesCalendar(t *testing.T) {
+	h := Handler("plan", func(*http.Request) ([]Event, error) {
+		return []Event{{UID: "a@starseed", Start: time.Now(), Summary: "Window"}}, nil
+	})
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/calendar.ics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/calendar") { t.Fatalf("content type %q", ct) }
+	if !strings.Contains(rec.Body.String(), "UID:a@starseed") { t.Fatalf("missing event: %s", rec.Body.String()) }
+}
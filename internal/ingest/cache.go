@@ -0,0 +1,35 @@
+package ingest
+
+import (
+	"context"
+	"time"
+
+	"starseed/internal/model"
+	"starseed/internal/store/sqlitevec"
+)
+
+// TimelineSource fetches a user's recent tweets including replies (xclient.HTTPClient).
+type TimelineSource interface {
+	GetUserTimeline(ctx context.Context, userID string, limit int) ([]model.Tweet, error)
+}
+
+// CachedTimeline returns up to limit recent tweets by userID from the tweets table,
+// fetching and caching them first when the cache is older than maxAge. A failed fetch
+// falls back to whatever is cached.
+func CachedTimeline(ctx context.Context, db *sqlitevec.DB, src TimelineSource, userID string, limit int, maxAge time.Duration, now time.Time) ([]model.Tweet, error) {
+	fetched, err := db.TweetsFetchedAt(ctx, userID)
+	if err != nil { return nil, err }
+	if src != nil && (fetched.IsZero() || now.Sub(fetched) > maxAge) {
+		if ts, err := src.GetUserTimeline(ctx, userID, limit); err == nil {
+			if err := SaveTimeline(ctx, db, userID, ts, now); err != nil { return nil, err }
+		}
+	}
+	return db.TweetsByAuthor(ctx, userID, limit)
+}
+
+// SaveTimeline caches a fetched timeline and marks userID as fetched at now, so an
+// empty timeline is not refetched until it goes stale.
+func SaveTimeline(ctx context.Context, db *sqlitevec.DB, userID string, tweets []model.Tweet, now time.Time) error {
+	if err := db.PutTweets(ctx, tweets, now); err != nil { return err }
+	return db.MarkTimelineFetched(ctx, userID, now)
+}
@@ -0,0 +1,55 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"starseed/internal/model"
+	"starseed/internal/store/sqlitevec"
+)
+
+type fakeTimeline struct {
+	calls  int
+	tweets []model.Tweet
+	err    error
+}
+
+func (f *fakeTimeline) GetUserTimeline(ctx context.Context, userID string, limit int) ([]model.Tweet, error) {
+	f.calls++
+	return f.tweets, f.err
+}
+
+func TestCachedTimelineRefreshesWhenStale(t *testing.T) {
+	db, err := sqlitevec.Open(":memory:")
+	if err != nil { t.Fatal(err) }
+	defer db.Close()
+	ctx := context.Background()
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	src := &fakeTimeline{tweets: []model.Tweet{
+		{ID: "1", AuthorID: "u", Text: "older", CreatedAt: now.Add(-2 * time.Hour)},
+		{ID: "2", AuthorID: "u", Text: "reply https://x.co", CreatedAt: now.Add(-time.Hour), ReplyToID: "9", ConversationID: "9", HasLink: true, LikeCount: 3},
+	}}
+	got, err := CachedTimeline(ctx, db, src, "u", 10, 24*time.Hour, now)
+	if err != nil { t.Fatal(err) }
+	if len(got) != 2 || got[0].ID != "2" || got[0].ReplyToID != "9" || !got[0].HasLink || got[0].LikeCount != 3 { t.Fatalf("got %+v", got) }
+	if _, err := CachedTimeline(ctx, db, src, "u", 10, 24*time.Hour, now.Add(time.Hour)); err != nil || src.calls != 1 { t.Fatalf("fresh cache refetched: calls=%d err=%v", src.calls, err) }
+	src.err = errors.New("rate limited")
+	got, err = CachedTimeline(ctx, db, src, "u", 1, 24*time.Hour, now.Add(25*time.Hour))
+	if err != nil || src.calls != 2 || len(got) != 1 { t.Fatalf("stale fetch failure should fall back to cache: %v %d %+v", err, src.calls, got) }
+}
+
+func TestCachedTimelineRemembersEmptyFetches(t *testing.T) {
+	db, err := sqlitevec.Open(":memory:")
+	if err != nil { t.Fatal(err) }
+	defer db.Close()
+	ctx := context.Background()
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	src := &fakeTimeline{}
+	for i := 0; i < 2; i++ {
+		if got, err := CachedTimeline(ctx, db, src, "quiet", 10, 24*time.Hour, now.Add(time.Duration(i)*time.Hour)); err != nil || len(got) != 0 { t.Fatalf("got %v %v", got, err) }
+	}
+	if src.calls != 1 { t.Fatalf("empty timeline refetched: calls=%d", src.calls) }
+	if at, err := db.TweetsFetchedAt(ctx, "quiet"); err != nil || !at.Equal(now) { t.Fatalf("fetched at %v %v", at, err) }
+}
@@ -67,7 +67,7 @@ func IngestEngagements(ctx context.Context, db *sqlitevec.DB, client xclient.XCl
         if outs, err := client.SearchRecentTweetsSince(ctx, q, 100, orSince); err == nil {
             for _, t := range outs {
                 if t.CreatedAt.Before(orSince) { continue }
-                _ = db.PutEventRef(ctx, t.CreatedAt, "out_reply", t.ID, map[string]any{"tweet_id": t.ID, "author_id": t.AuthorID})
+                _ = db.PutEventRef(ctx, t.CreatedAt, "out_reply", t.ID, map[string]any{"tweet_id": t.ID, "author_id": t.AuthorID, "text": t.Text, "conversation_id": t.ConversationID, "reply_to_id": t.ReplyToID})
             }
         }
         _ = db.SaveCursor(ctx, "ingest:out_replies_since", now.Format(time.RFC3339Nano))
@@ -14,6 +14,7 @@ type fakeLikeClient struct{}
 func (fakeLikeClient) GetUserByUsername(ctx context.Context, username string) (model.User, error) { return model.User{}, nil }
 func (fakeLikeClient) GetHomeTimeline(ctx context.Context, userID string, limit int) ([]model.Tweet, error) { return nil, nil }
 func (fakeLikeClient) GetFollowing(ctx context.Context, userID string, limit int) ([]model.User, error) { return nil, nil }
+func (fakeLikeClient) GetFollowers(ctx context.Context, userID string, limit int) ([]model.User, error) { return nil, nil }
 func (fakeLikeClient) SearchRecentTweets(ctx context.Context, query string, limit int) ([]model.Tweet, error) { return nil, nil }
 func (fakeLikeClient) SearchRecentTweetsSince(ctx context.Context, query string, limit int, start time.Time) ([]model.Tweet, error) { return nil, nil }
 func (fakeLikeClient) GetUserTweets(ctx context.Context, userID string, limit int) ([]model.Tweet, error) { return nil, nil }
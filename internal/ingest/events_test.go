@@ -14,6 +14,7 @@ type fakeXIngest struct{}
 func (f fakeXIngest) GetUserByUsername(ctx context.Context, username string) (model.User, error) { return model.User{}, nil }
 func (f fakeXIngest) GetHomeTimeline(ctx context.Context, userID string, limit int) ([]model.Tweet, error) { return nil, nil }
 func (f fakeXIngest) GetFollowing(ctx context.Context, userID string, limit int) ([]model.User, error) { return nil, nil }
+func (f fakeXIngest) GetFollowers(ctx context.Context, userID string, limit int) ([]model.User, error) { return nil, nil }
 func (f fakeXIngest) SearchRecentTweets(ctx context.Context, query string, limit int) ([]model.Tweet, error) { return nil, nil }
 func (f fakeXIngest) SearchRecentTweetsSince(ctx context.Context, query string, limit int, start time.Time) ([]model.Tweet, error) {
 	return []model.Tweet{{ID: "r1", AuthorID: "a2", CreatedAt: time.Now().UTC()}}, nil
@@ -0,0 +1,90 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"starseed/internal/logging"
+	"starseed/internal/metrics"
+	"starseed/internal/nn"
+	"starseed/internal/store/sqlitevec"
+)
+
+const retrainCursorKey = "nn:retrain_needed"
+
+// ErrNoTrainingStats is returned by RunDriftCheck before the model was trained from the DB.
+var ErrNoTrainingStats = errors.New("no training stats; run nn-train-db first")
+
+// RetrainFlag returns when a drift check last flagged retraining (false when not flagged
+// since the last ClearRetrainFlag).
+func RetrainFlag(ctx context.Context, db *sqlitevec.DB) (time.Time, bool) {
+	v, err := db.LoadCursor(ctx, retrainCursorKey)
+	if err != nil { return time.Time{}, false }
+	at, err := time.Parse(time.RFC3339Nano, v)
+	return at, err == nil
+}
+
+// ClearRetrainFlag resets the flag once a model has been retrained.
+func ClearRetrainFlag(ctx context.Context, db *sqlitevec.DB) error { return db.DeleteCursor(ctx, retrainCursorKey) }
+
+// DriftOptions configures a drift check against a trained model.
+type DriftOptions struct {
+	ModelPath  string
+	BinPath    string // optional; enables prediction drift
+	Window     time.Duration
+	Thresholds nn.DriftThresholds
+}
+
+// RunDriftCheck compares recent feature windows with the model's training stats,
+// exports gauges, and flags retraining (RetrainFlag) when thresholds are exceeded.
+func RunDriftCheck(ctx context.Context, db *sqlitevec.DB, opts DriftOptions) (nn.DriftReport, error) {
+	var rep nn.DriftReport
+	st, err := nn.LoadTrainingStats(ctx, db, opts.ModelPath)
+	if errors.Is(err, sql.ErrNoRows) { return rep, ErrNoTrainingStats }
+	if err != nil { return rep, err }
+	now := time.Now().UTC()
+	_, X, _, err := db.LoadFeatures(ctx, now.Add(-opts.Window), now)
+	if err != nil { return rep, err }
+	var preds []float32
+	if opts.BinPath != "" && len(X) > 0 {
+		samples := make([]nn.FeatureVector, 0, len(X))
+		for _, x := range X { samples = append(samples, nn.FeatureVector{X: x}) }
+		if out, err := nn.Infer(opts.BinPath, opts.ModelPath, samples); err == nil {
+			for _, p := range out {
+				if len(p) > 0 { preds = append(preds, p[0]) }
+			}
+		}
+	}
+	rep = nn.ComputeDrift(st, X, preds, opts.Thresholds)
+	for _, f := range rep.Features {
+		metrics.SetFeatureDrift(f.Name, f.PSI, f.MeanShift)
+	}
+	if rep.Prediction != nil {
+		metrics.SetPredictionDrift(rep.Prediction.PSI)
+	}
+	metrics.SetRetrainNeeded(rep.RetrainNeeded)
+	if rep.RetrainNeeded {
+		_ = db.SaveCursor(ctx, retrainCursorKey, now.Format(time.RFC3339Nano))
+		logging.Info("drift_retrain_needed", map[string]any{"model": opts.ModelPath, "drifted": rep.Drifted, "samples": rep.Samples})
+	}
+	return rep, nil
+}
+
+// RunDriftLoop runs RunDriftCheck on a ticker until ctx is cancelled.
+func RunDriftLoop(ctx context.Context, db *sqlitevec.DB, opts DriftOptions, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		// No training stats yet (model never trained from the DB): nothing to check
+		if _, err := RunDriftCheck(ctx, db, opts); err != nil && !errors.Is(err, ErrNoTrainingStats) {
+			logging.Error("drift_check_error", map[string]any{"error": err.Error()})
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"starseed/internal/store/sqlitevec"
+)
+
+func TestDriftCheckWithoutStatsAndRetrainFlag(t *testing.T) {
+	db, err := sqlitevec.Open(":memory:")
+	if err != nil { t.Fatal(err) }
+	defer db.Close()
+	ctx := context.Background()
+	if _, err := RunDriftCheck(ctx, db, DriftOptions{ModelPath: "m.json", Window: time.Hour}); !errors.Is(err, ErrNoTrainingStats) { t.Fatalf("want ErrNoTrainingStats, got %v", err) }
+	if _, ok := RetrainFlag(ctx, db); ok { t.Fatal("flag set on a fresh db") }
+	at := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	if err := db.SaveCursor(ctx, retrainCursorKey, at.Format(time.RFC3339Nano)); err != nil { t.Fatal(err) }
+	if got, ok := RetrainFlag(ctx, db); !ok || !got.Equal(at) { t.Fatalf("flag = %v %v", got, ok) }
+	if err := ClearRetrainFlag(ctx, db); err != nil { t.Fatal(err) }
+	if _, ok := RetrainFlag(ctx, db); ok { t.Fatal("flag not cleared") }
+}
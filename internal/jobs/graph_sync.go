@@ -0,0 +1,26 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"starseed/internal/config"
+	"starseed/internal/graph"
+	"starseed/internal/logging"
+	"starseed/internal/model"
+	"starseed/internal/store/sqlitevec"
+	"starseed/internal/xclient"
+)
+
+// RunGraphSync refreshes the stored follow graph around our account within opts.Budget
+// API calls.
+func RunGraphSync(ctx context.Context, db *sqlitevec.DB, client xclient.XClient, cfg config.Config, opts graph.SyncOptions) (graph.SyncReport, error) {
+	me, err := client.GetUserByUsername(ctx, cfg.Account.Username)
+	if err != nil { return graph.SyncReport{}, err }
+	now := time.Now().UTC()
+	if err := db.PutUsers(ctx, []model.User{me}, now); err != nil { return graph.SyncReport{}, err }
+	rep, err := graph.Sync(ctx, db, client, []string{me.ID}, opts, now)
+	if err != nil { return rep, err }
+	logging.Info("graph_sync", map[string]any{"synced": rep.Synced, "failed": rep.Failed, "pending": rep.Pending, "follows": rep.Follows, "unfollows": rep.Unfollows})
+	return rep, nil
+}
@@ -19,6 +19,7 @@ func (fx) GetUserByUsername(ctx context.Context, username string) (model.User, e
 }
 func (fx) GetHomeTimeline(ctx context.Context, userID string, limit int) ([]model.Tweet, error) { return nil, nil }
 func (fx) GetFollowing(ctx context.Context, userID string, limit int) ([]model.User, error) { return nil, nil }
+func (fx) GetFollowers(ctx context.Context, userID string, limit int) ([]model.User, error) { return nil, nil }
 func (fx) SearchRecentTweets(ctx context.Context, query string, limit int) ([]model.Tweet, error) { return nil, nil }
 func (fx) SearchRecentTweetsSince(ctx context.Context, query string, limit int, start time.Time) ([]model.Tweet, error) {
     // Return a tweet 20 minutes after the ws used in the test to fall into next window
@@ -35,10 +35,31 @@ var (
         Name: "starseed_command_errors_total",
         Help: "Total command errors",
     }, []string{"command"})
+    FeatureDriftPSI = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+        Name: "starseed_feature_drift_psi",
+        Help: "Population stability index of live features vs training",
+    }, []string{"feature"})
+    FeatureDriftMeanShift = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+        Name: "starseed_feature_drift_mean_shift",
+        Help: "Absolute mean shift of live features in training std units",
+    }, []string{"feature"})
+    PredictionDriftPSI = prometheus.NewGauge(prometheus.GaugeOpts{
+        Name: "starseed_prediction_drift_psi",
+        Help: "Population stability index of live predictions vs training",
+    })
+    RetrainNeeded = prometheus.NewGauge(prometheus.GaugeOpts{
+        Name: "starseed_retrain_needed",
+        Help: "1 when drift thresholds are exceeded and the model should be retrained",
+    })
+    LLMTokens = prometheus.NewCounterVec(prometheus.CounterOpts{
+        Name: "starseed_llm_tokens_total",
+        Help: "LLM tokens consumed by provider and direction (input/output)",
+    }, []string{"provider", "kind"})
 )
 
 func init() {
-    prometheus.MustRegister(IngestRuns, IngestErrors, IngestDuration, APIRetries, CommandRuns, CommandErrors)
+    prometheus.MustRegister(IngestRuns, IngestErrors, IngestDuration, APIRetries, CommandRuns, CommandErrors,
+        FeatureDriftPSI, FeatureDriftMeanShift, PredictionDriftPSI, RetrainNeeded, LLMTokens)
 }
 
 // StartServer starts a metrics HTTP server on addr (e.g., ":9090").
@@ -65,3 +86,21 @@ func IncAPIRetry(endpoint string) { APIRetries.WithLabelValues(endpoint).Inc() }
 
 func IncCommandRun(cmd string)   { CommandRuns.WithLabelValues(cmd).Inc() }
 func IncCommandError(cmd string) { CommandErrors.WithLabelValues(cmd).Inc() }
+
+// SetFeatureDrift exports drift scores for one feature.
+func SetFeatureDrift(feature string, psi, meanShift float64) {
+    FeatureDriftPSI.WithLabelValues(feature).Set(psi)
+    FeatureDriftMeanShift.WithLabelValues(feature).Set(meanShift)
+}
+
+func SetPredictionDrift(psi float64) { PredictionDriftPSI.Set(psi) }
+
+func SetRetrainNeeded(needed bool) {
+    if needed { RetrainNeeded.Set(1) } else { RetrainNeeded.Set(0) }
+}
+
+// AddLLMTokens records token usage reported by an LLM provider.
+func AddLLMTokens(provider string, input, output int) {
+    LLMTokens.WithLabelValues(provider, "input").Add(float64(input))
+    LLMTokens.WithLabelValues(provider, "output").Add(float64(output))
+}
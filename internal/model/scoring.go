@@ -1,12 +1,16 @@
 package model
 
 import (
+	"context"
 	"math"
 	"strings"
 
 	"starseed/internal/util"
 )
 
+// SpamTerms mark promotional or spammy tweet text.
+var SpamTerms = []string{"giveaway", "win big", "click here", "promo", "ref code"}
+
 // OrganicContentScore estimates how organic a tweet appears.
 // Heuristics: no excessive links, balanced engagement, non-spammy tokens.
 func OrganicContentScore(t Tweet) float64 {
@@ -25,8 +29,7 @@ func OrganicContentScore(t Tweet) float64 {
 		}
 	}
 	// Penalize spammy tokens
-	spammy := []string{"giveaway", "win big", "click here", "promo", "ref code"}
-	if util.ContainsAnyCaseInsensitive(t.Text, spammy) {
+	if util.ContainsAnyCaseInsensitive(t.Text, SpamTerms) {
 		score -= 0.25
 	}
 	if score < 0 {
@@ -38,7 +41,19 @@ func OrganicContentScore(t Tweet) float64 {
 	return math.Round(score*100) / 100
 }
 
-// BotLikelihood estimates if a user is a bot [0,1]. Lower is better.
+// BotScorer scores how likely an account is automated in [0,1], e.g. a trained
+// bots.Model's BotProbability. The nil scorer is the BotLikelihood heuristic.
+type BotScorer func(u User) float64
+
+// Score is s(u), or BotLikelihood(u) when s is nil.
+func (s BotScorer) Score(u User) float64 {
+	if s == nil { return BotLikelihood(u) }
+	return s(u)
+}
+
+// BotLikelihood estimates if a user is a bot [0,1] from profile checks. Lower is better.
+// It stays the heuristic whatever bot model is configured, since the NN features are
+// trained on it.
 func BotLikelihood(u User) float64 {
 	score := 0.2
 	if u.DefaultImage || u.DefaultProfile {
@@ -56,8 +71,41 @@ func BotLikelihood(u User) float64 {
 	return math.Round(score*100) / 100
 }
 
-// InterestRelevance scores how relevant text is to our interests.
+// Relevance scores text against our interests in [0,1] (embed.Relevance).
+type Relevance interface {
+	Relevance(text string) float64
+}
+
+var semantic Relevance
+
+// SetRelevance makes InterestRelevance score by r as well as by exact keyword hits
+// (nil restores keyword matching only). Set it once, before scoring starts.
+func SetRelevance(r Relevance) { semantic = r }
+
+// WarmRelevance embeds texts in one pass ahead of scoring them when the Relevance set by
+// SetRelevance supports it (embed.Relevance); otherwise it does nothing.
+func WarmRelevance(ctx context.Context, texts []string) error {
+	w, ok := semantic.(interface{ Warm(context.Context, []string) error })
+	if !ok || len(texts) == 0 { return nil }
+	return w.Warm(ctx, texts)
+}
+
+// SemanticRelevance scores text with the Relevance set by SetRelevance (false when none is set).
+func SemanticRelevance(text string) (float64, bool) {
+	if semantic == nil { return 0, false }
+	return semantic.Relevance(text), true
+}
+
+// InterestRelevance scores how relevant text is to our interests: the higher of the
+// semantic score (see SetRelevance) and KeywordRelevance.
 func InterestRelevance(text string, keywords []string, weights map[string]float64) float64 {
+	kw := KeywordRelevance(text, keywords, weights)
+	if s, ok := SemanticRelevance(text); ok && s > kw { return s }
+	return kw
+}
+
+// KeywordRelevance scores text by weighted exact keyword hits per token.
+func KeywordRelevance(text string, keywords []string, weights map[string]float64) float64 {
 	tokens := util.Tokenize(text)
 	if len(tokens) == 0 || len(keywords) == 0 {
 		return 0
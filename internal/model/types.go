@@ -32,6 +32,9 @@ type Tweet struct {
 	QuoteCount int
 	Language  string
 	HasLink   bool
+	// Conversation root and, for replies, the tweet replied to
+	ConversationID string
+	ReplyToID      string
 }
 
 // EngagementEvent captures an engagement we did or received.
@@ -25,13 +25,13 @@ func Train(binaryPath, outPath string, samples []FeatureVector, hidden, epochs i
 }
 
 type TrainOptions struct {
-    Hidden     int
-    Epochs     int
-    LR         float32
-    ValSplit   float32
-    Patience   int
-    Calibrate  bool
-    Checkpoint string
+    Hidden     int     `json:"hidden"`
+    Epochs     int     `json:"epochs"`
+    LR         float32 `json:"lr"`
+    ValSplit   float32 `json:"val_split"`
+    Patience   int     `json:"patience"`
+    Calibrate  bool    `json:"calibrate"`
+    Checkpoint string  `json:"checkpoint,omitempty"`
 }
 
 // TrainWithOptions calls the Rust trainer with advanced options.
@@ -63,8 +63,17 @@ func LoadThresholdFromModel(modelPath string) float32 {
     return tmp.Threshold
 }
 
-// Infer calls the Rust binary to get predictions for samples.
+// Infer returns predictions for samples. It uses a warm worker pool running the binary
+// in serve mode and falls back to a one-shot process if the pool is unavailable.
 func Infer(binaryPath, modelPath string, samples []FeatureVector) ([][]float32, error) {
+	if p := sharedPool(binaryPath, modelPath); p != nil {
+		if preds, err := p.Infer(samples); err == nil { return preds, nil }
+	}
+	return inferOnce(binaryPath, modelPath, samples)
+}
+
+// inferOnce spawns the Rust binary to get predictions for samples.
+func inferOnce(binaryPath, modelPath string, samples []FeatureVector) ([][]float32, error) {
 	var buf bytes.Buffer
 	w := bufio.NewWriter(&buf)
 	enc := json.NewEncoder(w)
@@ -0,0 +1,222 @@
+package nn
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"starseed/internal/store/sqlitevec"
+)
+
+// FeatureStats summarizes the training distribution of a single feature.
+type FeatureStats struct {
+	Mean  float64   `json:"mean"`
+	Std   float64   `json:"std"`
+	Edges []float64 `json:"edges"` // interior bin edges (quantiles of the training data)
+	Props []float64 `json:"props"` // share of training samples per bin, len(Edges)+1
+}
+
+// TrainingStats is the training-time reference stored with each model.
+type TrainingStats struct {
+	Model      string         `json:"model"`
+	CreatedAt  time.Time      `json:"created_at"`
+	Samples    int            `json:"samples"`
+	Features   []FeatureStats `json:"features"`
+	Prediction *FeatureStats  `json:"prediction,omitempty"`
+}
+
+// DriftThresholds controls when drift is flagged.
+type DriftThresholds struct {
+	PSI        float64 // population stability index above which a feature is drifted
+	MeanShift  float64 // |mean shift| in training standard deviations
+	MinDrifted int     // drifted features needed to flag retraining
+}
+
+// DefaultDriftThresholds uses the common PSI rule of thumb (>0.25 is a major shift).
+func DefaultDriftThresholds() DriftThresholds {
+	return DriftThresholds{PSI: 0.25, MeanShift: 2.0, MinDrifted: 3}
+}
+
+// FeatureDrift is the drift of one feature (or the prediction) against training.
+type FeatureDrift struct {
+	Index     int     `json:"index"`
+	Name      string  `json:"name"`
+	PSI       float64 `json:"psi"`
+	MeanShift float64 `json:"mean_shift"`
+	Drifted   bool    `json:"drifted"`
+}
+
+// DriftReport aggregates per-feature and prediction drift over recent windows.
+type DriftReport struct {
+	Samples       int            `json:"samples"`
+	Features      []FeatureDrift `json:"features"`
+	Prediction    *FeatureDrift  `json:"prediction,omitempty"`
+	Drifted       int            `json:"drifted"`
+	RetrainNeeded bool           `json:"retrain_needed"`
+}
+
+const driftBins = 10
+
+// FeatureNames labels the slots produced by BuildFeatures.
+func FeatureNames() []string {
+	names := []string{"log_count", "log_likes", "log_replies", "log_retweets", "log_quotes", "avg_likes", "avg_replies", "avg_retweets"}
+	for i := 0; i < 4; i++ {
+		names = append(names, "roll_count_"+strconv.Itoa(i), "roll_avg_likes_"+strconv.Itoa(i))
+	}
+	return append(names, "how_sin", "how_cos", "rel_mean", "rel_var", "bot_low", "bot_mid", "bot_high")
+}
+
+func featureName(i int) string {
+	names := FeatureNames()
+	if i < len(names) {
+		return names[i]
+	}
+	return "f" + strconv.Itoa(i)
+}
+
+// ComputeTrainingStats builds per-feature reference statistics from training vectors.
+func ComputeTrainingStats(modelPath string, X [][]float32, preds []float32) TrainingStats {
+	st := TrainingStats{Model: modelPath, CreatedAt: time.Now().UTC(), Samples: len(X)}
+	dim := 0
+	for _, v := range X {
+		if len(v) > dim {
+			dim = len(v)
+		}
+	}
+	for j := 0; j < dim; j++ {
+		st.Features = append(st.Features, ComputeFeatureStats(column(X, j), driftBins))
+	}
+	if len(preds) > 0 {
+		ps := ComputeFeatureStats(toF64(preds), driftBins)
+		st.Prediction = &ps
+	}
+	return st
+}
+
+// ComputeFeatureStats computes mean, std and quantile bins for values.
+func ComputeFeatureStats(values []float64, bins int) FeatureStats {
+	var fs FeatureStats
+	if len(values) == 0 {
+		return fs
+	}
+	fs.Mean, fs.Std = meanStd(values)
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	for b := 1; b < bins; b++ {
+		e := sorted[b*len(sorted)/bins]
+		if len(fs.Edges) == 0 || e > fs.Edges[len(fs.Edges)-1] {
+			fs.Edges = append(fs.Edges, e)
+		}
+	}
+	fs.Props = binProps(fs.Edges, values)
+	return fs
+}
+
+// PSI returns the population stability index of values against the reference bins.
+func PSI(ref FeatureStats, values []float64) float64 {
+	if len(values) == 0 || len(ref.Props) == 0 {
+		return 0
+	}
+	actual := binProps(ref.Edges, values)
+	const eps = 1e-4
+	psi := 0.0
+	for i := range ref.Props {
+		e := math.Max(ref.Props[i], eps)
+		a := math.Max(actual[i], eps)
+		psi += (a - e) * math.Log(a/e)
+	}
+	return psi
+}
+
+// MeanShift returns |mean(values) - ref.Mean| in units of the training std.
+func MeanShift(ref FeatureStats, values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	m, _ := meanStd(values)
+	return math.Abs(m-ref.Mean) / math.Max(ref.Std, 1e-6)
+}
+
+// ComputeDrift compares recent feature vectors (and optional predictions) to training stats.
+func ComputeDrift(st TrainingStats, X [][]float32, preds []float32, thr DriftThresholds) DriftReport {
+	rep := DriftReport{Samples: len(X)}
+	if len(X) == 0 {
+		return rep
+	}
+	for j, ref := range st.Features {
+		vals := column(X, j)
+		fd := FeatureDrift{Index: j, Name: featureName(j), PSI: PSI(ref, vals), MeanShift: MeanShift(ref, vals)}
+		fd.Drifted = fd.PSI > thr.PSI || fd.MeanShift > thr.MeanShift
+		if fd.Drifted {
+			rep.Drifted++
+		}
+		rep.Features = append(rep.Features, fd)
+	}
+	if st.Prediction != nil && len(preds) > 0 {
+		vals := toF64(preds)
+		pd := FeatureDrift{Index: -1, Name: "prediction", PSI: PSI(*st.Prediction, vals), MeanShift: MeanShift(*st.Prediction, vals)}
+		pd.Drifted = pd.PSI > thr.PSI || pd.MeanShift > thr.MeanShift
+		rep.Prediction = &pd
+	}
+	minDrifted := thr.MinDrifted
+	if minDrifted < 1 {
+		minDrifted = 1
+	}
+	rep.RetrainNeeded = rep.Drifted >= minDrifted || (rep.Prediction != nil && rep.Prediction.Drifted)
+	return rep
+}
+
+// SaveTrainingStats persists stats keyed by model path.
+func SaveTrainingStats(ctx context.Context, db *sqlitevec.DB, st TrainingStats) error {
+	return db.SaveModelStats(ctx, st.Model, st.CreatedAt, st)
+}
+
+// LoadTrainingStats loads the stats stored for a model path.
+func LoadTrainingStats(ctx context.Context, db *sqlitevec.DB, modelPath string) (TrainingStats, error) {
+	var st TrainingStats
+	s, err := db.LoadModelStats(ctx, modelPath)
+	if err != nil { return st, err }
+	err = json.Unmarshal([]byte(s), &st)
+	return st, err
+}
+
+func binProps(edges, values []float64) []float64 {
+	props := make([]float64, len(edges)+1)
+	if len(values) == 0 {
+		return props
+	}
+	for _, v := range values {
+		props[sort.SearchFloat64s(edges, v)]++
+	}
+	for i := range props {
+		props[i] /= float64(len(values))
+	}
+	return props
+}
+
+func meanStd(values []float64) (float64, float64) {
+	mean := 0.0
+	for _, v := range values { mean += v }
+	mean /= float64(len(values))
+	variance := 0.0
+	for _, v := range values { variance += (v - mean) * (v - mean) }
+	variance /= float64(len(values))
+	return mean, math.Sqrt(variance)
+}
+
+func column(X [][]float32, j int) []float64 {
+	out := make([]float64, 0, len(X))
+	for _, v := range X {
+		if j < len(v) { out = append(out, float64(v[j])) }
+	}
+	return out
+}
+
+func toF64(v []float32) []float64 {
+	out := make([]float64, len(v))
+	for i := range v { out[i] = float64(v[i]) }
+	return out
+}
@@ -0,0 +1,62 @@
+package nn
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"starseed/internal/store/sqlitevec"
+)
+
+func gridVectors(n int, offset float32) [][]float32 {
+	X := make([][]float32, n)
+	for i := range X {
+		v := float32(i%20) + offset
+		X[i] = []float32{v, 2 * v}
+	}
+	return X
+}
+
+func TestPSIStableVsShifted(t *testing.T) {
+	ref := ComputeFeatureStats(column(gridVectors(200, 0), 0), 10)
+	if psi := PSI(ref, column(gridVectors(100, 0), 0)); psi > 0.05 {
+		t.Fatalf("expected near-zero PSI for same distribution, got %.3f", psi)
+	}
+	if psi := PSI(ref, column(gridVectors(100, 15), 0)); psi < 0.25 {
+		t.Fatalf("expected large PSI for shifted distribution, got %.3f", psi)
+	}
+}
+
+func TestComputeDriftFlagsRetrain(t *testing.T) {
+	st := ComputeTrainingStats("m.json", gridVectors(200, 0), []float32{0.1, 0.2, 0.3, 0.4})
+	thr := DriftThresholds{PSI: 0.25, MeanShift: 2, MinDrifted: 2}
+	if rep := ComputeDrift(st, gridVectors(50, 0), nil, thr); rep.RetrainNeeded {
+		t.Fatalf("unexpected retrain flag: %+v", rep)
+	}
+	rep := ComputeDrift(st, gridVectors(50, 30), nil, thr)
+	if !rep.RetrainNeeded || rep.Drifted != 2 {
+		t.Fatalf("expected both features drifted, got %+v", rep)
+	}
+	if rep.Features[0].Name != "log_count" {
+		t.Fatalf("unexpected feature name %q", rep.Features[0].Name)
+	}
+	rep = ComputeDrift(st, gridVectors(50, 0), []float32{5, 5, 5}, thr)
+	if rep.Prediction == nil || !rep.Prediction.Drifted || !rep.RetrainNeeded {
+		t.Fatalf("expected prediction drift to flag retrain, got %+v", rep.Prediction)
+	}
+}
+
+func TestTrainingStatsRoundTrip(t *testing.T) {
+	db, err := sqlitevec.Open(":memory:")
+	if err != nil { t.Fatal(err) }
+	defer db.Close()
+	ctx := context.Background()
+	st := ComputeTrainingStats("m.json", gridVectors(40, 0), nil)
+	st.CreatedAt = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := SaveTrainingStats(ctx, db, st); err != nil { t.Fatal(err) }
+	got, err := LoadTrainingStats(ctx, db, "m.json")
+	if err != nil { t.Fatal(err) }
+	if got.Samples != 40 || len(got.Features) != 2 || got.Features[0].Mean != st.Features[0].Mean {
+		t.Fatalf("stats mismatch: %+v", got)
+	}
+}
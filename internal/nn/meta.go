@@ -15,7 +15,8 @@ func MetaFeatures(tweets []model.Tweet, authors map[string]model.User, keywords
 	rels := make([]float64, 0, len(tweets))
 	low, mid, high := 0, 0, 0
 	for _, t := range tweets {
-		r := model.InterestRelevance(t.Text, keywords, weights)
+		// keyword hits only, so features match the ones the model was trained on
+		r := model.KeywordRelevance(t.Text, keywords, weights)
 		rels = append(rels, float64(r))
 		if u, ok := authors[t.AuthorID]; ok {
 			b := model.BotLikelihood(u)
@@ -0,0 +1,318 @@
+package nn
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// serveCommand builds the long-lived model process; tests replace it with a stand-in.
+var serveCommand = func(binaryPath, modelPath string) *exec.Cmd {
+	return exec.Command(binaryPath, "serve", "--model", modelPath)
+}
+
+// requestTimeout bounds a single request/response round trip.
+var requestTimeout = 30 * time.Second
+
+type serveRequest struct {
+	ID    uint64      `json:"id"`
+	Op    string      `json:"op"`
+	X     [][]float32 `json:"x,omitempty"`
+	Model string      `json:"model,omitempty"`
+}
+
+type serveResponse struct {
+	ID    uint64      `json:"id"`
+	OK    bool        `json:"ok"`
+	Y     [][]float32 `json:"y"`
+	Input int         `json:"input"`
+	Error string      `json:"error"`
+}
+
+// worker is one `starseed-nn serve` process answering JSON lines.
+type worker struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	out    *bufio.Reader
+	nextID uint64
+	gen    int
+}
+
+func startWorker(binaryPath, modelPath string, gen int) (*worker, error) {
+	cmd := serveCommand(binaryPath, modelPath)
+	stdin, err := cmd.StdinPipe()
+	if err != nil { return nil, err }
+	stdout, err := cmd.StdoutPipe()
+	if err != nil { return nil, err }
+	if err := cmd.Start(); err != nil { return nil, err }
+	w := &worker{cmd: cmd, stdin: stdin, out: bufio.NewReaderSize(stdout, 1<<20), gen: gen}
+	if _, err := w.call(serveRequest{Op: "health"}); err != nil {
+		w.kill()
+		return nil, fmt.Errorf("nn worker health: %w", err)
+	}
+	return w, nil
+}
+
+func (w *worker) call(req serveRequest) (serveResponse, error) {
+	var resp serveResponse
+	w.nextID++
+	req.ID = w.nextID
+	b, err := json.Marshal(req)
+	if err != nil { return resp, err }
+	if _, err := w.stdin.Write(append(b, '\n')); err != nil { return resp, err }
+	type result struct {
+		line []byte
+		err  error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		line, err := w.out.ReadBytes('\n')
+		ch <- result{line, err}
+	}()
+	select {
+	case r := <-ch:
+		if r.err != nil { return resp, r.err }
+		if err := json.Unmarshal(r.line, &resp); err != nil { return resp, err }
+	case <-time.After(requestTimeout):
+		w.kill()
+		return resp, errors.New("nn worker timeout")
+	}
+	if resp.ID != req.ID { return resp, fmt.Errorf("nn worker response id %d, want %d", resp.ID, req.ID) }
+	if !resp.OK { return resp, &remoteError{msg: resp.Error} }
+	return resp, nil
+}
+
+// remoteError is an error reported by a healthy worker (bad input, unreadable model).
+type remoteError struct{ msg string }
+
+func (e *remoteError) Error() string { return "nn worker: " + e.msg }
+
+func (w *worker) kill() {
+	if w.cmd == nil { return }
+	_ = w.stdin.Close()
+	if w.cmd.Process != nil { _ = w.cmd.Process.Kill() }
+	_ = w.cmd.Wait()
+}
+
+// Pool keeps model processes warm and hands each request to an idle worker.
+// Workers are restarted after a crash and reloaded when the model is swapped
+// or the model file changes on disk (e.g., after a retrain promotes it).
+type Pool struct {
+	bin  string
+	idle chan *worker
+	// done is closed by Close so requests waiting for a worker give up
+	done chan struct{}
+
+	mu      sync.Mutex
+	model   string
+	modTime time.Time
+	gen     int
+	closed  bool
+}
+
+// NewPool starts size workers serving modelPath.
+func NewPool(binaryPath, modelPath string, size int) (*Pool, error) {
+	if size < 1 { size = 1 }
+	p := &Pool{bin: binaryPath, model: modelPath, idle: make(chan *worker, size), done: make(chan struct{}), modTime: modTime(modelPath)}
+	for i := 0; i < size; i++ {
+		w, err := startWorker(binaryPath, modelPath, 0)
+		if err != nil { _ = p.Close(); return nil, err }
+		p.idle <- w
+	}
+	return p, nil
+}
+
+// Infer returns one prediction row per sample, like the one-shot Infer.
+func (p *Pool) Infer(samples []FeatureVector) ([][]float32, error) {
+	x := make([][]float32, len(samples))
+	for i, s := range samples { x[i] = s.X }
+	resp, err := p.do(serveRequest{Op: "infer", X: x})
+	if err != nil { return nil, err }
+	return resp.Y, nil
+}
+
+// Health checks that an idle worker answers.
+func (p *Pool) Health() error {
+	_, err := p.do(serveRequest{Op: "health"})
+	return err
+}
+
+// Swap points the pool at a new model; workers reload it before their next request.
+func (p *Pool) Swap(modelPath string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.model = modelPath
+	p.modTime = modTime(modelPath)
+	p.gen++
+}
+
+// Close stops all workers.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	if p.closed { p.mu.Unlock(); return nil }
+	p.closed = true
+	close(p.done)
+	p.mu.Unlock()
+	for {
+		select {
+		case w := <-p.idle:
+			w.kill()
+		default:
+			return nil
+		}
+	}
+}
+
+func (p *Pool) do(req serveRequest) (serveResponse, error) {
+	model, gen, err := p.current()
+	if err != nil { return serveResponse{}, err }
+	var w *worker
+	select {
+	case w = <-p.idle:
+	case <-p.done:
+		return serveResponse{}, errors.New("nn pool closed")
+	}
+	defer func() { p.release(w) }()
+	if w.cmd == nil {
+		// Empty slot left by a failed restart
+		if w, err = p.restart(w, model, gen); err != nil { return serveResponse{}, err }
+	}
+	if w.gen != gen {
+		if _, err := w.call(serveRequest{Op: "reload", Model: model}); err != nil {
+			if w, err = p.restart(w, model, gen); err != nil { return serveResponse{}, err }
+		}
+		w.gen = gen
+	}
+	resp, err := w.call(req)
+	var re *remoteError
+	if err == nil || errors.As(err, &re) {
+		return resp, err
+	}
+	// Transport failure: the process crashed or hung; replace it and retry once.
+	if w, err = p.restart(w, model, gen); err != nil { return serveResponse{}, err }
+	return w.call(req)
+}
+
+// current returns the model to serve, bumping the generation if the file changed on disk.
+func (p *Pool) current() (string, int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed { return "", 0, errors.New("nn pool closed") }
+	if mt := modTime(p.model); !mt.IsZero() && !mt.Equal(p.modTime) {
+		p.modTime = mt
+		p.gen++
+	}
+	return p.model, p.gen, nil
+}
+
+// release returns w to the pool, or stops it once the pool is closed. The send happens
+// under mu so Close cannot drain idle between the check and the send.
+func (p *Pool) release(w *worker) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed { w.kill(); return }
+	p.idle <- w
+}
+
+func (p *Pool) modelPath() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.model
+}
+
+// restart replaces old with a fresh worker. When the start fails it returns an empty
+// slot (no process) so the pool keeps its size; the next request retries the start.
+func (p *Pool) restart(old *worker, model string, gen int) (*worker, error) {
+	old.kill()
+	w, err := startWorker(p.bin, model, gen)
+	if err != nil { return &worker{gen: -1}, err }
+	return w, nil
+}
+
+func modTime(path string) time.Time {
+	fi, err := os.Stat(path)
+	if err != nil { return time.Time{} }
+	return fi.ModTime()
+}
+
+var (
+	poolsMu sync.Mutex
+	pools   = map[string]*Pool{}
+	// failed remembers when starting a pool last failed (e.g. a binary without serve
+	// mode) so one-shot inference is used until poolRetry has passed
+	failed    = map[string]time.Time{}
+	poolRetry = 5 * time.Minute
+)
+
+// poolSize reads STARSEED_NN_WORKERS (default 2; 0 disables the pool).
+func poolSize() int {
+	if v := os.Getenv("STARSEED_NN_WORKERS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 { return n }
+	}
+	return 2
+}
+
+func sharedPool(binaryPath, modelPath string) *Pool {
+	size := poolSize()
+	if size == 0 { return nil }
+	key := binaryPath + "\x00" + modelPath
+	poolsMu.Lock()
+	if p, ok := pools[key]; ok { poolsMu.Unlock(); return p }
+	if at, ok := failed[key]; ok && time.Since(at) < poolRetry { poolsMu.Unlock(); return nil }
+	poolsMu.Unlock()
+	// Workers are started and health-checked outside poolsMu so other models' pools
+	// stay usable meanwhile; a pool stored by a concurrent caller wins.
+	p, err := NewPool(binaryPath, modelPath, size)
+	poolsMu.Lock()
+	if err != nil {
+		// A missing model is retried on the next call; other failures after poolRetry.
+		if !modTime(modelPath).IsZero() { failed[key] = time.Now() }
+		poolsMu.Unlock()
+		return nil
+	}
+	delete(failed, key)
+	cur, ok := pools[key]
+	if !ok { pools[key] = p }
+	poolsMu.Unlock()
+	if ok { _ = p.Close(); return cur }
+	return p
+}
+
+// SwapModel hot-swaps every shared pool serving oldPath to newPath.
+func SwapModel(oldPath, newPath string) {
+	poolsMu.Lock()
+	defer poolsMu.Unlock()
+	for key, p := range pools {
+		if p.modelPath() != oldPath { continue }
+		p.Swap(newPath)
+		delete(pools, key)
+		pools[p.bin+"\x00"+newPath] = p
+	}
+}
+
+// Promote moves a freshly trained model over modelPath and hot-swaps the warm pools
+// serving it, so running processes answer with the new model from the next request.
+func Promote(candidate, modelPath string) error {
+	if err := os.Rename(candidate, modelPath); err != nil { return err }
+	SwapModel(modelPath, modelPath)
+	return nil
+}
+
+// ClosePools stops all shared workers.
+func ClosePools() {
+	poolsMu.Lock()
+	open := make([]*Pool, 0, len(pools))
+	for key, p := range pools {
+		open = append(open, p)
+		delete(pools, key)
+	}
+	poolsMu.Unlock()
+	for _, p := range open { _ = p.Close() }
+}
@@ -0,0 +1,166 @@
+package nn
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestHelperServe is not a real test: it stands in for `starseed-nn serve` when
+// re-executed by the pool. The fake model file holds {"scale": k}; y = k*sum(x),
+// and a sample starting with -1 crashes the process.
+func TestHelperServe(t *testing.T) {
+	if os.Getenv("STARSEED_NN_HELPER") != "1" { return }
+	load := func(path string) float32 {
+		var m struct{ Scale float32 `json:"scale"` }
+		b, _ := os.ReadFile(path)
+		_ = json.Unmarshal(b, &m)
+		return m.Scale
+	}
+	scale := load(os.Getenv("STARSEED_NN_MODEL"))
+	in := bufio.NewScanner(os.Stdin)
+	for in.Scan() {
+		var req serveRequest
+		_ = json.Unmarshal(in.Bytes(), &req)
+		resp := serveResponse{ID: req.ID, OK: true}
+		switch req.Op {
+		case "reload":
+			scale = load(req.Model)
+		case "infer":
+			for _, x := range req.X {
+				if len(x) > 0 && x[0] == -1 { os.Exit(3) }
+				sum := float32(0)
+				for _, v := range x { sum += v }
+				resp.Y = append(resp.Y, []float32{scale * sum})
+			}
+		}
+		b, _ := json.Marshal(resp)
+		fmt.Println(string(b))
+	}
+	os.Exit(0)
+}
+
+func writeFakeModel(t *testing.T, path string, scale float32, mtime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(fmt.Sprintf(`{"scale":%g}`, scale)), 0o644); err != nil { t.Fatal(err) }
+	if err := os.Chtimes(path, mtime, mtime); err != nil { t.Fatal(err) }
+}
+
+func newHelperPool(t *testing.T, model string) *Pool {
+	t.Helper()
+	prev := serveCommand
+	serveCommand = helperCommand
+	t.Cleanup(func() { serveCommand = prev })
+	p, err := NewPool("fake-bin", model, 2)
+	if err != nil { t.Fatal(err) }
+	t.Cleanup(func() { _ = p.Close() })
+	return p
+}
+
+func TestPoolInferAndHealth(t *testing.T) {
+	model := filepath.Join(t.TempDir(), "model.json")
+	writeFakeModel(t, model, 2, time.Now().Add(-time.Hour))
+	p := newHelperPool(t, model)
+	if err := p.Health(); err != nil { t.Fatal(err) }
+	preds, err := p.Infer([]FeatureVector{{X: []float32{1, 2}}, {X: []float32{3}}})
+	if err != nil { t.Fatal(err) }
+	if len(preds) != 2 || preds[0][0] != 6 || preds[1][0] != 6 { t.Fatalf("unexpected preds %v", preds) }
+}
+
+func TestPoolRestartsAfterCrash(t *testing.T) {
+	model := filepath.Join(t.TempDir(), "model.json")
+	writeFakeModel(t, model, 1, time.Now().Add(-time.Hour))
+	p := newHelperPool(t, model)
+	if _, err := p.Infer([]FeatureVector{{X: []float32{-1}}}); err == nil { t.Fatalf("expected crash error") }
+	for i := 0; i < 3; i++ {
+		preds, err := p.Infer([]FeatureVector{{X: []float32{4}}})
+		if err != nil || preds[0][0] != 4 { t.Fatalf("pool did not recover: %v %v", preds, err) }
+	}
+}
+
+func TestPoolHotSwap(t *testing.T) {
+	dir := t.TempDir()
+	model := filepath.Join(dir, "model.json")
+	writeFakeModel(t, model, 1, time.Now().Add(-time.Hour))
+	p := newHelperPool(t, model)
+	// Promotion overwrites the model in place: workers reload on the next request.
+	writeFakeModel(t, model, 3, time.Now())
+	for i := 0; i < 2; i++ {
+		preds, err := p.Infer([]FeatureVector{{X: []float32{1}}})
+		if err != nil || preds[0][0] != 3 { t.Fatalf("expected reloaded model, got %v %v", preds, err) }
+	}
+	next := filepath.Join(dir, "model-v2.json")
+	writeFakeModel(t, next, 5, time.Now())
+	p.Swap(next)
+	preds, err := p.Infer([]FeatureVector{{X: []float32{1}}})
+	if err != nil || preds[0][0] != 5 { t.Fatalf("expected swapped model, got %v %v", preds, err) }
+}
+
+func helperCommand(bin, modelPath string) *exec.Cmd {
+	cmd := exec.Command(os.Args[0], "-test.run=TestHelperServe")
+	cmd.Env = append(os.Environ(), "STARSEED_NN_HELPER=1", "STARSEED_NN_MODEL="+modelPath)
+	return cmd
+}
+
+func failingCommand(bin, modelPath string) *exec.Cmd { return exec.Command(filepath.Join(os.TempDir(), "no-such-starseed-nn")) }
+
+func TestPoolFailedRestartLeavesRetryableSlot(t *testing.T) {
+	model := filepath.Join(t.TempDir(), "model.json")
+	writeFakeModel(t, model, 2, time.Now().Add(-time.Hour))
+	prev := serveCommand
+	t.Cleanup(func() { serveCommand = prev })
+	serveCommand = helperCommand
+	p, err := NewPool("fake-bin", model, 1)
+	if err != nil { t.Fatal(err) }
+	defer p.Close()
+	serveCommand = failingCommand
+	if _, err := p.Infer([]FeatureVector{{X: []float32{-1}}}); err == nil { t.Fatal("expected error when the restart fails") }
+	if _, err := p.Infer([]FeatureVector{{X: []float32{1}}}); err == nil { t.Fatal("expected error while the binary is missing") }
+	serveCommand = helperCommand
+	if preds, err := p.Infer([]FeatureVector{{X: []float32{1}}}); err != nil || preds[0][0] != 2 { t.Fatalf("slot not restarted: %v %v", preds, err) }
+}
+
+func TestSharedPoolRetriesAndPromote(t *testing.T) {
+	dir := t.TempDir()
+	model := filepath.Join(dir, "model.json")
+	writeFakeModel(t, model, 2, time.Now().Add(-time.Hour))
+	prev, prevRetry := serveCommand, poolRetry
+	t.Cleanup(func() { serveCommand, poolRetry = prev, prevRetry; ClosePools() })
+	serveCommand = failingCommand
+	if sharedPool("fake-bin", model) != nil { t.Fatal("expected no pool") }
+	serveCommand = helperCommand
+	if sharedPool("fake-bin", model) != nil { t.Fatal("failure should be remembered until poolRetry") }
+	poolRetry = 0
+	p := sharedPool("fake-bin", model)
+	if p == nil { t.Fatal("pool not retried") }
+	// keep the file time so only the explicit swap can reload the workers
+	candidate := filepath.Join(dir, "model.json.candidate")
+	writeFakeModel(t, candidate, 7, time.Now().Add(-time.Hour))
+	if err := Promote(candidate, model); err != nil { t.Fatal(err) }
+	if preds, err := p.Infer([]FeatureVector{{X: []float32{1}}}); err != nil || preds[0][0] != 7 { t.Fatalf("promoted model not served: %v %v", preds, err) }
+}
+
+func TestPoolCloseWakesWaiters(t *testing.T) {
+	model := filepath.Join(t.TempDir(), "model.json")
+	writeFakeModel(t, model, 1, time.Now().Add(-time.Hour))
+	p := newHelperPool(t, model)
+	// Hold both workers so the next request has to wait for one.
+	held := []*worker{<-p.idle, <-p.idle}
+	errc := make(chan error, 1)
+	go func() { _, err := p.Infer([]FeatureVector{{X: []float32{1}}}); errc <- err }()
+	time.Sleep(50 * time.Millisecond)
+	_ = p.Close()
+	select {
+	case err := <-errc:
+		if err == nil { t.Fatal("expected closed pool error") }
+	case <-time.After(5 * time.Second):
+		t.Fatal("Infer still blocked after Close")
+	}
+	for _, w := range held { p.release(w) }
+	if len(p.idle) != 0 { t.Fatal("workers returned to a closed pool") }
+}
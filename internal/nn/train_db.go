@@ -3,27 +3,39 @@ package nn
 import (
 	"context"
 	"fmt"
+	"os"
 	"time"
 
 	"starseed/internal/store/sqlitevec"
 )
 
-// TrainFromDB loads labeled windows from SQLite and trains the model.
+// TrainFromDB loads labeled windows from SQLite and trains the model with default options.
 func TrainFromDB(ctx context.Context, db *sqlitevec.DB, start, end time.Time, binPath, outPath string) error {
-	ts, X, y, err := db.LoadFeatures(ctx, start, end)
+	return TrainFromDBWithOptions(ctx, db, start, end, binPath, outPath, DefaultTrainOptions())
+}
+
+// TrainFromDBWithOptions trains on labeled windows with the given (e.g., tuned) options.
+func TrainFromDBWithOptions(ctx context.Context, db *sqlitevec.DB, start, end time.Time, binPath, outPath string, opts TrainOptions) error {
+	samples, err := LoadLabeledSamples(ctx, db, start, end)
 	if err != nil { return err }
-	var samples []FeatureVector
-	for i := range ts {
-		if y[i] < 0 { continue }
-		samples = append(samples, FeatureVector{X: X[i], Y: []float32{y[i]}})
-	}
 	if len(samples) == 0 { return fmt.Errorf("no labeled samples") }
-    opts := TrainOptions{Hidden: 64, Epochs: 10, LR: 0.01, ValSplit: 0.2, Patience: 3, Calibrate: true, Checkpoint: outPath}
-    if err := TrainWithOptions(binPath, outPath, samples, opts); err != nil { return err }
+    // Train next to the live model and promote only a finished model
+    candidate := outPath + ".candidate"
+    opts.Checkpoint = candidate
+    opts.Calibrate = true
+    if err := TrainWithOptions(binPath, candidate, samples, opts); err != nil { _ = os.Remove(candidate); return err }
+    if err := Promote(candidate, outPath); err != nil { return err }
     // Load threshold from model file and save to DB calibration for engage
     thr := LoadThresholdFromModel(outPath)
     if thr > 0 {
         _ = db.SaveThreshold(ctx, float64(thr))
     }
-    return nil
+    // Store training-time feature and prediction statistics for drift monitoring
+    trainX := make([][]float32, 0, len(samples))
+    for _, s := range samples { trainX = append(trainX, s.X) }
+    var preds []float32
+    if out, err := Infer(binPath, outPath, samples); err == nil {
+        for _, p := range out { if len(p) > 0 { preds = append(preds, p[0]) } }
+    }
+    return SaveTrainingStats(ctx, db, ComputeTrainingStats(outPath, trainX, preds))
 }
@@ -0,0 +1,211 @@
+package nn
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"starseed/internal/store/sqlitevec"
+)
+
+// TuneSpace lists candidate values for each hyperparameter.
+type TuneSpace struct {
+	Hidden   []int
+	LR       []float32
+	Epochs   []int
+	ValSplit []float32
+}
+
+// DefaultTuneSpace brackets the values TrainFromDB used to hardcode.
+func DefaultTuneSpace() TuneSpace {
+	return TuneSpace{
+		Hidden:   []int{16, 32, 64, 128},
+		LR:       []float32{0.001, 0.003, 0.01, 0.03},
+		Epochs:   []int{5, 10, 20},
+		ValSplit: []float32{0.1, 0.2, 0.3},
+	}
+}
+
+// TuneOptions controls the search.
+type TuneOptions struct {
+	Mode    string // "grid" or "random"
+	Trials  int    // random mode: number of sampled configurations
+	Folds   int    // time-ordered cross-validation folds
+	Workers int    // trials evaluated in parallel
+	Seed    int64
+}
+
+// TrialResult is the cross-validated score of one configuration (lower is better).
+type TrialResult struct {
+	Params     TrainOptions `json:"params"`
+	Score      float64      `json:"score"`
+	FoldScores []float64    `json:"fold_scores"`
+	Err        string       `json:"error,omitempty"`
+}
+
+// FoldRunner trains on train and returns the validation loss on val.
+type FoldRunner func(ctx context.Context, opts TrainOptions, train, val []FeatureVector) (float64, error)
+
+// Fold is a [0,TrainEnd) train / [TrainEnd,ValEnd) validation split over time-ordered samples.
+type Fold struct{ TrainEnd, ValEnd int }
+
+// DefaultTrainOptions are the settings used when no tuned configuration is available.
+func DefaultTrainOptions() TrainOptions {
+	return TrainOptions{Hidden: 64, Epochs: 10, LR: 0.01, ValSplit: 0.2, Patience: 3, Calibrate: true}
+}
+
+// TimeSeriesFolds builds expanding-window folds so validation always follows training in time.
+func TimeSeriesFolds(n, k int) []Fold {
+	if k < 1 { k = 1 }
+	chunk := n / (k + 1)
+	if chunk < 1 { return nil }
+	folds := make([]Fold, 0, k)
+	for i := 1; i <= k; i++ {
+		end := (i + 1) * chunk
+		if i == k { end = n }
+		folds = append(folds, Fold{TrainEnd: i * chunk, ValEnd: end})
+	}
+	return folds
+}
+
+// Configurations expands the search space according to opts.Mode.
+func Configurations(space TuneSpace, opts TuneOptions) []TrainOptions {
+	base := DefaultTrainOptions()
+	pickI := func(v []int, def int) []int { if len(v) == 0 { return []int{def} }; return v }
+	pickF := func(v []float32, def float32) []float32 { if len(v) == 0 { return []float32{def} }; return v }
+	hidden, epochs := pickI(space.Hidden, base.Hidden), pickI(space.Epochs, base.Epochs)
+	lrs, splits := pickF(space.LR, base.LR), pickF(space.ValSplit, base.ValSplit)
+	var out []TrainOptions
+	if opts.Mode == "random" {
+		rng := rand.New(rand.NewSource(opts.Seed))
+		n := opts.Trials
+		if n <= 0 { n = 10 }
+		for i := 0; i < n; i++ {
+			o := base
+			o.Hidden, o.Epochs = hidden[rng.Intn(len(hidden))], epochs[rng.Intn(len(epochs))]
+			o.LR, o.ValSplit = lrs[rng.Intn(len(lrs))], splits[rng.Intn(len(splits))]
+			out = append(out, o)
+		}
+		return out
+	}
+	for _, h := range hidden {
+		for _, lr := range lrs {
+			for _, e := range epochs {
+				for _, vs := range splits {
+					o := base
+					o.Hidden, o.LR, o.Epochs, o.ValSplit = h, lr, e, vs
+					out = append(out, o)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// Tune evaluates every configuration with time-ordered cross-validation using at most
+// opts.Workers concurrent trials. Samples must be sorted by window start. Results are
+// returned best first; failed trials sort last.
+func Tune(ctx context.Context, samples []FeatureVector, space TuneSpace, opts TuneOptions, run FoldRunner) ([]TrialResult, error) {
+	folds := TimeSeriesFolds(len(samples), opts.Folds)
+	if len(folds) == 0 { return nil, fmt.Errorf("not enough samples (%d) for %d folds", len(samples), opts.Folds) }
+	configs := Configurations(space, opts)
+	workers := opts.Workers
+	if workers < 1 { workers = 1 }
+	results := make([]TrialResult, len(configs))
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, cfg := range configs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, cfg TrainOptions) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = runTrial(ctx, samples, folds, cfg, run)
+		}(i, cfg)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil { return nil, err }
+	sort.SliceStable(results, func(i, j int) bool {
+		if (results[i].Err == "") != (results[j].Err == "") { return results[i].Err == "" }
+		return results[i].Score < results[j].Score
+	})
+	return results, nil
+}
+
+func runTrial(ctx context.Context, samples []FeatureVector, folds []Fold, cfg TrainOptions, run FoldRunner) TrialResult {
+	res := TrialResult{Params: cfg}
+	sum := 0.0
+	for _, f := range folds {
+		if ctx.Err() != nil { res.Err = ctx.Err().Error(); return res }
+		loss, err := run(ctx, cfg, samples[:f.TrainEnd], samples[f.TrainEnd:f.ValEnd])
+		if err != nil { res.Err = err.Error(); return res }
+		res.FoldScores = append(res.FoldScores, loss)
+		sum += loss
+	}
+	res.Score = sum / float64(len(folds))
+	return res
+}
+
+// BinaryFoldRunner trains the Rust model in a temp dir and scores validation MSE.
+func BinaryFoldRunner(binaryPath string) FoldRunner {
+	return func(ctx context.Context, opts TrainOptions, train, val []FeatureVector) (float64, error) {
+		dir, err := os.MkdirTemp("", "starseed-tune-")
+		if err != nil { return 0, err }
+		defer os.RemoveAll(dir)
+		modelPath := filepath.Join(dir, "model.json")
+		opts.Checkpoint = modelPath
+		opts.Calibrate = false
+		if err := TrainWithOptions(binaryPath, modelPath, train, opts); err != nil { return 0, err }
+		preds, err := inferOnce(binaryPath, modelPath, val)
+		if err != nil { return 0, err }
+		return validationMSE(preds, val), nil
+	}
+}
+
+func validationMSE(preds [][]float32, val []FeatureVector) float64 {
+	sum, n := 0.0, 0
+	for i := range val {
+		if i >= len(preds) || len(preds[i]) == 0 || len(val[i].Y) == 0 { continue }
+		d := float64(preds[i][0] - val[i].Y[0])
+		sum += d * d
+		n++
+	}
+	if n == 0 { return 0 }
+	return sum / float64(n)
+}
+
+// LoadLabeledSamples returns labeled windows in [start,end) ordered by time.
+func LoadLabeledSamples(ctx context.Context, db *sqlitevec.DB, start, end time.Time) ([]FeatureVector, error) {
+	ts, X, y, err := db.LoadFeatures(ctx, start, end)
+	if err != nil { return nil, err }
+	var samples []FeatureVector
+	for i := range ts {
+		if y[i] < 0 { continue }
+		samples = append(samples, FeatureVector{X: X[i], Y: []float32{y[i]}})
+	}
+	return samples, nil
+}
+
+// SaveTrials persists a tuning run's results.
+func SaveTrials(ctx context.Context, db *sqlitevec.DB, runID string, results []TrialResult) error {
+	now := time.Now().UTC()
+	for _, r := range results {
+		if err := db.PutTuneTrial(ctx, runID, now, r.Params, r.Score, r.FoldScores, r.Err); err != nil { return err }
+	}
+	return nil
+}
+
+// LoadBestTrainOptions returns the best successful configuration of the most recent tuning run.
+func LoadBestTrainOptions(ctx context.Context, db *sqlitevec.DB) (TrainOptions, float64, error) {
+	var opts TrainOptions
+	params, score, err := db.BestTuneTrial(ctx)
+	if err != nil { return opts, 0, err }
+	err = json.Unmarshal([]byte(params), &opts)
+	return opts, score, err
+}
@@ -0,0 +1,64 @@
+package nn
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+
+	"starseed/internal/store/sqlitevec"
+)
+
+func TestTimeSeriesFoldsAreOrdered(t *testing.T) {
+	folds := TimeSeriesFolds(10, 3)
+	if len(folds) != 3 { t.Fatalf("expected 3 folds, got %d", len(folds)) }
+	prev := 0
+	for _, f := range folds {
+		if f.TrainEnd <= prev || f.ValEnd <= f.TrainEnd { t.Fatalf("bad fold %+v", f) }
+		prev = f.TrainEnd
+	}
+	if folds[2].ValEnd != 10 { t.Fatalf("last fold should end at n, got %d", folds[2].ValEnd) }
+	if TimeSeriesFolds(2, 3) != nil { t.Fatalf("expected no folds for tiny sample") }
+}
+
+func TestTuneFindsBestAndLimitsWorkers(t *testing.T) {
+	samples := make([]FeatureVector, 20)
+	for i := range samples { samples[i] = FeatureVector{X: []float32{float32(i)}, Y: []float32{0}} }
+	var mu sync.Mutex
+	running, peak := 0, 0
+	run := func(ctx context.Context, o TrainOptions, train, val []FeatureVector) (float64, error) {
+		mu.Lock()
+		running++
+		if running > peak { peak = running }
+		mu.Unlock()
+		defer func() { mu.Lock(); running--; mu.Unlock() }()
+		if train[len(train)-1].X[0] >= val[0].X[0] { t.Errorf("validation precedes training") }
+		return math.Abs(float64(o.LR)-0.01) + float64(o.Hidden)/1000, nil
+	}
+	space := TuneSpace{Hidden: []int{32, 64}, LR: []float32{0.001, 0.01}, Epochs: []int{5}, ValSplit: []float32{0.2}}
+	res, err := Tune(context.Background(), samples, space, TuneOptions{Mode: "grid", Folds: 3, Workers: 2}, run)
+	if err != nil { t.Fatal(err) }
+	if len(res) != 4 { t.Fatalf("expected 4 grid trials, got %d", len(res)) }
+	if res[0].Params.Hidden != 32 || res[0].Params.LR != 0.01 || len(res[0].FoldScores) != 3 {
+		t.Fatalf("unexpected best %+v", res[0])
+	}
+	if peak > 2 { t.Fatalf("worker limit exceeded: %d", peak) }
+
+	db, _ := sqlitevec.Open(":memory:")
+	defer db.Close()
+	ctx := context.Background()
+	if err := SaveTrials(ctx, db, "run1", res); err != nil { t.Fatal(err) }
+	best, score, err := LoadBestTrainOptions(ctx, db)
+	if err != nil { t.Fatal(err) }
+	if best.Hidden != 32 || best.LR != 0.01 || score != res[0].Score { t.Fatalf("best mismatch %+v %v", best, score) }
+}
+
+func TestRandomConfigurationsDeterministic(t *testing.T) {
+	opts := TuneOptions{Mode: "random", Trials: 5, Seed: 7}
+	a := Configurations(DefaultTuneSpace(), opts)
+	b := Configurations(DefaultTuneSpace(), opts)
+	if len(a) != 5 { t.Fatalf("expected 5 trials, got %d", len(a)) }
+	for i := range a {
+		if a[i] != b[i] { t.Fatalf("random configs not reproducible at %d", i) }
+	}
+}
@@ -0,0 +1,46 @@
+package policy
+
+import (
+	"strings"
+	"time"
+
+	"starseed/internal/model"
+)
+
+// Facts are the values rules are evaluated against, keyed by the names in Fields. Only
+// known facts are set; conditions on missing facts never match.
+type Facts map[string]any
+
+// WithTweet adds tweet facts; age is measured at now.
+func (f Facts) WithTweet(t model.Tweet, now time.Time) Facts {
+	f["tweet.organic"] = model.OrganicContentScore(t)
+	f["tweet.lang"] = t.Language
+	f["tweet.text"] = t.Text
+	f["tweet.likes"] = t.LikeCount
+	f["tweet.replies"] = t.ReplyCount
+	f["tweet.retweets"] = t.RetweetCount
+	f["tweet.has_link"] = t.HasLink
+	f["tweet.is_reply"] = t.ReplyToID != ""
+	if !t.CreatedAt.IsZero() { f["tweet.age_hours"] = now.Sub(t.CreatedAt).Hours() }
+	return f
+}
+
+// WithAuthor adds author facts, scoring author.bot with bot (nil: the profile heuristic).
+func (f Facts) WithAuthor(u model.User, bot model.BotScorer) Facts {
+	f["author.username"] = u.Username
+	f["author.bot"] = bot.Score(u)
+	f["author.followers"] = u.FollowersCount
+	f["author.following"] = u.FollowingCount
+	f["author.verified"] = u.Verified
+	return f
+}
+
+// WithTime adds the local hour and weekday and whether now is quiet.
+func (f Facts) WithTime(now time.Time, loc *time.Location, quiet bool) Facts {
+	if loc == nil { loc = time.UTC }
+	local := now.In(loc)
+	f["time.hour"] = local.Hour()
+	f["time.weekday"] = strings.ToLower(local.Weekday().String()[:3])
+	f["time.quiet"] = quiet
+	return f
+}
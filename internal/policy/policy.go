@@ -0,0 +1,269 @@
+// Package policy evaluates ordered engagement rules (YAML) against facts about a
+// candidate action: the tweet, its author, the model prediction, time and budget state.
+// The first rule whose conditions all hold decides allow, deny or defer.
+package policy
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"starseed/internal/config"
+)
+
+// Decisions.
+const (
+	Allow = "allow"
+	Deny  = "deny"
+	Defer = "defer"
+)
+
+// Policy is an ordered rule list with a default decision.
+type Policy struct {
+	Default string `yaml:"default"`
+	Rules   []Rule `yaml:"rules"`
+}
+
+// Rule matches when every condition in When holds.
+type Rule struct {
+	Name   string               `yaml:"name"`
+	Action string               `yaml:"action"` // allow, deny or defer
+	Reason string               `yaml:"reason"`
+	When   map[string]Condition `yaml:"when"`
+}
+
+// Condition compares one fact. In YAML a bare scalar or list means eq / in, otherwise a
+// map of operators: eq, ne, gt, gte, lt, lte, in, not_in, contains, not_contains.
+type Condition map[string]any
+
+// UnmarshalYAML accepts the scalar and list shorthands.
+func (c *Condition) UnmarshalYAML(n *yaml.Node) error {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		var v any
+		if err := n.Decode(&v); err != nil { return err }
+		*c = Condition{"eq": v}
+	case yaml.SequenceNode:
+		var v []any
+		if err := n.Decode(&v); err != nil { return err }
+		*c = Condition{"in": v}
+	default:
+		var m map[string]any
+		if err := n.Decode(&m); err != nil { return err }
+		*c = m
+	}
+	return nil
+}
+
+// Result is the engine's answer and the rule that produced it ("default" when none matched).
+type Result struct {
+	Decision string `json:"decision"`
+	Rule     string `json:"rule"`
+	Reason   string `json:"reason"`
+}
+
+// Fields lists the facts rules may reference and their kinds.
+var Fields = map[string]string{
+	"action":           "string", // reply, like, ...
+	"tweet.organic":    "number",
+	"tweet.lang":       "string",
+	"tweet.text":       "string",
+	"tweet.likes":      "number",
+	"tweet.replies":    "number",
+	"tweet.retweets":   "number",
+	"tweet.has_link":   "bool",
+	"tweet.is_reply":   "bool",
+	"tweet.age_hours":  "number",
+	"author.username":  "string",
+	"author.bot":       "number",
+	"author.followers": "number",
+	"author.following": "number",
+	"author.verified":  "bool",
+	"author.score":     "number",
+	"prediction":       "number",
+	"model.pass":       "bool",
+	"draft.score":      "number",
+	"time.hour":        "number",
+	"time.weekday":     "string", // mon..sun
+	"time.quiet":       "bool",
+	"budget.allowed":   "bool",
+	"cooldown.allowed": "bool",
+}
+
+var operators = map[string]bool{"eq": true, "ne": true, "gt": true, "gte": true, "lt": true, "lte": true, "in": true, "not_in": true, "contains": true, "not_contains": true}
+
+// Parse reads and validates a YAML policy.
+func Parse(b []byte) (*Policy, error) {
+	var p Policy
+	if err := yaml.Unmarshal(b, &p); err != nil { return nil, err }
+	if err := p.Validate(); err != nil { return nil, err }
+	return &p, nil
+}
+
+// Load reads a policy file.
+func Load(path string) (*Policy, error) {
+	b, err := os.ReadFile(path)
+	if err != nil { return nil, err }
+	p, err := Parse(b)
+	if err != nil { return nil, fmt.Errorf("%s: %w", path, err) }
+	return p, nil
+}
+
+// Validate rejects unknown decisions, fields and operators.
+func (p *Policy) Validate() error {
+	if p.Default == "" { p.Default = Allow }
+	if !validDecision(p.Default) { return fmt.Errorf("default: unknown decision %q", p.Default) }
+	for i, r := range p.Rules {
+		name := r.Name
+		if name == "" { name = fmt.Sprintf("#%d", i+1) }
+		if !validDecision(r.Action) { return fmt.Errorf("rule %s: unknown action %q (want allow, deny or defer)", name, r.Action) }
+		if len(r.When) == 0 { return fmt.Errorf("rule %s: no conditions", name) }
+		for field, c := range r.When {
+			kind, ok := Fields[field]
+			if !ok { return fmt.Errorf("rule %s: unknown field %q", name, field) }
+			for op, v := range c {
+				if !operators[op] { return fmt.Errorf("rule %s: %s: unknown operator %q", name, field, op) }
+				if (op == "gt" || op == "gte" || op == "lt" || op == "lte") && kind != "number" { return fmt.Errorf("rule %s: %s: %s needs a numeric field", name, field, op) }
+				if (op == "contains" || op == "not_contains") && kind != "string" { return fmt.Errorf("rule %s: %s: %s needs a text field", name, field, op) }
+				if op == "in" || op == "not_in" {
+					if _, ok := v.([]any); !ok { return fmt.Errorf("rule %s: %s: %s needs a list", name, field, op) }
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func validDecision(d string) bool { return d == Allow || d == Deny || d == Defer }
+
+// Evaluate returns the decision of the first matching rule. Conditions on facts that are
+// missing (e.g. no prediction yet) do not match.
+func (p *Policy) Evaluate(f Facts) Result {
+	for i, r := range p.Rules {
+		if !r.matches(f) { continue }
+		name := r.Name
+		if name == "" { name = fmt.Sprintf("#%d", i+1) }
+		reason := r.Reason
+		if reason == "" { reason = r.describe() }
+		return Result{Decision: r.Action, Rule: name, Reason: reason}
+	}
+	return Result{Decision: p.Default, Rule: "default", Reason: "no rule matched"}
+}
+
+func (r Rule) matches(f Facts) bool {
+	for field, c := range r.When {
+		v, ok := f[field]
+		if !ok { return false }
+		for op, want := range c {
+			if !compare(v, op, want) { return false }
+		}
+	}
+	return true
+}
+
+// describe renders the conditions in a stable order, e.g. "author.bot gt 0.35".
+func (r Rule) describe() string {
+	var parts []string
+	for field, c := range r.When {
+		for op, v := range c { parts = append(parts, fmt.Sprintf("%s %s %v", field, op, v)) }
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, " and ")
+}
+
+func compare(v any, op string, want any) bool {
+	switch op {
+	case "in", "not_in":
+		found := false
+		for _, w := range want.([]any) {
+			if equal(v, w) { found = true; break }
+		}
+		return found == (op == "in")
+	case "contains", "not_contains":
+		s, _ := v.(string)
+		w := fmt.Sprint(want)
+		return strings.Contains(strings.ToLower(s), strings.ToLower(w)) == (op == "contains")
+	case "eq":
+		return equal(v, want)
+	case "ne":
+		return !equal(v, want)
+	}
+	a, ok1 := number(v)
+	b, ok2 := number(want)
+	if !ok1 || !ok2 { return false }
+	switch op {
+	case "gt":
+		return a > b
+	case "gte":
+		return a >= b
+	case "lt":
+		return a < b
+	case "lte":
+		return a <= b
+	}
+	return false
+}
+
+func equal(v, want any) bool {
+	if a, ok := number(v); ok {
+		b, ok := number(want)
+		return ok && a == b
+	}
+	if a, ok := v.(bool); ok {
+		b, ok := want.(bool)
+		return ok && a == b
+	}
+	return strings.EqualFold(fmt.Sprint(v), fmt.Sprint(want))
+}
+
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// Default builds the built-in policy from the config filters: skip non-organic tweets,
+// likely bots and other languages, and defer replies during quiet hours or when budgets
+// or cooldowns say no.
+func Default(cfg config.Config) *Policy {
+	f := cfg.Filters
+	p := &Policy{Default: Allow}
+	if f.MinOrganicScore > 0 {
+		p.Rules = append(p.Rules, Rule{Name: "organic", Action: Deny, When: map[string]Condition{"tweet.organic": {"lt": f.MinOrganicScore}}})
+	}
+	if f.MaxBotLikelihood > 0 {
+		p.Rules = append(p.Rules, Rule{Name: "bots", Action: Deny, When: map[string]Condition{"author.bot": {"gt": f.MaxBotLikelihood}}})
+	}
+	if len(f.Languages) > 0 {
+		langs := make([]any, 0, len(f.Languages)+1)
+		for _, l := range f.Languages { langs = append(langs, l) }
+		langs = append(langs, "") // unknown language passes
+		p.Rules = append(p.Rules, Rule{Name: "language", Action: Deny, When: map[string]Condition{"tweet.lang": {"not_in": langs}}})
+	}
+	p.Rules = append(p.Rules,
+		Rule{Name: "quiet-replies", Action: Defer, When: map[string]Condition{"time.quiet": {"eq": true}, "action": {"eq": "reply"}}},
+		Rule{Name: "model-gate", Action: Deny, When: map[string]Condition{"model.pass": {"eq": false}}},
+		Rule{Name: "budget", Action: Defer, When: map[string]Condition{"budget.allowed": {"eq": false}}},
+		Rule{Name: "cooldown", Action: Defer, When: map[string]Condition{"cooldown.allowed": {"eq": false}}},
+	)
+	return p
+}
+
+// ForConfig loads engagement.policy when set, otherwise the built-in policy.
+func ForConfig(cfg config.Config) (*Policy, error) {
+	if cfg.Engagement.Policy == "" { return Default(cfg), nil }
+	p, err := Load(cfg.Engagement.Policy)
+	if err != nil { return nil, fmt.Errorf("engagement.policy: %w", err) }
+	return p, nil
+}
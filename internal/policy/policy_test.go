@@ -0,0 +1,90 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"starseed/internal/config"
+	"starseed/internal/model"
+)
+
+const sample = `
+default: allow
+rules:
+  - name: bots
+    action: deny
+    when:
+      author.bot: {gt: 0.35}
+  - name: quiet-likes
+    action: allow
+    when:
+      time.quiet: true
+      action: like
+  - name: quiet
+    action: defer
+    reason: nothing but likes at night
+    when:
+      time.quiet: true
+  - name: weekend-links
+    action: deny
+    when:
+      time.weekday: [sat, sun]
+      tweet.text: {contains: "http"}
+`
+
+func TestEvaluateFirstMatchWins(t *testing.T) {
+	p, err := Parse([]byte(sample))
+	if err != nil { t.Fatal(err) }
+	night := time.Date(2025, 1, 4, 2, 0, 0, 0, time.UTC) // Saturday
+	bot := model.User{Username: "spam", FollowersCount: 3, FollowingCount: 900, DefaultImage: true}
+	human := model.User{Username: "ann", Description: "Go developer", FollowersCount: 900}
+	cases := []struct {
+		name string
+		f    Facts
+		want Result
+	}{
+		{"bot", Facts{"action": "reply"}.WithAuthor(bot, nil).WithTime(night, nil, true), Result{Deny, "bots", "author.bot gt 0.35"}},
+		{"like at night", Facts{"action": "like"}.WithAuthor(human, nil).WithTime(night, nil, true), Result{Allow, "quiet-likes", "action eq like and time.quiet eq true"}},
+		{"reply at night", Facts{"action": "reply"}.WithAuthor(human, nil).WithTime(night, nil, true), Result{Defer, "quiet", "nothing but likes at night"}},
+		{"weekend link", Facts{"action": "reply"}.WithTweet(model.Tweet{Text: "see HTTPS://x.y"}, night).WithTime(night.Add(12*time.Hour), nil, false), Result{Deny, "weekend-links", "time.weekday in [sat sun] and tweet.text contains http"}},
+		{"missing facts never match", Facts{"action": "reply"}, Result{Allow, "default", "no rule matched"}},
+	}
+	for _, c := range cases {
+		if got := p.Evaluate(c.f); got != c.want { t.Errorf("%s: got %+v want %+v", c.name, got, c.want) }
+	}
+}
+
+func TestParseRejectsBadRules(t *testing.T) {
+	bad := map[string]string{
+		"unknown field":    "rules: [{name: x, action: deny, when: {author.age: 3}}]",
+		"unknown action":   "rules: [{name: x, action: block, when: {action: reply}}]",
+		"unknown operator": "rules: [{name: x, action: deny, when: {author.bot: {above: 0.3}}}]",
+		"numeric op":       "rules: [{name: x, action: deny, when: {tweet.lang: {gt: 1}}}]",
+		"no conditions":    "rules: [{name: x, action: deny}]",
+		"bad default":      "default: maybe",
+	}
+	for name, y := range bad {
+		if _, err := Parse([]byte(y)); err == nil { t.Errorf("%s: expected error", name) }
+	}
+	if _, err := Parse([]byte("rules: [{name: x, action: deny, when: {author.bot: {gte: 0.5}}}]")); err != nil { t.Fatalf("valid policy rejected: %v", err) }
+}
+
+func TestDefaultPolicyFromFilters(t *testing.T) {
+	p := Default(config.Default())
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	organic := model.Tweet{Text: "thoughtful post", Language: "en", LikeCount: 5, ReplyCount: 2}
+	check := func(f Facts, decision, rule string) {
+		t.Helper()
+		if got := p.Evaluate(f); got.Decision != decision || got.Rule != rule { t.Fatalf("got %+v, want %s by %s", got, decision, rule) }
+	}
+	check(Facts{"action": "reply"}.WithTweet(organic, now), Allow, "default")
+	check(Facts{"action": "reply"}.WithTweet(model.Tweet{Text: "giveaway! click here", HasLink: true}, now), Deny, "organic")
+	fr := organic
+	fr.Language = "fr"
+	check(Facts{"action": "reply"}.WithTweet(fr, now), Deny, "language")
+	check(Facts{"action": "reply", "model.pass": false}.WithTweet(organic, now), Deny, "model-gate")
+	check(Facts{"action": "reply", "cooldown.allowed": false}.WithTweet(organic, now), Defer, "cooldown")
+	check(Facts{"action": "like"}.WithTweet(organic, now).WithTime(now, nil, true), Allow, "default")
+	if !strings.Contains(p.Evaluate(Facts{"action": "reply"}.WithTime(now, nil, true)).Rule, "quiet") { t.Fatalf("replies should defer in quiet hours") }
+}
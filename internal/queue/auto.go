@@ -0,0 +1,208 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"starseed/internal/audit"
+	"starseed/internal/config"
+	"starseed/internal/engage"
+	"starseed/internal/logging"
+	"starseed/internal/policy"
+	"starseed/internal/schedule"
+	"starseed/internal/store/sqlitevec"
+)
+
+// KillSwitchKey is the cursor holding the DB kill switch ("on" halts automated posting).
+const KillSwitchKey = "auto_kill_switch"
+
+// Decision outcomes recorded by Auto and the engage pipeline.
+const (
+	OutcomeQueued   = "queued"
+	// the tweet already had a draft, so nothing new was queued
+	OutcomeDuplicate = "duplicate"
+	OutcomeRejected = "rejected"
+	OutcomePosted  = "posted"
+	OutcomeDryRun  = "dry_run"
+	OutcomeSkipped = "skipped"
+	OutcomeDeferred = "deferred"
+	OutcomeHalted  = "halted"
+	OutcomeError   = "error"
+)
+
+// SetKillSwitch turns the DB kill switch on or off.
+func SetKillSwitch(ctx context.Context, db *sqlitevec.DB, on bool) error {
+	v := "off"
+	if on { v = "on" }
+	return db.SaveCursor(ctx, KillSwitchKey, v)
+}
+
+// KillSwitch reports whether automated posting is halted, by the DB flag or by file.
+func KillSwitch(ctx context.Context, db *sqlitevec.DB, file string) (bool, string) {
+	if v, err := db.LoadCursor(ctx, KillSwitchKey); err == nil && v == "on" { return true, "kill switch set in database" }
+	if file != "" {
+		if _, err := os.Stat(file); err == nil { return true, "kill switch file " + file + " present" }
+	}
+	return false, ""
+}
+
+// AutoOptions are the inputs automated posting is gated on.
+type AutoOptions struct {
+	Engagement config.EngagementConfig
+	// Policy decides per draft (policy.Default when nil)
+	Policy *policy.Policy
+	// Quiet reports quiet hours/blackouts (schedule.Rules.IsQuiet) in Location
+	Quiet    func(time.Time) bool
+	Location *time.Location
+	// Model prediction for the current window and the calibrated threshold (HasModel false
+	// when no model is available). Auto refuses to post without a model and threshold.
+	HasModel   bool
+	Prediction float32
+	Threshold  float32
+	DryRun     bool
+	// Recorder receives every decision (table only when nil)
+	Recorder *audit.Recorder
+}
+
+// DraftFacts describes a queued draft for the policy engine, using the tweet and author
+// scores captured when it was enqueued.
+func DraftFacts(dr sqlitevec.Draft, now time.Time) policy.Facts {
+	var meta Meta
+	_ = json.Unmarshal([]byte(dr.Meta), &meta)
+	f := policy.Facts{
+		"action":        "reply",
+		"tweet.text":    dr.TweetText,
+		"tweet.organic": meta.Organic,
+		"tweet.lang":    meta.Lang,
+		"tweet.is_reply": meta.ReplyTo != "",
+		"tweet.likes":    meta.Likes,
+		"tweet.replies":  meta.Replies,
+		"tweet.retweets": meta.Retweets,
+		"tweet.has_link": meta.HasLink,
+		"draft.score":   dr.Score,
+	}
+	if !dr.TweetCreated.IsZero() && dr.TweetCreated.Unix() > 0 { f["tweet.age_hours"] = now.Sub(dr.TweetCreated).Hours() }
+	if meta.Author != "" {
+		f["author.username"], f["author.score"], f["author.bot"] = meta.Author, meta.AuthorScore, meta.Bot
+		f["author.followers"], f["author.following"], f["author.verified"] = meta.Followers, meta.Following, meta.Verified
+	}
+	return f
+}
+
+// Auto posts due drafts without review. The kill switch and a missing model halt
+// everything; pending drafts need minScore and a prediction at or above the threshold;
+// nothing is posted in quiet hours; the policy (filters, ...) may deny or defer each draft
+// further; cooldowns (deferred) and the global and reply budgets are enforced whatever the
+// policy says. Each decision is recorded through opts.Recorder and returned; with DryRun
+// nothing is posted but the decisions show exactly what would have been.
+func Auto(ctx context.Context, db *sqlitevec.DB, p Poster, opts AutoOptions, now time.Time) ([]sqlitevec.Decision, error) {
+	cfg := opts.Engagement
+	var out []sqlitevec.Decision
+	rules, err := schedule.Compile(cfg)
+	if err != nil { return nil, err }
+	budget := engage.NewBudget(db, cfg)
+	budget.DryRun = opts.DryRun
+	rec := opts.Recorder
+	if rec == nil { rec = &audit.Recorder{DB: db} }
+	record := func(outcome, reason string, dr *sqlitevec.Draft) {
+		e := audit.Entry{Kind: "auto_post", Outcome: outcome, Reason: reason}
+		detail := map[string]any{"dry_run": opts.DryRun}
+		if opts.HasModel { detail["prediction"], detail["threshold"] = opts.Prediction, opts.Threshold }
+		if dr != nil {
+			var meta Meta
+			_ = json.Unmarshal([]byte(dr.Meta), &meta)
+			e.DraftID, e.TweetID, e.AuthorID, e.Author = dr.ID, dr.TweetID, dr.AuthorID, meta.Author
+			detail["text"], detail["score"], detail["author_score"], detail["scheduled"], detail["state"], detail["posted_id"] = dr.Text, dr.Score, meta.AuthorScore, dr.Scheduled, dr.State, dr.PostedID
+		}
+		if counts, err := budget.Counts(ctx, "reply", now); err == nil { detail["budget"] = counts }
+		e.Facts = detail
+		dc, err := rec.Record(ctx, e, now)
+		if err != nil { logging.Error("audit_record", map[string]any{"err": err.Error()}) }
+		logging.Info("auto_decision", map[string]any{"outcome": outcome, "reason": reason, "draft": dc.DraftID, "tweet_id": dc.TweetID, "detail": detail})
+		out = append(out, dc)
+	}
+	if !opts.DryRun {
+		if _, err := Expire(ctx, db, time.Duration(cfg.DraftTTLHours)*time.Hour, now); err != nil { return out, err }
+	}
+	if on, why := KillSwitch(ctx, db, cfg.Auto.KillSwitchFile); on {
+		record(OutcomeHalted, why, nil)
+		return out, nil
+	}
+	if !opts.HasModel || opts.Threshold <= 0 {
+		record(OutcomeHalted, "no calibrated model: auto posting needs a prediction and threshold", nil)
+		return out, nil
+	}
+	pol := opts.Policy
+	if pol == nil { pol = policy.Default(config.Config{Engagement: cfg}) }
+	quiet := opts.Quiet != nil && opts.Quiet(now)
+	drafts, err := db.ListDrafts(ctx, []string{Pending, Approved}, 0)
+	if err != nil { return out, err }
+	for i := range drafts {
+		dr := &drafts[i]
+		if dr.Scheduled.After(now) { continue }
+		if dr.State == Pending && dr.Score < cfg.Auto.MinScore {
+			record(OutcomeSkipped, fmt.Sprintf("score %.2f below minScore %.2f; left for review", dr.Score, cfg.Auto.MinScore), dr)
+			continue
+		}
+		if opts.Prediction < opts.Threshold {
+			record(OutcomeSkipped, fmt.Sprintf("model prediction %.3f below threshold %.3f", opts.Prediction, opts.Threshold), dr)
+			continue
+		}
+		if quiet {
+			record(OutcomeDeferred, "quiet hours", dr)
+			continue
+		}
+		target := DraftTarget(*dr)
+		cv, err := budget.CheckTarget(ctx, "reply", target, now)
+		if err != nil { return out, err }
+		v, err := budget.Check(ctx, "reply", now)
+		if err != nil { return out, err }
+		f := DraftFacts(*dr, now).WithTime(now, opts.Location, quiet)
+		f["budget.allowed"], f["cooldown.allowed"] = v.Allowed, cv.Allowed
+		f["prediction"], f["model.pass"] = float64(opts.Prediction), true
+		res := pol.Evaluate(f)
+		why := "policy " + res.Rule + ": " + res.Reason
+		if res.Decision == policy.Deny {
+			record(OutcomeSkipped, why, dr)
+			continue
+		}
+		if res.Decision == policy.Defer && cv.Allowed && v.Allowed {
+			record(OutcomeDeferred, why, dr)
+			continue
+		}
+		if !cv.Allowed {
+			// Push the draft past the cooldown; Expire drops it if the tweet goes stale first
+			when, err := deferTo(ctx, budget, rules, target, cv.Next)
+			if err != nil { return out, err }
+			reason := cv.Reason + "; deferred to " + when.Format(time.RFC3339)
+			if !opts.DryRun {
+				moved, err := transition(ctx, db, dr.ID, now, []string{Pending, Approved}, func(d *sqlitevec.Draft) { d.Scheduled = when })
+				if err != nil { return out, err }
+				dr = &moved
+			}
+			record(OutcomeDeferred, reason, dr)
+			continue
+		}
+		if !v.Allowed {
+			record(OutcomeHalted, budgetReason(v), dr)
+			break
+		}
+		if opts.DryRun {
+			_ = budget.RecordTarget(ctx, "reply", target, now)
+			record(OutcomeDryRun, "all gates passed", dr)
+			continue
+		}
+		posted, err := post(ctx, db, p, budget, *dr, now)
+		if errors.Is(err, errPost) {
+			record(OutcomeError, err.Error(), dr)
+			continue
+		}
+		if err != nil { return out, err }
+		record(OutcomePosted, "all gates passed", &posted)
+	}
+	return out, nil
+}
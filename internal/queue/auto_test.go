@@ -0,0 +1,137 @@
+package queue
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"starseed/internal/config"
+	"starseed/internal/model"
+	"starseed/internal/policy"
+	"starseed/internal/recommend"
+	"starseed/internal/store/sqlitevec"
+	"starseed/internal/suggest"
+)
+
+func autoFixture(t *testing.T, now time.Time) *sqlitevec.DB {
+	t.Helper()
+	db, err := sqlitevec.Open(":memory:")
+	if err != nil { t.Fatal(err) }
+	sugs := []suggest.Suggestion{
+		{Tweet: model.Tweet{ID: "1", AuthorID: "alice", CreatedAt: now}, Text: "good one", Score: 0.9, When: now},
+		{Tweet: model.Tweet{ID: "2", AuthorID: "alice", CreatedAt: now}, Text: "same author", Score: 0.8, When: now},
+		{Tweet: model.Tweet{ID: "3", AuthorID: "bob", CreatedAt: now}, Text: "weak", Score: 0.3, When: now},
+		{Tweet: model.Tweet{ID: "4", AuthorID: "carol", CreatedAt: now}, Text: "later", Score: 0.9, When: now.Add(time.Hour)},
+		{Tweet: model.Tweet{ID: "5", AuthorID: "dave", CreatedAt: now}, Text: "fine", Score: 0.7, When: now},
+	}
+	if _, err := Enqueue(context.Background(), db, sugs, nil, now); err != nil { t.Fatal(err) }
+	return db
+}
+
+func outcomes(ds []sqlitevec.Decision) map[string]string {
+	out := map[string]string{}
+	for _, d := range ds { out[d.TweetID] = d.Outcome }
+	return out
+}
+
+func TestAutoGatesAndDryRun(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	db := autoFixture(t, now)
+	defer db.Close()
+	ctx := context.Background()
+	cfg := config.EngagementConfig{MaxPerHour: 5, DraftTTLHours: 24, Auto: config.AutoConfig{MinScore: 0.6},
+		Cooldowns: []config.CooldownRule{{Scope: "author", Type: "reply", Max: 1, WindowHours: 24}}}
+	p := &fakePoster{}
+	opts := AutoOptions{Engagement: cfg, HasModel: true, Prediction: 0.6, Threshold: 0.4, DryRun: true}
+	ds, err := Auto(ctx, db, p, opts, now)
+	if err != nil { t.Fatal(err) }
+	got := outcomes(ds)
+	want := map[string]string{"1": OutcomeDryRun, "2": OutcomeDeferred, "3": OutcomeSkipped, "5": OutcomeDryRun}
+	for k, v := range want {
+		if got[k] != v { t.Fatalf("tweet %s: got %q want %q (%v)", k, got[k], v, got) }
+	}
+	if _, ok := got["4"]; ok || len(p.posted) != 0 { t.Fatalf("dry run must not post or consider future drafts: %v %v", got, p.posted) }
+	// Live run posts the same set and records the audit trail
+	opts.DryRun = false
+	ds, _ = Auto(ctx, db, p, opts, now)
+	if len(p.posted) != 2 || p.posted[0] != "1" || p.posted[1] != "5" { t.Fatalf("posted %v", p.posted) }
+	if d, _ := db.GetDraft(ctx, 3); d.State != Pending { t.Fatalf("low score draft should stay pending, got %s", d.State) }
+	if d, _ := db.GetDraft(ctx, 2); !d.Scheduled.Equal(now.Add(24 * time.Hour)) { t.Fatalf("second reply to alice should be deferred a day, got %s", d.Scheduled) }
+	all, _ := db.ListDecisions(ctx, sqlitevec.DecisionFilter{Since: now.Add(-time.Minute)})
+	if len(all) != len(ds)*2 { t.Fatalf("expected every decision recorded, got %d", len(all)) }
+	// An hour later the daily budget is spent
+	cfg.MaxPerDay = 2
+	opts.Engagement = cfg
+	ds, _ = Auto(ctx, db, p, opts, now.Add(time.Hour))
+	got = outcomes(ds)
+	if _, ok := got["2"]; ok || got["4"] != OutcomeHalted { t.Fatalf("unexpected %v", got) }
+}
+
+func TestAutoHaltsOnKillSwitch(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	db := autoFixture(t, now)
+	defer db.Close()
+	ctx := context.Background()
+	file := filepath.Join(t.TempDir(), "stop")
+	cfg := config.EngagementConfig{Auto: config.AutoConfig{MinScore: 0.6, KillSwitchFile: file}}
+	p := &fakePoster{}
+	check := func(reason string) {
+		t.Helper()
+		ds, err := Auto(ctx, db, p, AutoOptions{Engagement: cfg}, now)
+		if err != nil || len(ds) != 1 || ds[0].Outcome != OutcomeHalted || ds[0].Reason != reason { t.Fatalf("want halt %q, got %+v %v", reason, ds, err) }
+	}
+	_ = os.WriteFile(file, nil, 0o644)
+	check("kill switch file " + file + " present")
+	_ = os.Remove(file)
+	_ = SetKillSwitch(ctx, db, true)
+	check("kill switch set in database")
+	if len(p.posted) != 0 { t.Fatalf("nothing should be posted, got %v", p.posted) }
+}
+
+func TestAutoAppliesPolicy(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	db := autoFixture(t, now)
+	defer db.Close()
+	ctx := context.Background()
+	cfg := config.EngagementConfig{Auto: config.AutoConfig{MinScore: 0.6}}
+	p := &fakePoster{}
+	// Quiet hours and the model threshold hold even under a policy that allows everything
+	allow, err := policy.Parse([]byte("default: allow\n"))
+	if err != nil { t.Fatal(err) }
+	ds, _ := Auto(ctx, db, p, AutoOptions{Engagement: cfg, Policy: allow, Quiet: func(time.Time) bool { return true }, HasModel: true, Prediction: 0.6, Threshold: 0.4}, now)
+	if got := outcomes(ds); got["1"] != OutcomeDeferred || ds[0].Reason != "quiet hours" { t.Fatalf("unexpected %+v", ds) }
+	ds, _ = Auto(ctx, db, p, AutoOptions{Engagement: cfg, HasModel: true, Prediction: 0.1, Threshold: 0.4}, now)
+	if got := outcomes(ds); got["1"] != OutcomeSkipped || got["5"] != OutcomeSkipped { t.Fatalf("expected model gate to skip, got %v", got) }
+	// Without a model or threshold nothing is posted, whatever the policy allows
+	for _, opts := range []AutoOptions{{Engagement: cfg, Policy: allow}, {Engagement: cfg, Policy: allow, HasModel: true, Prediction: 0.9}} {
+		ds, _ = Auto(ctx, db, p, opts, now)
+		if len(ds) != 1 || ds[0].Outcome != OutcomeHalted { t.Fatalf("expected auto mode refused without a model, got %+v", ds) }
+	}
+	ds, _ = Auto(ctx, db, p, AutoOptions{Engagement: cfg, Policy: allow, HasModel: true, Prediction: 0.1, Threshold: 0.4, DryRun: true}, now)
+	if got := outcomes(ds); got["1"] != OutcomeSkipped { t.Fatalf("model gate must hold under a permissive policy, got %v", got) }
+	// A custom policy: only the strongest drafts
+	pol, err := policy.Parse([]byte("default: deny\nrules:\n  - name: strong\n    action: allow\n    when:\n      draft.score: {gte: 0.85}\n"))
+	if err != nil { t.Fatal(err) }
+	ds, _ = Auto(ctx, db, p, AutoOptions{Engagement: cfg, Policy: pol, HasModel: true, Prediction: 0.6, Threshold: 0.4, DryRun: true}, now)
+	if got := outcomes(ds); got["1"] != OutcomeDryRun || got["5"] != OutcomeSkipped { t.Fatalf("custom policy not applied: %v", got) }
+	if len(p.posted) != 0 { t.Fatalf("nothing should be posted, got %v", p.posted) }
+}
+
+func TestDraftFactsCarryTweetAndAuthorProfile(t *testing.T) {
+	db, err := sqlitevec.Open(":memory:")
+	if err != nil { t.Fatal(err) }
+	defer db.Close()
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tw := model.Tweet{ID: "1", AuthorID: "a1", Text: "see link", LikeCount: 12, ReplyCount: 3, RetweetCount: 2, HasLink: true, CreatedAt: now.Add(-2 * time.Hour)}
+	authors := map[string]recommend.AccountRecommendation{"a1": {User: model.User{ID: "a1", Username: "alice", FollowersCount: 900, FollowingCount: 150, Verified: true}}}
+	if _, err := Enqueue(ctx, db, []suggest.Suggestion{{Tweet: tw, Text: "reply"}}, authors, now); err != nil { t.Fatal(err) }
+	d, _ := db.GetDraft(ctx, 1)
+	f := DraftFacts(d, now)
+	want := policy.Facts{"tweet.likes": 12, "tweet.replies": 3, "tweet.retweets": 2, "tweet.has_link": true, "author.followers": 900, "author.following": 150, "author.verified": true}
+	for k, v := range want {
+		if f[k] != v { t.Errorf("%s: got %v want %v", k, f[k], v) }
+	}
+}
@@ -0,0 +1,246 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"starseed/internal/config"
+	"starseed/internal/engage"
+	"starseed/internal/logging"
+	"starseed/internal/model"
+	"starseed/internal/recommend"
+	"starseed/internal/schedule"
+	"starseed/internal/store/sqlitevec"
+	"starseed/internal/suggest"
+)
+
+// Draft states.
+const (
+	Pending  = "pending"
+	Approved = "approved"
+	Rejected = "rejected"
+	Posted   = "posted"
+	Expired  = "expired"
+)
+
+// ErrState is returned when an action is not allowed in the draft's current state.
+var ErrState = errors.New("invalid state transition")
+
+// Poster publishes replies (xclient.HTTPClient in production).
+type Poster interface {
+	PostReply(ctx context.Context, inReplyTo, text string) (string, error)
+}
+
+// Meta is stored alongside each draft for reviewers.
+type Meta struct {
+	Alternates     []suggest.Candidate `json:"alternates,omitempty"`
+	ConversationID string              `json:"conversation_id,omitempty"`
+	Author         string              `json:"author,omitempty"`
+	AuthorScore    float64             `json:"author_score,omitempty"`
+	Bot            float64             `json:"bot,omitempty"`
+	Organic        float64             `json:"organic,omitempty"`
+	Lang           string              `json:"lang,omitempty"`
+	ReplyTo        string              `json:"reply_to,omitempty"`
+	Window         float32             `json:"window,omitempty"`
+	// Tweet metrics and author profile at enqueue time, for the policy engine
+	Likes     int  `json:"likes,omitempty"`
+	Replies   int  `json:"replies,omitempty"`
+	Retweets  int  `json:"retweets,omitempty"`
+	HasLink   bool `json:"has_link,omitempty"`
+	Followers int  `json:"followers,omitempty"`
+	Following int  `json:"following,omitempty"`
+	Verified  bool `json:"verified,omitempty"`
+}
+
+// Enqueued is the draft a suggestion was stored as; Created is false when the tweet
+// already had a draft (DraftID is then the existing one).
+type Enqueued struct {
+	DraftID int64
+	Created bool
+}
+
+// Enqueue stores suggestions as pending drafts; tweets that already have a draft are
+// skipped. authors (by ID, may be nil) supply the author handle and score shown to
+// reviewers. It returns one Enqueued per suggestion stored, in order.
+func Enqueue(ctx context.Context, db *sqlitevec.DB, sugs []suggest.Suggestion, authors map[string]recommend.AccountRecommendation, now time.Time) ([]Enqueued, error) {
+	out := make([]Enqueued, 0, len(sugs))
+	for _, s := range sugs {
+		m := Meta{Alternates: s.Alternates, ConversationID: s.Tweet.ConversationID, Organic: model.OrganicContentScore(s.Tweet), Lang: s.Tweet.Language, ReplyTo: s.Tweet.ReplyToID, Window: s.Window,
+			Likes: s.Tweet.LikeCount, Replies: s.Tweet.ReplyCount, Retweets: s.Tweet.RetweetCount, HasLink: s.Tweet.HasLink}
+		if a, ok := authors[s.Tweet.AuthorID]; ok {
+			m.Author, m.AuthorScore, m.Bot = a.User.Username, a.FinalScore, a.BotLikelihood
+			m.Followers, m.Following, m.Verified = a.User.FollowersCount, a.User.FollowingCount, a.User.Verified
+		}
+		meta, _ := json.Marshal(m)
+		id, created, err := db.PutDraft(ctx, sqlitevec.Draft{
+			Created: now, TweetID: s.Tweet.ID, AuthorID: s.Tweet.AuthorID, TweetText: s.Tweet.Text, TweetCreated: s.Tweet.CreatedAt,
+			Text: s.Text, Scheduled: s.When, State: Pending, Score: s.Score, Why: s.Why, Meta: string(meta),
+		})
+		if err != nil { return out, err }
+		out = append(out, Enqueued{DraftID: id, Created: created})
+	}
+	return out, nil
+}
+
+// DraftContext rebuilds what the validator checks an edited draft against: the target
+// tweet and the author handle captured in Meta (so mentioning the author is allowed).
+func DraftContext(dr sqlitevec.Draft) suggest.DraftContext {
+	var meta Meta
+	_ = json.Unmarshal([]byte(dr.Meta), &meta)
+	tw := model.Tweet{ID: dr.TweetID, AuthorID: dr.AuthorID, Text: dr.TweetText, CreatedAt: dr.TweetCreated, ConversationID: meta.ConversationID, ReplyToID: meta.ReplyTo}
+	return suggest.DraftContext{Tweet: tw, Author: model.User{ID: dr.AuthorID, Username: meta.Author}}
+}
+
+// transition loads a draft, checks it is in one of from, applies change and saves it.
+func transition(ctx context.Context, db *sqlitevec.DB, id int64, now time.Time, from []string, change func(*sqlitevec.Draft)) (sqlitevec.Draft, error) {
+	dr, err := db.GetDraft(ctx, id)
+	if err != nil { return dr, err }
+	ok := false
+	for _, f := range from {
+		if dr.State == f { ok = true }
+	}
+	if !ok { return dr, fmt.Errorf("%w: draft %d is %s", ErrState, id, dr.State) }
+	prev := dr.State
+	change(&dr)
+	if err := db.UpdateDraft(ctx, dr, prev, now); err != nil {
+		if errors.Is(err, sqlitevec.ErrNotFound) { return dr, fmt.Errorf("%w: draft %d changed concurrently", ErrState, id) }
+		return dr, err
+	}
+	return dr, nil
+}
+
+// Approve marks a pending draft for posting, optionally rescheduling it.
+func Approve(ctx context.Context, db *sqlitevec.DB, id int64, at time.Time, now time.Time) (sqlitevec.Draft, error) {
+	return transition(ctx, db, id, now, []string{Pending}, func(d *sqlitevec.Draft) {
+		d.State = Approved
+		if !at.IsZero() { d.Scheduled = at }
+	})
+}
+
+// Edit replaces the text of a pending or approved draft; the state is kept.
+func Edit(ctx context.Context, db *sqlitevec.DB, id int64, text string, now time.Time) (sqlitevec.Draft, error) {
+	if text == "" { return sqlitevec.Draft{}, errors.New("empty text") }
+	return transition(ctx, db, id, now, []string{Pending, Approved}, func(d *sqlitevec.Draft) { d.Text = text })
+}
+
+// Reject drops a pending or approved draft with an optional reason.
+func Reject(ctx context.Context, db *sqlitevec.DB, id int64, reason string, now time.Time) (sqlitevec.Draft, error) {
+	return transition(ctx, db, id, now, []string{Pending, Approved}, func(d *sqlitevec.Draft) {
+		d.State, d.Note = Rejected, reason
+	})
+}
+
+// Expire marks drafts whose target tweet is older than ttl as expired.
+func Expire(ctx context.Context, db *sqlitevec.DB, ttl time.Duration, now time.Time) (int, error) {
+	if ttl <= 0 { ttl = 24 * time.Hour }
+	return db.ExpireDrafts(ctx, now.Add(-ttl), now)
+}
+
+// PostResult describes what PostDue did with one draft.
+type PostResult struct {
+	Draft  sqlitevec.Draft
+	Posted bool
+	Reason string // why it was not posted
+}
+
+// PostDue posts approved drafts scheduled at or before now, deferring drafts that would
+// break a cooldown and stopping at the first draft the global or reply budgets refuse.
+// Nothing is posted during quiet hours. With dryRun nothing is posted or recorded.
+func PostDue(ctx context.Context, db *sqlitevec.DB, p Poster, cfg config.EngagementConfig, now time.Time, dryRun bool) ([]PostResult, error) {
+	rules, err := schedule.Compile(cfg)
+	if err != nil { return nil, err }
+	ttl := time.Duration(cfg.DraftTTLHours) * time.Hour
+	if !dryRun {
+		if _, err := Expire(ctx, db, ttl, now); err != nil { return nil, err }
+	}
+	drafts, err := db.ListDrafts(ctx, []string{Approved}, 0)
+	if err != nil { return nil, err }
+	budget := engage.NewBudget(db, cfg)
+	budget.DryRun = dryRun
+	var out []PostResult
+	for _, dr := range drafts {
+		if dr.Scheduled.After(now) { continue }
+		if rules.IsQuiet(now) {
+			out = append(out, PostResult{Draft: dr, Reason: "quiet hours; due again at " + rules.Next(now).Format(time.RFC3339)})
+			continue
+		}
+		target := DraftTarget(dr)
+		cv, err := budget.CheckTarget(ctx, "reply", target, now)
+		if err != nil { return out, err }
+		if !cv.Allowed {
+			when, err := deferTo(ctx, budget, rules, target, cv.Next)
+			if err != nil { return out, err }
+			if !dryRun {
+				if dr, err = transition(ctx, db, dr.ID, now, []string{Approved}, func(d *sqlitevec.Draft) { d.Scheduled = when }); err != nil { return out, err }
+			}
+			out = append(out, PostResult{Draft: dr, Reason: cv.Reason + "; deferred to " + when.Format(time.RFC3339)})
+			continue
+		}
+		v, err := budget.Check(ctx, "reply", now)
+		if err != nil { return out, err }
+		if !v.Allowed {
+			out = append(out, PostResult{Draft: dr, Reason: budgetReason(v)})
+			break
+		}
+		if dryRun {
+			_ = budget.RecordTarget(ctx, "reply", target, now)
+			out = append(out, PostResult{Draft: dr, Reason: "dry run"})
+			continue
+		}
+		posted, err := post(ctx, db, p, budget, dr, now)
+		if errors.Is(err, errPost) {
+			// Keep the draft approved so it is retried on the next run.
+			out = append(out, PostResult{Draft: dr, Reason: err.Error()})
+			continue
+		}
+		if err != nil { return out, err }
+		out = append(out, PostResult{Draft: posted, Posted: true})
+	}
+	return out, nil
+}
+
+var errPost = errors.New("post failed")
+
+// DraftTarget is the author and conversation a draft replies into.
+func DraftTarget(dr sqlitevec.Draft) engage.Target {
+	var meta Meta
+	_ = json.Unmarshal([]byte(dr.Meta), &meta)
+	conv := meta.ConversationID
+	if conv == "" { conv = dr.TweetID }
+	return engage.Target{UserID: dr.AuthorID, ConversationID: conv}
+}
+
+// deferTo picks the time a draft refused by a cooldown is moved to: the first time from
+// next that is outside quiet hours and fits the cooldowns and budgets, looking a day ahead.
+func deferTo(ctx context.Context, budget *engage.Budget, rules *schedule.Rules, target engage.Target, next time.Time) (time.Time, error) {
+	when, _, _, err := suggest.Reschedule(ctx, budget, rules, target, next, next.Add(24*time.Hour))
+	return when, err
+}
+
+// budgetReason describes a budget refusal with the time the next reply fits.
+func budgetReason(v engage.Verdict) string {
+	return fmt.Sprintf("budget: %s; next allowed at %s", v.Reason, v.Next.Format(time.RFC3339))
+}
+
+// post publishes a pending or approved draft, marks it posted and records the reply
+// action plus an out_reply event so novelty and thread checks see it. Write API
+// failures wrap errPost and leave the draft unchanged.
+func post(ctx context.Context, db *sqlitevec.DB, p Poster, budget *engage.Budget, dr sqlitevec.Draft, now time.Time) (sqlitevec.Draft, error) {
+	id, err := p.PostReply(ctx, dr.TweetID, dr.Text)
+	if err != nil {
+		logging.Error("queue_post", map[string]any{"draft": dr.ID, "err": err.Error()})
+		return dr, fmt.Errorf("%w: %v", errPost, err)
+	}
+	dr, err = transition(ctx, db, dr.ID, now, []string{Pending, Approved}, func(d *sqlitevec.Draft) {
+		d.State, d.PostedID, d.Note = Posted, id, ""
+	})
+	if err != nil { return dr, err }
+	_ = budget.RecordTarget(ctx, "reply", DraftTarget(dr), now)
+	var meta Meta
+	_ = json.Unmarshal([]byte(dr.Meta), &meta)
+	_ = db.PutEventRef(ctx, now, "out_reply", id, map[string]any{"tweet_id": id, "text": dr.Text, "conversation_id": meta.ConversationID, "reply_to_id": dr.TweetID, "reply_to_user_id": dr.AuthorID})
+	return dr, nil
+}
@@ -0,0 +1,154 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"starseed/internal/config"
+	"starseed/internal/model"
+	"starseed/internal/recommend"
+	"starseed/internal/store/sqlitevec"
+	"starseed/internal/suggest"
+)
+
+type fakePoster struct {
+	posted []string
+	fail   bool
+}
+
+func (f *fakePoster) PostReply(ctx context.Context, inReplyTo, text string) (string, error) {
+	if f.fail { return "", errors.New("boom") }
+	f.posted = append(f.posted, inReplyTo)
+	return "r" + inReplyTo, nil
+}
+
+func seed(t *testing.T, db *sqlitevec.DB, now time.Time, ids ...string) {
+	t.Helper()
+	var sugs []suggest.Suggestion
+	for _, id := range ids {
+		sugs = append(sugs, suggest.Suggestion{Tweet: model.Tweet{ID: id, AuthorID: "a" + id, Text: "tweet " + id, CreatedAt: now.Add(-time.Hour)}, Text: "reply " + id, When: now})
+	}
+	es, err := Enqueue(context.Background(), db, sugs, nil, now)
+	if err != nil || len(es) != len(ids) { t.Fatalf("enqueue: %v %v", es, err) }
+	for i, e := range es {
+		if !e.Created || e.DraftID == 0 { t.Fatalf("enqueue %s: %+v", ids[i], e) }
+	}
+}
+
+func TestQueueTransitions(t *testing.T) {
+	db, err := sqlitevec.Open(":memory:")
+	if err != nil { t.Fatal(err) }
+	defer db.Close()
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	seed(t, db, now, "1", "2")
+	// Re-enqueueing the same tweet is a no-op
+	seed(t, db, now)
+	if es, _ := Enqueue(ctx, db, []suggest.Suggestion{{Tweet: model.Tweet{ID: "1"}, Text: "again"}}, nil, now); len(es) != 1 || es[0].Created || es[0].DraftID != 1 { t.Fatalf("expected duplicate of draft 1 skipped, got %+v", es) }
+	at := now.Add(2 * time.Hour)
+	d, err := Approve(ctx, db, 1, at, now)
+	if err != nil || d.State != Approved || !d.Scheduled.Equal(at) { t.Fatalf("approve: %+v %v", d, err) }
+	if _, err := Approve(ctx, db, 1, time.Time{}, now); !errors.Is(err, ErrState) { t.Fatalf("expected state error, got %v", err) }
+	d, err = Edit(ctx, db, 1, "edited", now)
+	if err != nil || d.Text != "edited" || d.State != Approved { t.Fatalf("edit: %+v %v", d, err) }
+	d, err = Reject(ctx, db, 2, "off topic", now)
+	if err != nil || d.State != Rejected || d.Note != "off topic" { t.Fatalf("reject: %+v %v", d, err) }
+	if _, err := Edit(ctx, db, 2, "x", now); !errors.Is(err, ErrState) { t.Fatalf("expected rejected draft to be read-only, got %v", err) }
+	if _, err := Approve(ctx, db, 99, time.Time{}, now); !errors.Is(err, sqlitevec.ErrNotFound) { t.Fatalf("expected not found, got %v", err) }
+}
+
+func TestDraftContextAllowsMentioningTheAuthor(t *testing.T) {
+	db, err := sqlitevec.Open(":memory:")
+	if err != nil { t.Fatal(err) }
+	defer db.Close()
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	authors := map[string]recommend.AccountRecommendation{"a1": {User: model.User{ID: "a1", Username: "alice"}}}
+	if _, err := Enqueue(ctx, db, []suggest.Suggestion{{Tweet: model.Tweet{ID: "1", AuthorID: "a1", Text: "Leases beat heartbeats"}, Text: "reply"}}, authors, now); err != nil { t.Fatal(err) }
+	d, err := db.GetDraft(ctx, 1)
+	if err != nil { t.Fatal(err) }
+	v := suggest.NewValidator(config.DraftRules{}, 220, nil)
+	if rej := v.Validate("@alice agreed, leases make failover predictable", DraftContext(d)); rej != nil { t.Fatalf("author mention rejected: %v", rej) }
+	if rej := v.Validate("@carol agreed, leases make failover predictable", DraftContext(d)); rej == nil || rej.Rule != "pii" { t.Fatalf("expected pii rejection, got %v", rej) }
+}
+
+func TestPostDueRespectsScheduleAndBudgets(t *testing.T) {
+	db, _ := sqlitevec.Open(":memory:")
+	defer db.Close()
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	seed(t, db, now, "1", "2", "3")
+	_, _ = Approve(ctx, db, 1, time.Time{}, now)
+	_, _ = Approve(ctx, db, 2, time.Time{}, now)
+	_, _ = Approve(ctx, db, 3, now.Add(time.Hour), now) // not yet due
+	cfg := config.EngagementConfig{MaxPerHour: 1, MaxPerDay: 10, DraftTTLHours: 24}
+	p := &fakePoster{}
+	res, err := PostDue(ctx, db, p, cfg, now, true)
+	if err != nil || len(res) != 2 || len(p.posted) != 0 { t.Fatalf("dry run: %+v %v %v", res, err, p.posted) }
+	res, err = PostDue(ctx, db, p, cfg, now, false)
+	if err != nil { t.Fatal(err) }
+	if len(p.posted) != 1 || p.posted[0] != "1" { t.Fatalf("expected only draft 1 posted, got %v", p.posted) }
+	if len(res) != 2 || !res[0].Posted || res[1].Posted || !strings.HasPrefix(res[1].Reason, "budget: global limit 1 per hour") { t.Fatalf("unexpected results %+v", res) }
+	d, _ := db.GetDraft(ctx, 1)
+	if d.State != Posted || d.PostedID != "r1" { t.Fatalf("draft 1 not marked posted: %+v", d) }
+	// The posted reply counts for novelty checks
+	past, _ := suggest.PastReplies(ctx, db, now.Add(-time.Hour), now.Add(time.Hour))
+	if len(past) != 1 || past[0] != "reply 1" { t.Fatalf("expected out_reply event, got %v", past) }
+	// and names the account replied to, for per-community engagement
+	evs, _ := db.LoadEventsRange(ctx, now.Add(-time.Hour), now.Add(time.Hour), "out_reply")
+	if len(evs) != 1 || !strings.Contains(evs[0].Payload, `"reply_to_user_id":"a1"`) { t.Fatalf("out_reply payload %+v", evs) }
+}
+
+func TestPostDueHoldsDuringQuietHours(t *testing.T) {
+	db, _ := sqlitevec.Open(":memory:")
+	defer db.Close()
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	seed(t, db, now, "1")
+	_, _ = Approve(ctx, db, 1, time.Time{}, now)
+	cfg := config.EngagementConfig{MaxPerHour: 5, DraftTTLHours: 24, Schedule: config.ScheduleConfig{Quiet: []config.QuietRange{{Start: "11:00", End: "13:00"}}}}
+	p := &fakePoster{}
+	res, err := PostDue(ctx, db, p, cfg, now, false)
+	if err != nil || len(res) != 1 || res[0].Posted || len(p.posted) != 0 { t.Fatalf("posted in quiet hours: %+v %v %v", res, err, p.posted) }
+	if !strings.Contains(res[0].Reason, "2025-01-01T13:00:00Z") { t.Fatalf("expected next allowed time, got %q", res[0].Reason) }
+	if res, _ := PostDue(ctx, db, p, cfg, now.Add(time.Hour), false); len(res) != 1 || !res[0].Posted { t.Fatalf("expected post after quiet hours, got %+v", res) }
+}
+
+func TestPostDueDefersPastCooldownAndQuietHours(t *testing.T) {
+	db, _ := sqlitevec.Open(":memory:")
+	defer db.Close()
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	seed(t, db, now, "1")
+	_, _ = Approve(ctx, db, 1, time.Time{}, now)
+	// We replied to a1 an hour ago: the cooldown ends at 17:00, inside quiet hours
+	if err := db.PutActionTarget(ctx, now.Add(-time.Hour), "reply", "a1", "9"); err != nil { t.Fatal(err) }
+	cfg := config.EngagementConfig{MaxPerHour: 5, DraftTTLHours: 24,
+		Schedule:  config.ScheduleConfig{Quiet: []config.QuietRange{{Start: "16:00", End: "18:00"}}},
+		Cooldowns: []config.CooldownRule{{Scope: "author", Type: "reply", Max: 1, WindowHours: 6}}}
+	res, err := PostDue(ctx, db, &fakePoster{}, cfg, now, false)
+	if err != nil || len(res) != 1 || res[0].Posted { t.Fatalf("unexpected %+v %v", res, err) }
+	if d, _ := db.GetDraft(ctx, 1); !d.Scheduled.Equal(time.Date(2025, 1, 1, 18, 0, 0, 0, time.UTC)) { t.Fatalf("expected deferral past quiet hours, got %s", d.Scheduled) }
+}
+
+func TestPostDueKeepsDraftOnFailureAndExpiresStale(t *testing.T) {
+	db, _ := sqlitevec.Open(":memory:")
+	defer db.Close()
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	seed(t, db, now, "1", "2")
+	_, _ = Approve(ctx, db, 1, time.Time{}, now)
+	cfg := config.EngagementConfig{MaxPerHour: 5, MaxPerDay: 10, DraftTTLHours: 24}
+	res, err := PostDue(ctx, db, &fakePoster{fail: true}, cfg, now, false)
+	if err != nil || len(res) != 1 || res[0].Posted { t.Fatalf("unexpected %+v %v", res, err) }
+	if d, _ := db.GetDraft(ctx, 1); d.State != Approved { t.Fatalf("failed post should stay approved, got %s", d.State) }
+	// A day later the target tweets are stale
+	later := now.Add(24 * time.Hour)
+	if _, err := PostDue(ctx, db, &fakePoster{}, cfg, later, false); err != nil { t.Fatal(err) }
+	for _, id := range []int64{1, 2} {
+		if d, _ := db.GetDraft(ctx, id); d.State != Expired { t.Fatalf("draft %d should be expired, got %s", id, d.State) }
+	}
+}
@@ -0,0 +1,42 @@
+package recommend
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"starseed/internal/graph"
+	"starseed/internal/store/sqlitevec"
+)
+
+// BoostUnderserved places each candidate in the community most of its stored followers
+// among our followings belong to and, when we under-engage that community (served below
+// graph.UnderservedBelow), adds weight*(UnderservedBelow-served) to its FinalScore. recs are re-sorted. Without any outbound
+// engagement in the report every community looks unserved, so nothing is boosted.
+func BoostUnderserved(ctx context.Context, db *sqlitevec.DB, recs []AccountRecommendation, rep graph.CommunityReport, weight float64) ([]AccountRecommendation, error) {
+	if weight <= 0 || rep.Outbound == 0 || len(rep.Communities) == 0 { return recs, nil }
+	member := map[string]int{}
+	for i, c := range rep.Communities {
+		for _, id := range c.Members { member[id] = i }
+	}
+	for i := range recs {
+		followers, err := db.Followers(ctx, recs[i].User.ID)
+		if err != nil { return recs, err }
+		votes := make([]int, len(rep.Communities))
+		best := -1
+		for _, f := range followers {
+			c, ok := member[f]
+			if !ok { continue }
+			votes[c]++
+			if best < 0 || votes[c] > votes[best] || (votes[c] == votes[best] && c < best) { best = c }
+		}
+		if best < 0 { continue }
+		served := rep.Communities[best].Engagement.Served
+		if served >= graph.UnderservedBelow { continue }
+		recs[i].Community = rep.Communities[best].ID
+		recs[i].CommunityBoost = weight * (graph.UnderservedBelow - math.Max(served, 0))
+		recs[i].FinalScore += recs[i].CommunityBoost
+	}
+	sort.SliceStable(recs, func(i, j int) bool { return recs[i].FinalScore > recs[j].FinalScore })
+	return recs, nil
+}
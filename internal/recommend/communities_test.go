@@ -0,0 +1,37 @@
+package recommend
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"starseed/internal/graph"
+	"starseed/internal/model"
+	"starseed/internal/store/sqlitevec"
+)
+
+func TestBoostUnderserved(t *testing.T) {
+	db, err := sqlitevec.Open(":memory:")
+	if err != nil { t.Fatal(err) }
+	defer db.Close()
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	// x is followed from the well-served circle, y mostly from the neglected one
+	for src, dsts := range map[string][]string{"a0": {"x", "y"}, "b0": {"y"}, "b1": {"y"}} {
+		if _, err := db.ReplaceFollowing(ctx, src, dsts, 0, now); err != nil { t.Fatal(err) }
+	}
+	rep := graph.CommunityReport{Outbound: 10, Communities: []graph.Community{
+		{ID: 1, Members: []string{"a0", "a1"}, Engagement: graph.Engagement{Served: 1.6}},
+		{ID: 2, Members: []string{"b0", "b1"}, Engagement: graph.Engagement{Served: 0.25}},
+	}}
+	recs := []AccountRecommendation{{User: model.User{ID: "x"}, FinalScore: 0.5}, {User: model.User{ID: "y"}, FinalScore: 0.45}, {User: model.User{ID: "z"}, FinalScore: 0.4}}
+	recs, err = BoostUnderserved(ctx, db, recs, rep, 0.1)
+	if err != nil { t.Fatal(err) }
+	if recs[0].User.ID != "y" || recs[0].Community != 2 || math.Abs(recs[0].CommunityBoost-0.075) > 1e-9 { t.Fatalf("boosted = %+v", recs[0]) }
+	if recs[1].CommunityBoost != 0 || recs[2].CommunityBoost != 0 { t.Fatalf("unexpected boosts %+v", recs) }
+	// no engagement at all: no circle stands out
+	rep.Outbound = 0
+	for i := range recs { recs[i].FinalScore -= recs[i].CommunityBoost; recs[i].CommunityBoost = 0 }
+	if recs, _ = BoostUnderserved(ctx, db, recs, rep, 0.1); recs[0].CommunityBoost != 0 { t.Fatalf("boost without engagement %+v", recs[0]) }
+}
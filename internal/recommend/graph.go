@@ -5,13 +5,14 @@ import (
 	"sort"
 
 	"starseed/internal/model"
-	"starseed/internal/xclient"
+	"starseed/internal/graph"
     "starseed/internal/store/sqlitevec"
     "time"
 )
 
-// DiscoverGraph expands accounts by mutual follow edges (up to one hop).
-func DiscoverGraph(ctx context.Context, client xclient.XClient, seed []model.User, limit int) ([]model.User, error) {
+// DiscoverGraph expands accounts by mutual follow edges (up to one hop). client may be
+// the live API or the stored graph (graph.Stored).
+func DiscoverGraph(ctx context.Context, client graph.FollowingGetter, seed []model.User, limit int) ([]model.User, error) {
 	seen := make(map[string]struct{})
 	for _, u := range seed { seen[u.ID] = struct{}{} }
 	var out []model.User
@@ -30,7 +31,7 @@ func DiscoverGraph(ctx context.Context, client xclient.XClient, seed []model.Use
 }
 
 // DiscoverGraphMultiHop expands follow graph up to given depth (>=1).
-func DiscoverGraphMultiHop(ctx context.Context, client xclient.XClient, seed []model.User, depth int, limit int) ([]model.User, error) {
+func DiscoverGraphMultiHop(ctx context.Context, client graph.FollowingGetter, seed []model.User, depth int, limit int) ([]model.User, error) {
     if depth < 1 { depth = 1 }
     seen := make(map[string]struct{})
     var frontier []model.User = seed
@@ -64,7 +65,7 @@ type GraphParams struct {
 }
 
 // BuildGraphStats returns candidates plus hop distance and mutual counts.
-func BuildGraphStats(ctx context.Context, client xclient.XClient, seed []model.User, depth int, limit int) ([]model.User, map[string]int, map[string]int, error) {
+func BuildGraphStats(ctx context.Context, client graph.FollowingGetter, seed []model.User, depth int, limit int) ([]model.User, map[string]int, map[string]int, error) {
     if depth < 1 { depth = 1 }
     hop := make(map[string]int)
     mutual := make(map[string]int)
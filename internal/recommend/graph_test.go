@@ -19,6 +19,7 @@ func (fakeGraphClient) GetFollowing(ctx context.Context, userID string, limit in
 	}
 	return nil, nil
 }
+func (fakeGraphClient) GetFollowers(ctx context.Context, userID string, limit int) ([]model.User, error) { return nil, nil }
 func (fakeGraphClient) SearchRecentTweets(ctx context.Context, query string, limit int) ([]model.Tweet, error) { return nil, nil }
 func (fakeGraphClient) SearchRecentTweetsSince(ctx context.Context, query string, limit int, start time.Time) ([]model.Tweet, error) {
 	return nil, nil
@@ -0,0 +1,135 @@
+package recommend
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"starseed/internal/ingest"
+	"starseed/internal/model"
+	"starseed/internal/store/sqlitevec"
+)
+
+// Organic is an account's organic score from its recent tweets.
+type Organic struct {
+	Score   float64
+	Sampled int
+}
+
+// organicHalfLife is how fast older tweets lose weight in the content average.
+const organicHalfLife = 7 * 24 * time.Hour
+
+// AccountOrganic scores an account from its recent tweets: OrganicContentScore averaged
+// with recency decay (60%), a healthy mix of replies and original posts (20%) and
+// consistent rather than spiky engagement (20%). Without tweets it is a neutral 0.5.
+func AccountOrganic(tweets []model.Tweet, now time.Time) Organic {
+	if len(tweets) == 0 { return Organic{Score: 0.5} }
+	sum, wsum := 0.0, 0.0
+	replies := 0
+	eng := make([]float64, 0, len(tweets))
+	for _, t := range tweets {
+		w := 1.0
+		if age := now.Sub(t.CreatedAt); !t.CreatedAt.IsZero() && age > 0 { w = math.Pow(0.5, float64(age)/float64(organicHalfLife)) }
+		sum += w * model.OrganicContentScore(t)
+		wsum += w
+		if t.ReplyToID != "" { replies++ }
+		eng = append(eng, float64(t.LikeCount+t.ReplyCount+t.RetweetCount+t.QuoteCount))
+	}
+	content := sum / wsum
+	// Conversation: people both post and reply; all-replies or never-replies is less organic
+	share := float64(replies) / float64(len(tweets))
+	convo := math.Max(0, 1-math.Abs(share-0.4)/0.6)
+	// Consistency: 1/(1+CV) of per-tweet engagement; no engagement at all is neutral
+	consistency := 0.5
+	if mean := meanOf(eng); mean > 0 {
+		v := 0.0
+		for _, e := range eng { v += (e - mean) * (e - mean) }
+		consistency = 1 / (1 + math.Sqrt(v/float64(len(eng)))/mean)
+	}
+	score := 0.6*content + 0.2*convo + 0.2*consistency
+	return Organic{Score: math.Round(score*100) / 100, Sampled: len(tweets)}
+}
+
+func meanOf(xs []float64) float64 {
+	if len(xs) == 0 { return 0 }
+	s := 0.0
+	for _, x := range xs { s += x }
+	return s / float64(len(xs))
+}
+
+// OrganicOptions control how account timelines are fetched.
+type OrganicOptions struct {
+	Tweets int           // recent tweets per account (default 20)
+	MaxAge time.Duration // cached timelines older than this are refetched (default 24h)
+	// MaxFetch caps API fetches per call; accounts beyond it use whatever is cached
+	// (0 = no cap)
+	MaxFetch int
+	// Budget, when set, replaces MaxFetch with a cap shared by several calls: each
+	// fetch draws it down
+	Budget *int
+	Workers int // concurrent timeline fetches (default 4)
+}
+
+// OrganicScores computes AccountOrganic for users from the tweets cache. Missing
+// timelines are fetched from src before stale ones, up to the fetch cap and Workers at a
+// time. src may be nil (cache only).
+func OrganicScores(ctx context.Context, db *sqlitevec.DB, src ingest.TimelineSource, users []model.User, opts OrganicOptions, now time.Time) (map[string]Organic, error) {
+	if opts.Tweets <= 0 { opts.Tweets = 20 }
+	if opts.MaxAge <= 0 { opts.MaxAge = 24 * time.Hour }
+	if opts.Workers <= 0 { opts.Workers = 4 }
+	var ids []string
+	seen := make(map[string]bool, len(users))
+	for _, u := range users {
+		if !seen[u.ID] { seen[u.ID] = true; ids = append(ids, u.ID) }
+	}
+	out := make(map[string]Organic, len(ids))
+	if src != nil {
+		fetched, err := db.TimelinesFetchedAt(ctx, ids)
+		if err != nil { return out, err }
+		var missing, stale []string
+		for _, id := range ids {
+			if at, ok := fetched[id]; !ok {
+				missing = append(missing, id)
+			} else if now.Sub(at) > opts.MaxAge {
+				stale = append(stale, id)
+			}
+		}
+		due := append(missing, stale...)
+		limit, capped := opts.MaxFetch, opts.MaxFetch > 0
+		if opts.Budget != nil { limit, capped = max(*opts.Budget, 0), true }
+		if capped && len(due) > limit { due = due[:limit] }
+		if opts.Budget != nil { *opts.Budget -= len(due) }
+		if err := fetchTimelines(ctx, db, src, due, opts, now); err != nil { return out, err }
+	}
+	for _, id := range ids {
+		ts, err := db.TweetsByAuthor(ctx, id, opts.Tweets)
+		if err != nil { return out, err }
+		out[id] = AccountOrganic(ts, now)
+	}
+	return out, nil
+}
+
+// fetchTimelines fetches ids opts.Workers at a time and caches each timeline, including
+// empty ones. Failed fetches are skipped: those accounts keep whatever is cached.
+func fetchTimelines(ctx context.Context, db *sqlitevec.DB, src ingest.TimelineSource, ids []string, opts OrganicOptions, now time.Time) error {
+	tweets := make([][]model.Tweet, len(ids))
+	errs := make([]error, len(ids))
+	sem := make(chan struct{}, opts.Workers)
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, id string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			tweets[i], errs[i] = src.GetUserTimeline(ctx, id, opts.Tweets)
+		}(i, id)
+	}
+	wg.Wait()
+	for i, id := range ids {
+		if errs[i] != nil { continue }
+		if err := ingest.SaveTimeline(ctx, db, id, tweets[i], now); err != nil { return err }
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package recommend
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"starseed/internal/model"
+	"starseed/internal/store/sqlitevec"
+)
+
+func TestAccountOrganic(t *testing.T) {
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	if o := AccountOrganic(nil, now); o.Score != 0.5 || o.Sampled != 0 { t.Fatalf("no tweets = %+v", o) }
+	var real, spam []model.Tweet
+	for i := 0; i < 10; i++ {
+		at := now.Add(-time.Duration(i) * 12 * time.Hour)
+		tw := model.Tweet{Text: "working through raft log compaction", CreatedAt: at, LikeCount: 10, ReplyCount: 3, QuoteCount: 1}
+		if i%3 == 0 { tw.ReplyToID = "x" }
+		real = append(real, tw)
+		sp := model.Tweet{Text: "giveaway! click here", HasLink: true, CreatedAt: at}
+		if i == 0 { sp.LikeCount = 5000 }
+		spam = append(spam, sp)
+	}
+	r, s := AccountOrganic(real, now), AccountOrganic(spam, now)
+	if r.Sampled != 10 || r.Score < 0.8 || s.Score > 0.4 { t.Fatalf("real=%+v spam=%+v", r, s) }
+	// recency decay: a recent spam tweet outweighs an old clean one
+	mixed := []model.Tweet{{Text: "giveaway", CreatedAt: now}, {Text: "thoughtful post", CreatedAt: now.AddDate(0, 0, -60)}}
+	rev := []model.Tweet{{Text: "giveaway", CreatedAt: now.AddDate(0, 0, -60)}, {Text: "thoughtful post", CreatedAt: now}}
+	if AccountOrganic(mixed, now).Score >= AccountOrganic(rev, now).Score { t.Error("recent tweets should weigh more") }
+}
+
+type fakeTimelines map[string][]model.Tweet
+
+func (f fakeTimelines) GetUserTimeline(ctx context.Context, userID string, limit int) ([]model.Tweet, error) { return f[userID], nil }
+
+func TestOrganicScoresFeedRanking(t *testing.T) {
+	db, err := sqlitevec.Open(":memory:")
+	if err != nil { t.Fatal(err) }
+	defer db.Close()
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	src := fakeTimelines{
+		"a": {{ID: "1", AuthorID: "a", Text: "giveaway click here", HasLink: true, CreatedAt: now}},
+		"b": {{ID: "2", AuthorID: "b", Text: "notes on consensus", CreatedAt: now, LikeCount: 2, ReplyCount: 1}},
+		"c": {{ID: "3", AuthorID: "c", Text: "never fetched", CreatedAt: now}},
+	}
+	users := []model.User{{ID: "a", Username: "a", Description: "golang"}, {ID: "b", Username: "b", Description: "golang"}, {ID: "c", Username: "c", Description: "golang"}}
+	org, err := OrganicScores(context.Background(), db, src, users, OrganicOptions{MaxFetch: 2}, now)
+	if err != nil { t.Fatal(err) }
+	if org["a"].Sampled != 1 || org["b"].Sampled != 1 || org["c"].Sampled != 0 || org["c"].Score != 0.5 { t.Fatalf("org = %+v", org) }
+	recs := RankAccountsOrganic(users, org, []string{"golang"}, nil, nil)
+	if recs[0].User.ID != "b" || recs[2].User.ID != "a" || recs[0].TweetsSampled != 1 { t.Fatalf("ranking = %+v", recs) }
+	// cached timelines are reused without fetching
+	org, err = OrganicScores(context.Background(), db, nil, users, OrganicOptions{}, now.Add(time.Hour))
+	if err != nil || org["b"].Sampled != 1 { t.Fatalf("cache-only = %+v %v", org, err) }
+}
+
+type countingTimelines struct {
+	fakeTimelines
+	mu    sync.Mutex
+	calls []string
+}
+
+func (c *countingTimelines) GetUserTimeline(ctx context.Context, userID string, limit int) ([]model.Tweet, error) {
+	c.mu.Lock()
+	c.calls = append(c.calls, userID)
+	c.mu.Unlock()
+	return c.fakeTimelines.GetUserTimeline(ctx, userID, limit)
+}
+
+func TestOrganicScoresFetchBudget(t *testing.T) {
+	db, err := sqlitevec.Open(":memory:")
+	if err != nil { t.Fatal(err) }
+	defer db.Close()
+	ctx := context.Background()
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	src := &countingTimelines{fakeTimelines: fakeTimelines{"old": {{ID: "1", AuthorID: "old", Text: "hello", CreatedAt: now}}}}
+	// "old" was cached two days ago; "quiet" has no tweets at all
+	if err := db.PutTweets(ctx, src.fakeTimelines["old"], now.Add(-48*time.Hour)); err != nil { t.Fatal(err) }
+	users := []model.User{{ID: "old"}, {ID: "quiet"}, {ID: "new"}}
+	budget := 2
+	if _, err := OrganicScores(ctx, db, src, users, OrganicOptions{Budget: &budget}, now); err != nil { t.Fatal(err) }
+	// never-fetched accounts come before stale ones
+	if budget != 0 || len(src.calls) != 2 || src.calls[0] == "old" || src.calls[1] == "old" { t.Fatalf("calls %v budget %d", src.calls, budget) }
+	src.calls = nil
+	if _, err := OrganicScores(ctx, db, src, users, OrganicOptions{Budget: &budget}, now); err != nil || len(src.calls) != 0 { t.Fatalf("spent budget still fetched %v %v", src.calls, err) }
+	// the empty timeline is remembered; only the stale one is due
+	org, err := OrganicScores(ctx, db, src, users, OrganicOptions{}, now.Add(time.Hour))
+	if err != nil || len(src.calls) != 1 || src.calls[0] != "old" || org["old"].Sampled != 1 { t.Fatalf("calls %v org %+v %v", src.calls, org, err) }
+}
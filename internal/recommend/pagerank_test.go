@@ -0,0 +1,41 @@
+package recommend
+
+import (
+	"testing"
+
+	"starseed/internal/graph"
+	"starseed/internal/model"
+)
+
+func TestGraphScoresExcludeFollowsAndWeighInteractions(t *testing.T) {
+	build := func() *graph.Graph {
+		g := graph.New()
+		for _, e := range [][2]string{{"me", "a"}, {"me", "b"}, {"a", "x"}, {"b", "y"}, {"a", "b"}} { g.AddEdge(e[0], e[1], 1) }
+		return g
+	}
+	for _, mode := range []string{ModePageRank, ModeSALSA} {
+		s := GraphScores(build(), "me", nil, GraphRankOptions{Mode: mode})
+		if _, ok := s["me"]; ok { t.Fatalf("%s: me scored", mode) }
+		if _, ok := s["a"]; ok { t.Fatalf("%s: followed account scored", mode) }
+		if s["x"] <= 0 || s["y"] <= 0 { t.Fatalf("%s: candidates missing: %v", mode, s) }
+		max := 0.0
+		for _, v := range s { if v > max { max = v } }
+		if max != 1 { t.Fatalf("%s: not normalized: %v", mode, s) }
+	}
+	// frequent interactions with b pull the walk away from a's follows; an interacted-with stranger becomes a candidate
+	plain := GraphScores(build(), "me", nil, GraphRankOptions{})
+	boosted := GraphScores(build(), "me", map[string]int{"b": 6, "z": 1}, GraphRankOptions{})
+	if boosted["x"] >= plain["x"] { t.Fatalf("interactions ignored: plain %v boosted %v", plain, boosted) }
+	if boosted["z"] <= 0 { t.Fatalf("interacted author should be a candidate: %v", boosted) }
+	if len(GraphScores(graph.New(), "me", nil, GraphRankOptions{})) != 0 { t.Fatal("empty graph should give no scores") }
+}
+
+func TestRankByGraphPenalizesBots(t *testing.T) {
+	human := model.User{ID: "h", Username: "human", Description: "golang and distributed systems", FollowersCount: 800, FollowingCount: 300, TweetCount: 2000, Verified: true}
+	bot := model.User{ID: "b", Username: "crypto_bot_123456", Description: "follow back dm for promo", FollowersCount: 3, FollowingCount: 5000, TweetCount: 90000, DefaultImage: true}
+	scores := map[string]float64{"h": 0.7, "b": 1}
+	recs := RankByGraph([]model.User{bot, human}, scores, nil, []string{"golang"}, nil, nil)
+	if recs[0].User.ID != "h" { t.Fatalf("bot outranked human: %+v", recs) }
+	if recs[0].GraphScore != 0.7 { t.Fatalf("graph score %v", recs[0].GraphScore) }
+	if ids := TopGraph(scores, 1); len(ids) != 1 || ids[0] != "b" { t.Fatalf("top %v", ids) }
+}
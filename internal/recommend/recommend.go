@@ -13,18 +13,34 @@ type AccountRecommendation struct {
 	BotLikelihood  float64
 	RelevanceScore float64
 	FinalScore     float64
+	// Normalized graph proximity (pagerank and salsa modes only)
+	GraphScore float64
+	// Recent tweets the organic score was computed from (0: neutral default)
+	TweetsSampled int
+	// Under-served community the account was boosted for (BoostUnderserved; 0: none)
+	Community      int
+	CommunityBoost float64
 }
 
-// RankAccounts ranks users to follow based on heuristic scores.
+// RankAccounts ranks users to follow based on heuristic scores, with a neutral organic
+// score.
 func RankAccounts(users []model.User, keywords []string, weights map[string]float64) []AccountRecommendation {
+	return RankAccountsOrganic(users, nil, keywords, weights, nil)
+}
+
+// RankAccountsOrganic is RankAccounts with per-account organic scores (see
+// OrganicScores) and bot likelihood from bot (nil: the profile heuristic); users missing
+// from organic get the neutral 0.5.
+func RankAccountsOrganic(users []model.User, organic map[string]Organic, keywords []string, weights map[string]float64, bot model.BotScorer) []AccountRecommendation {
 	recs := make([]AccountRecommendation, 0, len(users))
 	for _, u := range users {
-		org := 0.5
-		bot := model.BotLikelihood(u)
+		org := Organic{Score: 0.5}
+		if o, ok := organic[u.ID]; ok { org = o }
+		b := bot.Score(u)
 		text := u.Description + " " + u.Name
 		rel := model.InterestRelevance(text, keywords, weights)
-		final := rel*0.6 + org*0.2 + (1-bot)*0.2
-		recs = append(recs, AccountRecommendation{User: u, OrganicScore: org, BotLikelihood: bot, RelevanceScore: rel, FinalScore: final})
+		final := rel*0.6 + org.Score*0.2 + (1-b)*0.2
+		recs = append(recs, AccountRecommendation{User: u, OrganicScore: org.Score, BotLikelihood: b, RelevanceScore: rel, FinalScore: final, TweetsSampled: org.Sampled})
 	}
 	sort.Slice(recs, func(i, j int) bool { return recs[i].FinalScore > recs[j].FinalScore })
 	return recs
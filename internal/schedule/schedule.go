@@ -2,21 +2,115 @@ package schedule
 
 import (
 	"time"
+
+	"starseed/internal/config"
 )
 
+// IsQuietHour reports whether t falls in one of the quiet hours.
+func IsQuietHour(t time.Time, quietHours []int) bool {
+	for _, q := range quietHours {
+		if q == t.Hour() { return true }
+	}
+	return false
+}
+
 // NextWindow returns the next suitable interaction time avoiding quiet hours.
 func NextWindow(now time.Time, quietHours []int) time.Time {
-	isQuiet := func(h int) bool {
-		for _, q := range quietHours {
-			if q == h { return true }
-		}
-		return false
-	}
 	for i := 0; i < 48; i++ { // search up to 2 days ahead
 		cand := now.Add(time.Duration(i) * time.Hour)
-		if !isQuiet(cand.Hour()) {
+		if !IsQuietHour(cand, quietHours) {
 			return cand
 		}
 	}
 	return now.Add(15 * time.Minute)
 }
+
+// Rules evaluates quiet ranges and blackout dates in the account's local time.
+// All checks convert the instant to the configured zone first, so ranges follow
+// the wall clock across DST changes.
+type Rules struct {
+	loc      *time.Location
+	quiet    []quietRange
+	blackout map[string]bool
+}
+
+type quietRange struct {
+	days       [7]bool
+	start, end int // minutes after local midnight
+}
+
+// Compile builds Rules from engagement config. Without schedule.quiet ranges the
+// legacy quietHours are used as whole-hour ranges in the schedule timezone.
+func Compile(e config.EngagementConfig) (*Rules, error) {
+	sc := e.Schedule
+	r := &Rules{loc: time.UTC, blackout: map[string]bool{}}
+	if sc.Timezone != "" {
+		loc, err := time.LoadLocation(sc.Timezone)
+		if err != nil { return nil, err }
+		r.loc = loc
+	}
+	for _, q := range sc.Quiet {
+		start, err := config.ParseClock(q.Start)
+		if err != nil { return nil, err }
+		end, err := config.ParseClock(q.End)
+		if err != nil { return nil, err }
+		qr := quietRange{start: start, end: end}
+		if len(q.Days) == 0 {
+			for i := range qr.days { qr.days[i] = true }
+		}
+		for _, d := range q.Days {
+			wd, err := config.ParseWeekday(d)
+			if err != nil { return nil, err }
+			qr.days[wd] = true
+		}
+		r.quiet = append(r.quiet, qr)
+	}
+	if len(sc.Quiet) == 0 {
+		for _, h := range e.QuietHours {
+			qr := quietRange{start: h * 60, end: (h*60 + 60) % (24 * 60)}
+			for i := range qr.days { qr.days[i] = true }
+			r.quiet = append(r.quiet, qr)
+		}
+	}
+	for _, d := range sc.Blackout {
+		if _, err := time.Parse("2006-01-02", d); err != nil { return nil, err }
+		r.blackout[d] = true
+	}
+	return r, nil
+}
+
+// Location returns the schedule timezone.
+func (r *Rules) Location() *time.Location { return r.loc }
+
+// IsQuiet reports whether engagement should be avoided at t.
+func (r *Rules) IsQuiet(t time.Time) bool {
+	lt := t.In(r.loc)
+	if r.blackout[lt.Format("2006-01-02")] { return true }
+	m := lt.Hour()*60 + lt.Minute()
+	wd := lt.Weekday()
+	prev := (wd + 6) % 7
+	for _, q := range r.quiet {
+		switch {
+		case q.start == q.end:
+			if q.days[wd] { return true }
+		case q.start < q.end:
+			if q.days[wd] && m >= q.start && m < q.end { return true }
+		default: // wraps past midnight: the tail belongs to the previous day's range
+			if q.days[wd] && m >= q.start { return true }
+			if q.days[prev] && m < q.end { return true }
+		}
+	}
+	return false
+}
+
+// Next returns the first non-quiet minute at or after t, searching up to two weeks
+// ahead; t is returned unchanged when no allowed time exists in that span.
+func (r *Rules) Next(t time.Time) time.Time {
+	if !r.IsQuiet(t) { return t }
+	cand := t.Truncate(time.Minute)
+	for i := 0; i < 14*24*60; i++ {
+		cand = cand.Add(time.Minute)
+		if !r.IsQuiet(cand) { return cand }
+	}
+	return t
+}
@@ -0,0 +1,62 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"starseed/internal/config"
+)
+
+func mustCompile(t *testing.T, e config.EngagementConfig) *Rules {
+	t.Helper()
+	r, err := Compile(e)
+	if err != nil { t.Fatal(err) }
+	return r
+}
+
+func TestRulesWeekdayRangesWrapMidnight(t *testing.T) {
+	r := mustCompile(t, config.EngagementConfig{Schedule: config.ScheduleConfig{
+		Timezone: "Europe/Berlin",
+		Quiet:    []config.QuietRange{{Days: []string{"fri"}, Start: "22:30", End: "07:15"}},
+	}})
+	berlin := r.Location()
+	cases := []struct {
+		at    time.Time
+		quiet bool
+	}{
+		{time.Date(2024, 5, 10, 22, 29, 0, 0, berlin), false}, // Friday before start
+		{time.Date(2024, 5, 10, 22, 30, 0, 0, berlin), true},
+		{time.Date(2024, 5, 11, 7, 0, 0, 0, berlin), true},  // Saturday tail of Friday's range
+		{time.Date(2024, 5, 11, 7, 15, 0, 0, berlin), false},
+		{time.Date(2024, 5, 11, 23, 0, 0, 0, berlin), false}, // Saturday night is not quiet
+	}
+	for _, c := range cases {
+		if got := r.IsQuiet(c.at.UTC()); got != c.quiet { t.Errorf("IsQuiet(%v)=%v want %v", c.at, got, c.quiet) }
+	}
+	next := r.Next(time.Date(2024, 5, 11, 1, 0, 0, 0, berlin))
+	if !next.Equal(time.Date(2024, 5, 11, 7, 15, 0, 0, berlin)) { t.Fatalf("unexpected next %v", next.In(berlin)) }
+}
+
+func TestRulesFollowWallClockAcrossDST(t *testing.T) {
+	r := mustCompile(t, config.EngagementConfig{Schedule: config.ScheduleConfig{
+		Timezone: "America/New_York",
+		Quiet:    []config.QuietRange{{Start: "00:00", End: "06:00"}},
+	}})
+	ny := r.Location()
+	// 2024-03-10: clocks jump 02:00 -> 03:00 local, so the range ends at 10:00 UTC instead of 11:00.
+	before := r.Next(time.Date(2024, 3, 9, 5, 0, 0, 0, time.UTC))
+	after := r.Next(time.Date(2024, 3, 10, 5, 0, 0, 0, time.UTC))
+	if before.UTC().Hour() != 11 || after.UTC().Hour() != 10 { t.Fatalf("DST not honored: %v %v", before.UTC(), after.UTC()) }
+	if got := after.In(ny); got.Hour() != 6 || got.Minute() != 0 { t.Fatalf("expected 06:00 local, got %v", got) }
+}
+
+func TestRulesBlackoutAndLegacyHours(t *testing.T) {
+	r := mustCompile(t, config.EngagementConfig{
+		QuietHours: []int{23},
+		Schedule:   config.ScheduleConfig{Blackout: []string{"2024-12-25"}},
+	})
+	if !r.IsQuiet(time.Date(2024, 12, 25, 12, 0, 0, 0, time.UTC)) { t.Fatalf("blackout date should be quiet") }
+	if !r.IsQuiet(time.Date(2024, 12, 24, 23, 30, 0, 0, time.UTC)) { t.Fatalf("legacy quiet hour should apply") }
+	next := r.Next(time.Date(2024, 12, 24, 23, 30, 0, 0, time.UTC))
+	if !next.Equal(time.Date(2024, 12, 26, 0, 0, 0, 0, time.UTC)) { t.Fatalf("unexpected next %v", next) }
+}
@@ -0,0 +1,44 @@
+package sqlitevec
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// AuditSnapshot is the aggregate quality of our followings and followers at one audit.
+type AuditSnapshot struct {
+	TS          time.Time
+	Following   int
+	Followers   int
+	Mutual      int
+	Suspicious  int
+	MeanBot     float64
+	MeanOrganic float64
+	Detail      string // JSON: thresholds, per-relation breakdown, top signals
+}
+
+// PutAuditSnapshot stores a snapshot, replacing one taken in the same second.
+func (d *DB) PutAuditSnapshot(ctx context.Context, s AuditSnapshot) error {
+	_, err := d.sql.ExecContext(ctx, `INSERT OR REPLACE INTO audit_snapshots(ts, following, followers, mutual, suspicious, mean_bot, mean_organic, detail) VALUES(?,?,?,?,?,?,?,?)`,
+		s.TS.Unix(), s.Following, s.Followers, s.Mutual, s.Suspicious, s.MeanBot, s.MeanOrganic, s.Detail)
+	return err
+}
+
+// ListAuditSnapshots returns snapshots taken at or after since, oldest first.
+func (d *DB) ListAuditSnapshots(ctx context.Context, since time.Time) ([]AuditSnapshot, error) {
+	rows, err := d.sql.QueryContext(ctx, `SELECT ts, following, followers, mutual, suspicious, mean_bot, mean_organic, detail FROM audit_snapshots WHERE ts>=? ORDER BY ts`, since.Unix())
+	if err != nil { return nil, err }
+	defer rows.Close()
+	var out []AuditSnapshot
+	for rows.Next() {
+		var s AuditSnapshot
+		var ts int64
+		var bot, organic sql.NullFloat64
+		var detail sql.NullString
+		if err := rows.Scan(&ts, &s.Following, &s.Followers, &s.Mutual, &s.Suspicious, &bot, &organic, &detail); err != nil { return nil, err }
+		s.TS, s.MeanBot, s.MeanOrganic, s.Detail = time.Unix(ts, 0).UTC(), bot.Float64, organic.Float64, detail.String
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,74 @@
+package sqlitevec
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// Decision is one audited engagement decision (queued, skipped, posted, halted, ...).
+type Decision struct {
+	ID       int64
+	TS       time.Time
+	Kind     string // what decided, e.g. "engage_gate", "engage_candidate", "auto_post"
+	Outcome  string
+	DraftID  int64
+	TweetID  string
+	AuthorID string
+	Author   string // username when known
+	Reason   string
+	Detail   string // JSON: scores, prediction, budgets, policy result, ...
+}
+
+// PutDecision appends a decision to the audit table and returns its ID.
+func (d *DB) PutDecision(ctx context.Context, dc Decision) (int64, error) {
+	res, err := d.sql.ExecContext(ctx, `INSERT INTO decisions(ts, kind, outcome, draft_id, tweet_id, author_id, author, reason, detail) VALUES(?,?,?,?,?,?,?,?,?)`,
+		dc.TS.Unix(), dc.Kind, dc.Outcome, dc.DraftID, dc.TweetID, dc.AuthorID, dc.Author, dc.Reason, dc.Detail)
+	if err != nil { return 0, err }
+	return res.LastInsertId()
+}
+
+// DecisionFilter narrows ListDecisions; zero fields match everything. Author matches the
+// author ID or username (case-insensitive, without @).
+type DecisionFilter struct {
+	Since   time.Time
+	Until   time.Time
+	Author  string
+	Outcome string
+	Kind    string
+	TweetID string
+	Limit   int
+}
+
+// ListDecisions returns matching decisions, newest first.
+func (d *DB) ListDecisions(ctx context.Context, f DecisionFilter) ([]Decision, error) {
+	q := `SELECT id, ts, kind, outcome, draft_id, tweet_id, author_id, author, reason, detail FROM decisions WHERE ts>=?`
+	args := []any{f.Since.Unix()}
+	if !f.Until.IsZero() { q += ` AND ts<?`; args = append(args, f.Until.Unix()) }
+	if f.Author != "" {
+		a := strings.TrimPrefix(f.Author, "@")
+		q += ` AND (author_id=? OR lower(author)=lower(?))`
+		args = append(args, a, a)
+	}
+	if f.Outcome != "" { q += ` AND outcome=?`; args = append(args, f.Outcome) }
+	if f.Kind != "" { q += ` AND kind=?`; args = append(args, f.Kind) }
+	if f.TweetID != "" { q += ` AND tweet_id=?`; args = append(args, f.TweetID) }
+	q += ` ORDER BY ts DESC, id DESC`
+	if f.Limit > 0 { q += ` LIMIT ?`; args = append(args, f.Limit) }
+	rows, err := d.sql.QueryContext(ctx, q, args...)
+	if err != nil { return nil, err }
+	defer rows.Close()
+	var out []Decision
+	for rows.Next() {
+		var dc Decision
+		var ts int64
+		var draft sql.NullInt64
+		var tweet, authorID, author, reason, detail sql.NullString
+		if err := rows.Scan(&dc.ID, &ts, &dc.Kind, &dc.Outcome, &draft, &tweet, &authorID, &author, &reason, &detail); err != nil { return nil, err }
+		dc.TS = time.Unix(ts, 0).UTC()
+		dc.DraftID, dc.TweetID, dc.AuthorID, dc.Author, dc.Reason, dc.Detail = draft.Int64, tweet.String, authorID.String, author.String, reason.String, detail.String
+		out = append(out, dc)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,124 @@
+package sqlitevec
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Draft is a reply waiting for review or posting.
+type Draft struct {
+	ID           int64
+	Created      time.Time
+	Updated      time.Time
+	TweetID      string
+	AuthorID     string
+	TweetText    string
+	TweetCreated time.Time
+	Text         string
+	Scheduled    time.Time
+	State        string
+	Score        float64
+	Why          string
+	PostedID     string
+	Note         string // rejection reason or posting error
+	Meta         string // JSON: alternates, thread flags, ...
+}
+
+// ErrNotFound is returned when a row does not exist.
+var ErrNotFound = errors.New("not found")
+
+// PutDraft inserts a draft unless one already exists for the target tweet; it returns
+// the draft ID and whether a new row was created.
+func (d *DB) PutDraft(ctx context.Context, dr Draft) (int64, bool, error) {
+	res, err := d.sql.ExecContext(ctx, `INSERT OR IGNORE INTO drafts(created, updated, tweet_id, author_id, tweet_text, tweet_created, text, scheduled, state, score, why, meta)
+		VALUES(?,?,?,?,?,?,?,?,?,?,?,?)`,
+		dr.Created.Unix(), dr.Created.Unix(), dr.TweetID, dr.AuthorID, dr.TweetText, dr.TweetCreated.Unix(), dr.Text, dr.Scheduled.Unix(), dr.State, dr.Score, dr.Why, dr.Meta)
+	if err != nil { return 0, false, err }
+	if n, _ := res.RowsAffected(); n == 0 {
+		var id int64
+		err := d.sql.QueryRowContext(ctx, `SELECT id FROM drafts WHERE tweet_id=?`, dr.TweetID).Scan(&id)
+		return id, false, err
+	}
+	id, err := res.LastInsertId()
+	return id, true, err
+}
+
+const draftCols = `id, created, updated, tweet_id, author_id, tweet_text, tweet_created, text, scheduled, state, score, why, posted_id, note, meta`
+
+func scanDraft(sc interface{ Scan(...any) error }) (Draft, error) {
+	var dr Draft
+	var created, updated, tweetCreated, scheduled int64
+	var author, tweetText, why, posted, note, meta sql.NullString
+	var score sql.NullFloat64
+	if err := sc.Scan(&dr.ID, &created, &updated, &dr.TweetID, &author, &tweetText, &tweetCreated, &dr.Text, &scheduled, &dr.State, &score, &why, &posted, &note, &meta); err != nil {
+		return dr, err
+	}
+	dr.Created, dr.Updated = time.Unix(created, 0).UTC(), time.Unix(updated, 0).UTC()
+	dr.TweetCreated, dr.Scheduled = time.Unix(tweetCreated, 0).UTC(), time.Unix(scheduled, 0).UTC()
+	dr.AuthorID, dr.TweetText, dr.Why, dr.PostedID, dr.Note, dr.Meta = author.String, tweetText.String, why.String, posted.String, note.String, meta.String
+	dr.Score = score.Float64
+	return dr, nil
+}
+
+// DraftStates returns the state of the drafts already made for the given tweets, keyed
+// by tweet ID; tweets without a draft are absent.
+func (d *DB) DraftStates(ctx context.Context, tweetIDs []string) (map[string]string, error) {
+	return d.lookup(ctx, `SELECT tweet_id, state FROM drafts WHERE tweet_id IN %s`, tweetIDs)
+}
+
+// GetDraft loads one draft by ID.
+func (d *DB) GetDraft(ctx context.Context, id int64) (Draft, error) {
+	dr, err := scanDraft(d.sql.QueryRowContext(ctx, `SELECT `+draftCols+` FROM drafts WHERE id=?`, id))
+	if errors.Is(err, sql.ErrNoRows) { return dr, ErrNotFound }
+	return dr, err
+}
+
+// ListDrafts returns drafts in the given states (all when empty) ordered by schedule.
+func (d *DB) ListDrafts(ctx context.Context, states []string, limit int) ([]Draft, error) {
+	q := `SELECT ` + draftCols + ` FROM drafts`
+	var args []any
+	if len(states) > 0 {
+		q += ` WHERE state IN (?` + repeatPlaceholders(len(states)-1) + `)`
+		for _, s := range states { args = append(args, s) }
+	}
+	q += ` ORDER BY scheduled, id`
+	if limit > 0 { q += ` LIMIT ?`; args = append(args, limit) }
+	rows, err := d.sql.QueryContext(ctx, q, args...)
+	if err != nil { return nil, err }
+	defer rows.Close()
+	var out []Draft
+	for rows.Next() {
+		dr, err := scanDraft(rows)
+		if err != nil { return nil, err }
+		out = append(out, dr)
+	}
+	return out, rows.Err()
+}
+
+// UpdateDraft writes the mutable fields (text, schedule, state, posted ID, note) of dr,
+// but only if the stored state is still fromState. It returns ErrNotFound otherwise.
+func (d *DB) UpdateDraft(ctx context.Context, dr Draft, fromState string, now time.Time) error {
+	res, err := d.sql.ExecContext(ctx, `UPDATE drafts SET updated=?, text=?, scheduled=?, state=?, posted_id=?, note=? WHERE id=? AND state=?`,
+		now.Unix(), dr.Text, dr.Scheduled.Unix(), dr.State, dr.PostedID, dr.Note, dr.ID, fromState)
+	if err != nil { return err }
+	if n, _ := res.RowsAffected(); n == 0 { return ErrNotFound }
+	return nil
+}
+
+// ExpireDrafts moves pending/approved drafts whose target tweet was created before
+// cutoff to the expired state and returns how many changed.
+func (d *DB) ExpireDrafts(ctx context.Context, cutoff, now time.Time) (int, error) {
+	res, err := d.sql.ExecContext(ctx, `UPDATE drafts SET state='expired', updated=?, note='target tweet too old' WHERE state IN ('pending','approved') AND tweet_created>0 AND tweet_created<?`,
+		now.Unix(), cutoff.Unix())
+	if err != nil { return 0, err }
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+func repeatPlaceholders(n int) string {
+	s := ""
+	for i := 0; i < n; i++ { s += ",?" }
+	return s
+}
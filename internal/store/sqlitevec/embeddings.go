@@ -0,0 +1,49 @@
+package sqlitevec
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"strings"
+	"time"
+)
+
+// PutEmbeddings stores vectors by key for an embedding model, replacing earlier ones.
+func (d *DB) PutEmbeddings(ctx context.Context, model string, vecs map[string][]float32, now time.Time) error {
+	tx, err := d.sql.BeginTx(ctx, nil)
+	if err != nil { return err }
+	defer tx.Rollback()
+	stmt, err := tx.PrepareContext(ctx, `INSERT OR REPLACE INTO embeddings(model, key, vec, updated_at) VALUES(?,?,?,?)`)
+	if err != nil { return err }
+	defer stmt.Close()
+	for k, v := range vecs {
+		b := make([]byte, 4*len(v))
+		for i, x := range v { binary.LittleEndian.PutUint32(b[4*i:], math.Float32bits(x)) }
+		if _, err := stmt.ExecContext(ctx, model, k, b, now.Unix()); err != nil { return err }
+	}
+	return tx.Commit()
+}
+
+// GetEmbeddings returns the stored vectors of model for the keys that have one.
+func (d *DB) GetEmbeddings(ctx context.Context, model string, keys []string) (map[string][]float32, error) {
+	out := make(map[string][]float32, len(keys))
+	for i := 0; i < len(keys); i += 500 {
+		end := i + 500
+		if end > len(keys) { end = len(keys) }
+		args := []any{model}
+		for _, k := range keys[i:end] { args = append(args, k) }
+		rows, err := d.sql.QueryContext(ctx, `SELECT key, vec FROM embeddings WHERE model=? AND key IN (?`+strings.Repeat(",?", end-i-1)+`)`, args...)
+		if err != nil { return out, err }
+		for rows.Next() {
+			var k string
+			var b []byte
+			if err := rows.Scan(&k, &b); err != nil { rows.Close(); return out, err }
+			v := make([]float32, len(b)/4)
+			for j := range v { v[j] = math.Float32frombits(binary.LittleEndian.Uint32(b[4*j:])) }
+			out[k] = v
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil { return out, err }
+	}
+	return out, nil
+}
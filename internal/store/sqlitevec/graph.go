@@ -0,0 +1,205 @@
+package sqlitevec
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"starseed/internal/model"
+)
+
+// Edge change kinds.
+const (
+	EdgeFollow   = "follow"
+	EdgeUnfollow = "unfollow"
+)
+
+// EdgeChange is a follow or unfollow seen between two syncs of src's adjacency list.
+type EdgeChange struct {
+	TS   time.Time
+	Src  string
+	Dst  string
+	Kind string
+}
+
+// Edge is a stored follow edge (src follows dst).
+type Edge struct {
+	Src       string
+	Dst       string
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// PutUsers upserts user profiles seen at now.
+func (d *DB) PutUsers(ctx context.Context, users []model.User, now time.Time) error {
+	tx, err := d.sql.BeginTx(ctx, nil)
+	if err != nil { return err }
+	defer tx.Rollback()
+	stmt, err := tx.PrepareContext(ctx, `INSERT OR REPLACE INTO users(id, username, name, description, created_at, followers, following, tweets, listed, verified, default_image, url, updated_at) VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?)`)
+	if err != nil { return err }
+	defer stmt.Close()
+	for _, u := range users {
+		if u.ID == "" { continue }
+		var created int64
+		if !u.CreatedAt.IsZero() { created = u.CreatedAt.Unix() }
+		if _, err := stmt.ExecContext(ctx, u.ID, u.Username, u.Name, u.Description, created, u.FollowersCount, u.FollowingCount, u.TweetCount, u.ListedCount, u.Verified, u.DefaultImage, u.URL, now.Unix()); err != nil { return err }
+	}
+	return tx.Commit()
+}
+
+// GetUsers returns stored profiles by ID; unknown IDs are missing from the map.
+func (d *DB) GetUsers(ctx context.Context, ids []string) (map[string]model.User, error) {
+	out := make(map[string]model.User, len(ids))
+	for i := 0; i < len(ids); i += 500 {
+		end := i + 500
+		if end > len(ids) { end = len(ids) }
+		chunk := ids[i:end]
+		args := make([]any, len(chunk))
+		for j, id := range chunk { args[j] = id }
+		rows, err := d.sql.QueryContext(ctx, `SELECT id, username, name, description, created_at, followers, following, tweets, listed, verified, default_image, url FROM users WHERE id IN (?`+strings.Repeat(",?", len(chunk)-1)+`)`, args...)
+		if err != nil { return out, err }
+		for rows.Next() {
+			var u model.User
+			var created int64
+			var username, name, desc, url sql.NullString
+			if err := rows.Scan(&u.ID, &username, &name, &desc, &created, &u.FollowersCount, &u.FollowingCount, &u.TweetCount, &u.ListedCount, &u.Verified, &u.DefaultImage, &url); err != nil { rows.Close(); return out, err }
+			u.Username, u.Name, u.Description, u.URL = username.String, name.String, desc.String, url.String
+			if created != 0 { u.CreatedAt = time.Unix(created, 0).UTC() }
+			out[u.ID] = u
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil { return out, err }
+	}
+	return out, nil
+}
+
+// UserIDByUsername returns the stored ID for a handle (case-insensitive, ErrNotFound if
+// unknown).
+func (d *DB) UserIDByUsername(ctx context.Context, username string) (string, error) {
+	var id string
+	err := d.sql.QueryRowContext(ctx, `SELECT id FROM users WHERE lower(username)=lower(?) LIMIT 1`, strings.TrimPrefix(username, "@")).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) { return "", ErrNotFound }
+	return id, err
+}
+
+// ReplaceFollowing stores src's following list fetched at now with at most limit entries
+// (0 = uncapped). A list that reached limit may be truncated: its edges are added and
+// refreshed but missing ones are kept. A complete list also removes missing edges.
+// Additions and removals are recorded in edge_changes and returned only when this and
+// the previous snapshot of src are both complete.
+func (d *DB) ReplaceFollowing(ctx context.Context, src string, dsts []string, limit int, now time.Time) ([]EdgeChange, error) {
+	tx, err := d.sql.BeginTx(ctx, nil)
+	if err != nil { return nil, err }
+	defer tx.Rollback()
+	var count int
+	var prevCap sql.NullInt64
+	err = tx.QueryRowContext(ctx, `SELECT count, cap FROM adjacency_sync WHERE src=?`, src).Scan(&count, &prevCap)
+	if err != nil && err != sql.ErrNoRows { return nil, err }
+	// Lists stored before the cap was recorded count as incomplete
+	prevComplete := err == nil && prevCap.Valid && (prevCap.Int64 == 0 || int64(count) < prevCap.Int64)
+	old := map[string]bool{}
+	rows, err := tx.QueryContext(ctx, `SELECT dst FROM edges WHERE src=?`, src)
+	if err != nil { return nil, err }
+	for rows.Next() {
+		var dst string
+		if err := rows.Scan(&dst); err != nil { rows.Close(); return nil, err }
+		old[dst] = true
+	}
+	rows.Close()
+	var changes []EdgeChange
+	cur := map[string]bool{}
+	for _, dst := range dsts {
+		if cur[dst] { continue }
+		cur[dst] = true
+		if _, err := tx.ExecContext(ctx, `INSERT INTO edges(src, dst, first_seen, last_seen) VALUES(?,?,?,?) ON CONFLICT(src, dst) DO UPDATE SET last_seen=excluded.last_seen`, src, dst, now.Unix(), now.Unix()); err != nil { return nil, err }
+		if !old[dst] { changes = append(changes, EdgeChange{TS: now, Src: src, Dst: dst, Kind: EdgeFollow}) }
+	}
+	complete := limit <= 0 || len(cur) < limit
+	if complete {
+		for dst := range old {
+			if cur[dst] { continue }
+			if _, err := tx.ExecContext(ctx, `DELETE FROM edges WHERE src=? AND dst=?`, src, dst); err != nil { return nil, err }
+			changes = append(changes, EdgeChange{TS: now, Src: src, Dst: dst, Kind: EdgeUnfollow})
+		}
+	}
+	if !complete || !prevComplete { changes = nil }
+	for _, c := range changes {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO edge_changes(ts, src, dst, kind) VALUES(?,?,?,?)`, c.TS.Unix(), c.Src, c.Dst, c.Kind); err != nil { return nil, err }
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT OR REPLACE INTO adjacency_sync(src, synced_at, count, cap) VALUES(?,?,?,?)`, src, now.Unix(), len(cur), max(limit, 0)); err != nil { return nil, err }
+	return changes, tx.Commit()
+}
+
+// Following returns the stored accounts src follows, in the order first seen.
+func (d *DB) Following(ctx context.Context, src string) ([]string, error) {
+	return d.ids(ctx, `SELECT dst FROM edges WHERE src=? ORDER BY first_seen, rowid`, src)
+}
+
+// Followers returns the stored accounts following dst.
+func (d *DB) Followers(ctx context.Context, dst string) ([]string, error) {
+	return d.ids(ctx, `SELECT src FROM edges WHERE dst=? ORDER BY first_seen, rowid`, dst)
+}
+
+func (d *DB) ids(ctx context.Context, q string, args ...any) ([]string, error) {
+	rows, err := d.sql.QueryContext(ctx, q, args...)
+	if err != nil { return nil, err }
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil { return nil, err }
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
+
+// Edges returns every stored edge.
+func (d *DB) Edges(ctx context.Context) ([]Edge, error) {
+	rows, err := d.sql.QueryContext(ctx, `SELECT src, dst, first_seen, last_seen FROM edges ORDER BY src, first_seen, rowid`)
+	if err != nil { return nil, err }
+	defer rows.Close()
+	var out []Edge
+	for rows.Next() {
+		var e Edge
+		var first, last int64
+		if err := rows.Scan(&e.Src, &e.Dst, &first, &last); err != nil { return nil, err }
+		e.FirstSeen, e.LastSeen = time.Unix(first, 0).UTC(), time.Unix(last, 0).UTC()
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// AdjacencySyncedAt returns when each of ids last had its following list synced;
+// never-synced IDs are missing.
+func (d *DB) AdjacencySyncedAt(ctx context.Context, ids []string) (map[string]time.Time, error) {
+	out := make(map[string]time.Time, len(ids))
+	for _, id := range ids {
+		var ts int64
+		err := d.sql.QueryRowContext(ctx, `SELECT synced_at FROM adjacency_sync WHERE src=?`, id).Scan(&ts)
+		if err == sql.ErrNoRows { continue }
+		if err != nil { return out, err }
+		out[id] = time.Unix(ts, 0).UTC()
+	}
+	return out, nil
+}
+
+// EdgeChanges returns follow/unfollow changes at or after since, newest first (limit 0 = all).
+func (d *DB) EdgeChanges(ctx context.Context, since time.Time, limit int) ([]EdgeChange, error) {
+	q := `SELECT ts, src, dst, kind FROM edge_changes WHERE ts>=? ORDER BY ts DESC, id DESC`
+	args := []any{since.Unix()}
+	if limit > 0 { q += ` LIMIT ?`; args = append(args, limit) }
+	rows, err := d.sql.QueryContext(ctx, q, args...)
+	if err != nil { return nil, err }
+	defer rows.Close()
+	var out []EdgeChange
+	for rows.Next() {
+		var c EdgeChange
+		var ts int64
+		if err := rows.Scan(&ts, &c.Src, &c.Dst, &c.Kind); err != nil { return nil, err }
+		c.TS = time.Unix(ts, 0).UTC()
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
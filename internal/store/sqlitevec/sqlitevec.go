@@ -59,7 +59,167 @@ func (d *DB) migrate() error {
 	  type TEXT NOT NULL
 	);
 	CREATE INDEX IF NOT EXISTS idx_actions_ts ON actions(ts);
+	CREATE TABLE IF NOT EXISTS model_stats (
+	  model TEXT PRIMARY KEY,
+	  created INTEGER NOT NULL,
+	  stats TEXT NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS tune_trials (
+	  id INTEGER PRIMARY KEY AUTOINCREMENT,
+	  run_id TEXT NOT NULL,
+	  ts INTEGER NOT NULL,
+	  params TEXT NOT NULL,
+	  score REAL,
+	  folds TEXT,
+	  error TEXT
+	);
+	CREATE INDEX IF NOT EXISTS idx_tune_run ON tune_trials(run_id);
+	CREATE TABLE IF NOT EXISTS reply_candidates (
+	  id INTEGER PRIMARY KEY AUTOINCREMENT,
+	  ts INTEGER NOT NULL,
+	  tweet_id TEXT NOT NULL,
+	  rank INTEGER NOT NULL,
+	  source TEXT NOT NULL,
+	  text TEXT NOT NULL,
+	  score REAL,
+	  detail TEXT
+	);
+	CREATE INDEX IF NOT EXISTS idx_candidates_tweet ON reply_candidates(tweet_id);
+	CREATE TABLE IF NOT EXISTS drafts (
+	  id INTEGER PRIMARY KEY AUTOINCREMENT,
+	  created INTEGER NOT NULL,
+	  updated INTEGER NOT NULL,
+	  tweet_id TEXT NOT NULL UNIQUE,
+	  author_id TEXT,
+	  tweet_text TEXT,
+	  tweet_created INTEGER,
+	  text TEXT NOT NULL,
+	  scheduled INTEGER NOT NULL,
+	  state TEXT NOT NULL,
+	  score REAL,
+	  why TEXT,
+	  posted_id TEXT,
+	  note TEXT,
+	  meta TEXT
+	);
+	CREATE INDEX IF NOT EXISTS idx_drafts_state ON drafts(state, scheduled);
+	CREATE TABLE IF NOT EXISTS decisions (
+	  id INTEGER PRIMARY KEY AUTOINCREMENT,
+	  ts INTEGER NOT NULL,
+	  kind TEXT NOT NULL,
+	  outcome TEXT NOT NULL,
+	  draft_id INTEGER,
+	  tweet_id TEXT,
+	  author_id TEXT,
+	  author TEXT,
+	  reason TEXT,
+	  detail TEXT
+	);
+	CREATE INDEX IF NOT EXISTS idx_decisions_ts ON decisions(ts);
+	CREATE TABLE IF NOT EXISTS tweets (
+	  id TEXT PRIMARY KEY,
+	  author_id TEXT NOT NULL,
+	  created_at INTEGER,
+	  text TEXT,
+	  lang TEXT,
+	  likes INTEGER,
+	  replies INTEGER,
+	  retweets INTEGER,
+	  quotes INTEGER,
+	  has_link INTEGER,
+	  conversation_id TEXT,
+	  reply_to_id TEXT,
+	  fetched_at INTEGER NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_tweets_author ON tweets(author_id, created_at);
+	CREATE TABLE IF NOT EXISTS timeline_fetches (
+	  author_id TEXT PRIMARY KEY,
+	  fetched_at INTEGER NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS users (
+	  id TEXT PRIMARY KEY,
+	  username TEXT,
+	  name TEXT,
+	  description TEXT,
+	  created_at INTEGER,
+	  followers INTEGER,
+	  following INTEGER,
+	  tweets INTEGER,
+	  listed INTEGER,
+	  verified INTEGER,
+	  default_image INTEGER,
+	  url TEXT,
+	  updated_at INTEGER NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS edges (
+	  src TEXT NOT NULL,
+	  dst TEXT NOT NULL,
+	  first_seen INTEGER NOT NULL,
+	  last_seen INTEGER NOT NULL,
+	  PRIMARY KEY(src, dst)
+	);
+	CREATE INDEX IF NOT EXISTS idx_edges_dst ON edges(dst);
+	CREATE TABLE IF NOT EXISTS edge_changes (
+	  id INTEGER PRIMARY KEY AUTOINCREMENT,
+	  ts INTEGER NOT NULL,
+	  src TEXT NOT NULL,
+	  dst TEXT NOT NULL,
+	  kind TEXT NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_edge_changes_ts ON edge_changes(ts);
+	CREATE TABLE IF NOT EXISTS adjacency_sync (
+	  src TEXT PRIMARY KEY,
+	  synced_at INTEGER NOT NULL,
+	  count INTEGER NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS audit_snapshots (
+	  ts INTEGER PRIMARY KEY,
+	  following INTEGER NOT NULL,
+	  followers INTEGER NOT NULL,
+	  mutual INTEGER NOT NULL,
+	  suspicious INTEGER NOT NULL,
+	  mean_bot REAL,
+	  mean_organic REAL,
+	  detail TEXT
+	);
+	CREATE TABLE IF NOT EXISTS embeddings (
+	  model TEXT NOT NULL,
+	  key TEXT NOT NULL,
+	  vec BLOB NOT NULL,
+	  updated_at INTEGER NOT NULL,
+	  PRIMARY KEY(model, key)
+	);
 	`)
+	if err != nil { return err }
+	// Columns added after the first release
+	for _, c := range []struct{ table, col, typ string }{
+		{"actions", "target_user", "TEXT"},
+		{"actions", "conversation_id", "TEXT"},
+		{"decisions", "author_id", "TEXT"},
+		{"decisions", "author", "TEXT"},
+		{"adjacency_sync", "cap", "INTEGER"},
+	} {
+		if err := d.addColumn(c.table, c.col, c.typ); err != nil { return err }
+	}
+	_, err = d.sql.Exec(`CREATE INDEX IF NOT EXISTS idx_actions_target ON actions(target_user, ts);
+	CREATE INDEX IF NOT EXISTS idx_actions_conversation ON actions(conversation_id, ts);`)
+	return err
+}
+
+// addColumn adds col to table unless it already exists.
+func (d *DB) addColumn(table, col, typ string) error {
+	rows, err := d.sql.Query(`PRAGMA table_info(` + table + `)`)
+	if err != nil { return err }
+	defer rows.Close()
+	for rows.Next() {
+		var cid, notnull, pk int
+		var name, ctype string
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &ctype, &notnull, &dflt, &pk); err != nil { return err }
+		if name == col { return nil }
+	}
+	if err := rows.Err(); err != nil { return err }
+	_, err = d.sql.Exec(`ALTER TABLE ` + table + ` ADD COLUMN ` + col + ` ` + typ)
 	return err
 }
 
@@ -167,12 +327,97 @@ func (d *DB) LoadThreshold(ctx context.Context) (float64, error) {
 	return thr.Float64, nil
 }
 
+// SaveModelStats stores training-time statistics for a model path, replacing older ones.
+func (d *DB) SaveModelStats(ctx context.Context, model string, created time.Time, stats any) error {
+    sb, err := json.Marshal(stats)
+    if err != nil { return err }
+    _, err = d.sql.ExecContext(ctx, `INSERT INTO model_stats(model, created, stats) VALUES(?,?,?) ON CONFLICT(model) DO UPDATE SET created=excluded.created, stats=excluded.stats`, model, created.Unix(), string(sb))
+    return err
+}
+
+// LoadModelStats returns the stats JSON stored for a model path.
+func (d *DB) LoadModelStats(ctx context.Context, model string) (string, error) {
+    row := d.sql.QueryRowContext(ctx, `SELECT stats FROM model_stats WHERE model=?`, model)
+    var s string
+    if err := row.Scan(&s); err != nil { return "", err }
+    return s, nil
+}
+
+// PutTuneTrial stores one hyperparameter trial of a tuning run.
+func (d *DB) PutTuneTrial(ctx context.Context, runID string, ts time.Time, params any, score float64, folds any, errStr string) error {
+    pb, err := json.Marshal(params)
+    if err != nil { return err }
+    fb, _ := json.Marshal(folds)
+    _, err = d.sql.ExecContext(ctx, `INSERT INTO tune_trials(run_id, ts, params, score, folds, error) VALUES(?,?,?,?,?,?)`, runID, ts.Unix(), string(pb), score, string(fb), errStr)
+    return err
+}
+
+// BestTuneTrial returns params JSON and score of the lowest-scoring successful trial in the latest run.
+func (d *DB) BestTuneTrial(ctx context.Context) (string, float64, error) {
+    row := d.sql.QueryRowContext(ctx, `SELECT params, score FROM tune_trials
+        WHERE run_id=(SELECT run_id FROM tune_trials ORDER BY id DESC LIMIT 1) AND COALESCE(error,'')=''
+        ORDER BY score ASC LIMIT 1`)
+    var params string
+    var score float64
+    if err := row.Scan(&params, &score); err != nil { return "", 0, err }
+    return params, score, nil
+}
+
+// ReplyCandidate is one scored draft generated for a tweet.
+type ReplyCandidate struct {
+	TS      time.Time
+	TweetID string
+	Rank    int
+	Source  string
+	Text    string
+	Score   float64
+	Detail  string // JSON score breakdown
+}
+
+// PutReplyCandidates stores a generated candidate set (rank 0 is the selected draft).
+func (d *DB) PutReplyCandidates(ctx context.Context, cands []ReplyCandidate) error {
+	tx, err := d.sql.BeginTx(ctx, nil)
+	if err != nil { return err }
+	for _, c := range cands {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO reply_candidates(ts, tweet_id, rank, source, text, score, detail) VALUES(?,?,?,?,?,?,?)`,
+			c.TS.Unix(), c.TweetID, c.Rank, c.Source, c.Text, c.Score, c.Detail); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// LoadReplyCandidates returns stored candidates for a tweet, newest generation first, then by
+// rank with rejected drafts last.
+func (d *DB) LoadReplyCandidates(ctx context.Context, tweetID string) ([]ReplyCandidate, error) {
+	rows, err := d.sql.QueryContext(ctx, `SELECT ts, tweet_id, rank, source, text, score, detail FROM reply_candidates WHERE tweet_id=? ORDER BY ts DESC, rank < 0, rank`, tweetID)
+	if err != nil { return nil, err }
+	defer rows.Close()
+	var out []ReplyCandidate
+	for rows.Next() {
+		var c ReplyCandidate
+		var ts int64
+		var detail sql.NullString
+		if err := rows.Scan(&ts, &c.TweetID, &c.Rank, &c.Source, &c.Text, &c.Score, &detail); err != nil { return nil, err }
+		c.TS, c.Detail = time.Unix(ts, 0).UTC(), detail.String
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
 // Cursor helpers
 func (d *DB) SaveCursor(ctx context.Context, key, value string) error {
     _, err := d.sql.ExecContext(ctx, `INSERT INTO cursors(key, value) VALUES(?,?) ON CONFLICT(key) DO UPDATE SET value=excluded.value`, key, value)
     return err
 }
 
+// DeleteCursor removes key (a no-op when it is not set).
+func (d *DB) DeleteCursor(ctx context.Context, key string) error {
+    _, err := d.sql.ExecContext(ctx, `DELETE FROM cursors WHERE key=?`, key)
+    return err
+}
+
 func (d *DB) LoadCursor(ctx context.Context, key string) (string, error) {
     row := d.sql.QueryRowContext(ctx, `SELECT value FROM cursors WHERE key=?`, key)
     var v sql.NullString
@@ -183,10 +428,22 @@ func (d *DB) LoadCursor(ctx context.Context, key string) (string, error) {
 
 // Action helpers
 func (d *DB) PutAction(ctx context.Context, ts time.Time, typ string) error {
-    _, err := d.sql.ExecContext(ctx, `INSERT INTO actions(ts, type) VALUES(?,?)`, ts.Unix(), typ)
+    return d.PutActionTarget(ctx, ts, typ, "", "")
+}
+
+// PutActionTarget records an action aimed at a user and/or conversation (either may be empty).
+func (d *DB) PutActionTarget(ctx context.Context, ts time.Time, typ, targetUser, conversationID string) error {
+    _, err := d.sql.ExecContext(ctx, `INSERT INTO actions(ts, type, target_user, conversation_id) VALUES(?,?,?,?)`, ts.Unix(), typ, targetUser, conversationID)
     return err
 }
 
+// ActionFilter narrows ActionTimesWhere; empty fields match everything.
+type ActionFilter struct {
+    Type           string
+    TargetUser     string
+    ConversationID string
+}
+
 func (d *DB) CountActionsWithin(ctx context.Context, start, end time.Time, typ string) (int, error) {
     var row *sql.Row
     if typ == "" {
@@ -199,6 +456,31 @@ func (d *DB) CountActionsWithin(ctx context.Context, start, end time.Time, typ s
     return n, nil
 }
 
+// ActionTimes returns action timestamps in [start, end) in ascending order; an empty typ
+// matches every type.
+func (d *DB) ActionTimes(ctx context.Context, start, end time.Time, typ string) ([]time.Time, error) {
+    return d.ActionTimesWhere(ctx, start, end, ActionFilter{Type: typ})
+}
+
+// ActionTimesWhere returns timestamps in [start, end) of actions matching f, ascending.
+func (d *DB) ActionTimesWhere(ctx context.Context, start, end time.Time, f ActionFilter) ([]time.Time, error) {
+    q := `SELECT ts FROM actions WHERE ts>=? AND ts<?`
+    args := []any{start.Unix(), end.Unix()}
+    if f.Type != "" { q += ` AND type=?`; args = append(args, f.Type) }
+    if f.TargetUser != "" { q += ` AND target_user=?`; args = append(args, f.TargetUser) }
+    if f.ConversationID != "" { q += ` AND conversation_id=?`; args = append(args, f.ConversationID) }
+    rows, err := d.sql.QueryContext(ctx, q+` ORDER BY ts`, args...)
+    if err != nil { return nil, err }
+    defer rows.Close()
+    var out []time.Time
+    for rows.Next() {
+        var ts int64
+        if err := rows.Scan(&ts); err != nil { return nil, err }
+        out = append(out, time.Unix(ts, 0).UTC())
+    }
+    return out, rows.Err()
+}
+
 func encodeF32(v []float32) []byte {
     b := make([]byte, 4*len(v))
     for i := range v { binary.LittleEndian.PutUint32(b[4*i:], math.Float32bits(v[i])) }
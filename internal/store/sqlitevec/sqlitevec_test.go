@@ -2,6 +2,8 @@ package sqlitevec
 
 import (
 	"context"
+	"database/sql"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -18,3 +20,34 @@ func TestCursorsAndActions(t *testing.T) {
 	n, err := db.CountActionsWithin(ctx, time.Now().UTC().Add(-time.Hour), time.Now().UTC().Add(time.Hour), "reply")
 	if err != nil || n != 1 { t.Fatalf("action count mismatch: %v %d", err, n) }
 }
+
+func TestMigrateAddsActionTargetColumns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.db")
+	raw, err := sql.Open("sqlite", path)
+	if err != nil { t.Fatal(err) }
+	if _, err := raw.Exec(`CREATE TABLE actions (id INTEGER PRIMARY KEY AUTOINCREMENT, ts INTEGER NOT NULL, type TEXT NOT NULL); INSERT INTO actions(ts, type) VALUES(100, 'engage')`); err != nil { t.Fatal(err) }
+	_ = raw.Close()
+	db, err := Open(path)
+	if err != nil { t.Fatalf("open old schema: %v", err) }
+	ctx := context.Background()
+	if err := db.PutActionTarget(ctx, time.Unix(200, 0), "reply", "u1", "c1"); err != nil { t.Fatal(err) }
+	ts, err := db.ActionTimesWhere(ctx, time.Unix(0, 0), time.Unix(300, 0), ActionFilter{TargetUser: "u1"})
+	if err != nil || len(ts) != 1 { t.Fatalf("expected one targeted action, got %v %v", ts, err) }
+	if n, _ := db.CountActionsWithin(ctx, time.Unix(0, 0), time.Unix(300, 0), ""); n != 2 { t.Fatalf("old rows lost: %d", n) }
+	_ = db.Close()
+	// Reopening is a no-op
+	db, err = Open(path)
+	if err != nil { t.Fatalf("reopen: %v", err) }
+	_ = db.Close()
+}
+
+func TestDraftStates(t *testing.T) {
+	db, err := Open(":memory:")
+	if err != nil { t.Fatal(err) }
+	defer db.Close()
+	ctx := context.Background()
+	now := time.Now().UTC()
+	if _, _, err := db.PutDraft(ctx, Draft{Created: now, TweetID: "t1", Text: "hi", State: "rejected"}); err != nil { t.Fatal(err) }
+	states, err := db.DraftStates(ctx, []string{"t1", "t2"})
+	if err != nil || len(states) != 1 || states["t1"] != "rejected" { t.Fatalf("unexpected states %v %v", states, err) }
+}
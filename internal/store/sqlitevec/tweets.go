@@ -0,0 +1,176 @@
+package sqlitevec
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"starseed/internal/model"
+)
+
+// PutTweets caches tweets (replacing earlier copies, so metrics stay fresh) as fetched at.
+func (d *DB) PutTweets(ctx context.Context, tweets []model.Tweet, fetched time.Time) error {
+	tx, err := d.sql.BeginTx(ctx, nil)
+	if err != nil { return err }
+	defer tx.Rollback()
+	stmt, err := tx.PrepareContext(ctx, `INSERT OR REPLACE INTO tweets(id, author_id, created_at, text, lang, likes, replies, retweets, quotes, has_link, conversation_id, reply_to_id, fetched_at) VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?)`)
+	if err != nil { return err }
+	defer stmt.Close()
+	for _, t := range tweets {
+		link := 0
+		if t.HasLink { link = 1 }
+		if _, err := stmt.ExecContext(ctx, t.ID, t.AuthorID, t.CreatedAt.Unix(), t.Text, t.Language, t.LikeCount, t.ReplyCount, t.RetweetCount, t.QuoteCount, link, t.ConversationID, t.ReplyToID, fetched.Unix()); err != nil { return err }
+	}
+	return tx.Commit()
+}
+
+const tweetCols = `id, author_id, created_at, text, lang, likes, replies, retweets, quotes, has_link, conversation_id, reply_to_id`
+
+// TweetsByAuthor returns up to limit cached tweets by authorID, newest first (0 = all).
+func (d *DB) TweetsByAuthor(ctx context.Context, authorID string, limit int) ([]model.Tweet, error) {
+	q := `SELECT ` + tweetCols + ` FROM tweets WHERE author_id=? ORDER BY created_at DESC`
+	args := []any{authorID}
+	if limit > 0 { q += ` LIMIT ?`; args = append(args, limit) }
+	return d.queryTweets(ctx, q, args...)
+}
+
+// RecentTweets returns up to limit cached tweets by anyone, newest first (0 = all).
+func (d *DB) RecentTweets(ctx context.Context, limit int) ([]model.Tweet, error) {
+	q := `SELECT ` + tweetCols + ` FROM tweets ORDER BY created_at DESC`
+	var args []any
+	if limit > 0 { q += ` LIMIT ?`; args = append(args, limit) }
+	return d.queryTweets(ctx, q, args...)
+}
+
+func (d *DB) queryTweets(ctx context.Context, q string, args ...any) ([]model.Tweet, error) {
+	rows, err := d.sql.QueryContext(ctx, q, args...)
+	if err != nil { return nil, err }
+	defer rows.Close()
+	var out []model.Tweet
+	for rows.Next() {
+		var t model.Tweet
+		var created int64
+		var link int
+		var lang, conv, reply sql.NullString
+		if err := rows.Scan(&t.ID, &t.AuthorID, &created, &t.Text, &lang, &t.LikeCount, &t.ReplyCount, &t.RetweetCount, &t.QuoteCount, &link, &conv, &reply); err != nil { return nil, err }
+		t.CreatedAt, t.HasLink, t.Language, t.ConversationID, t.ReplyToID = time.Unix(created, 0).UTC(), link == 1, lang.String, conv.String, reply.String
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// TweetsFetchedAt returns when tweets by authorID were last cached (zero if never).
+func (d *DB) TweetsFetchedAt(ctx context.Context, authorID string) (time.Time, error) {
+	m, err := d.TimelinesFetchedAt(ctx, []string{authorID})
+	return m[authorID], err
+}
+
+// MarkTimelineFetched records that authorID's timeline was fetched at, so an account
+// with no tweets counts as cached too.
+func (d *DB) MarkTimelineFetched(ctx context.Context, authorID string, at time.Time) error {
+	_, err := d.sql.ExecContext(ctx, `INSERT INTO timeline_fetches(author_id, fetched_at) VALUES(?,?) ON CONFLICT(author_id) DO UPDATE SET fetched_at=excluded.fetched_at`, authorID, at.Unix())
+	return err
+}
+
+// TimelinesFetchedAt maps each of authorIDs that was ever fetched to when its timeline
+// was last cached, from fetch marks and cached tweets.
+func (d *DB) TimelinesFetchedAt(ctx context.Context, authorIDs []string) (map[string]time.Time, error) {
+	out := make(map[string]time.Time, len(authorIDs))
+	for i := 0; i < len(authorIDs); i += 500 {
+		end := i + 500
+		if end > len(authorIDs) { end = len(authorIDs) }
+		args := make([]any, end-i)
+		for j, id := range authorIDs[i:end] { args[j] = id }
+		in := `(?` + strings.Repeat(",?", len(args)-1) + `)`
+		rows, err := d.sql.QueryContext(ctx, `SELECT author_id, MAX(ts) FROM (
+			SELECT author_id, MAX(fetched_at) AS ts FROM tweets WHERE author_id IN `+in+` GROUP BY author_id
+			UNION ALL SELECT author_id, fetched_at FROM timeline_fetches WHERE author_id IN `+in+`
+		) GROUP BY author_id`, append(args, args...)...)
+		if err != nil { return out, err }
+		for rows.Next() {
+			var id string
+			var ts int64
+			if err := rows.Scan(&id, &ts); err != nil { rows.Close(); return out, err }
+			out[id] = time.Unix(ts, 0).UTC()
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil { return out, err }
+	}
+	return out, nil
+}
+
+// TweetTexts returns the text of up to limit cached tweets, newest first (0 = all).
+func (d *DB) TweetTexts(ctx context.Context, limit int) ([]string, error) {
+	q := `SELECT text FROM tweets WHERE text<>'' ORDER BY created_at DESC`
+	var args []any
+	if limit > 0 { q += ` LIMIT ?`; args = append(args, limit) }
+	rows, err := d.sql.QueryContext(ctx, q, args...)
+	if err != nil { return nil, err }
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var t string
+		if err := rows.Scan(&t); err != nil { return nil, err }
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// TweetAuthors maps the given tweet IDs to their authors for tweets in the cache.
+func (d *DB) TweetAuthors(ctx context.Context, ids []string) (map[string]string, error) {
+	return d.lookup(ctx, `SELECT id, author_id FROM tweets WHERE id IN %s`, ids)
+}
+
+// ReplyTargets maps tweet IDs we replied to (or their conversation IDs) to the account
+// replied to, from drafts and targeted reply actions, for replies whose parent tweet is
+// not cached.
+func (d *DB) ReplyTargets(ctx context.Context, tweetIDs, conversationIDs []string) (byTweet, byConversation map[string]string, err error) {
+	byTweet, err = d.lookup(ctx, `SELECT tweet_id, author_id FROM drafts WHERE author_id<>'' AND tweet_id IN %s`, tweetIDs)
+	if err != nil { return nil, nil, err }
+	byConversation, err = d.lookup(ctx, `SELECT conversation_id, target_user FROM actions WHERE type='reply' AND target_user<>'' AND conversation_id IN %s ORDER BY ts`, conversationIDs)
+	return byTweet, byConversation, err
+}
+
+// lookup runs a two-column key/value query whose %s is an IN list, 500 keys at a time.
+func (d *DB) lookup(ctx context.Context, q string, keys []string) (map[string]string, error) {
+	out := make(map[string]string, len(keys))
+	for i := 0; i < len(keys); i += 500 {
+		end := i + 500
+		if end > len(keys) { end = len(keys) }
+		args := make([]any, end-i)
+		for j, k := range keys[i:end] { args[j] = k }
+		rows, err := d.sql.QueryContext(ctx, fmt.Sprintf(q, `(?`+strings.Repeat(",?", len(args)-1)+`)`), args...)
+		if err != nil { return out, err }
+		for rows.Next() {
+			var k, v string
+			if err := rows.Scan(&k, &v); err != nil { rows.Close(); return out, err }
+			out[k] = v
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil { return out, err }
+	}
+	return out, nil
+}
+
+// ReplyEdge counts cached replies from Src to tweets by Dst.
+type ReplyEdge struct {
+	Src   string
+	Dst   string
+	Count int
+}
+
+// ReplyEdges returns who replied to whom among cached tweets (self-replies excluded).
+func (d *DB) ReplyEdges(ctx context.Context) ([]ReplyEdge, error) {
+	rows, err := d.sql.QueryContext(ctx, `SELECT r.author_id, p.author_id, COUNT(*) FROM tweets r JOIN tweets p ON p.id=r.reply_to_id WHERE r.reply_to_id<>'' AND r.author_id<>p.author_id GROUP BY r.author_id, p.author_id ORDER BY r.author_id, p.author_id`)
+	if err != nil { return nil, err }
+	defer rows.Close()
+	var out []ReplyEdge
+	for rows.Next() {
+		var e ReplyEdge
+		if err := rows.Scan(&e.Src, &e.Dst, &e.Count); err != nil { return nil, err }
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,201 @@
+package suggest
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"starseed/internal/config"
+	"starseed/internal/model"
+	"starseed/internal/store/sqlitevec"
+)
+
+// Candidate is one scored reply draft.
+type Candidate struct {
+	Text   string          `json:"text"`
+	Source string          `json:"source"` // "llm" or "heuristic"
+	Score  float64         `json:"score"`
+	Parts  CandidateScores `json:"parts"`
+	// Set when the validator dropped the draft
+	Rejection *Rejection `json:"rejection,omitempty"`
+}
+
+// CandidateScores are the per-criterion scores in [0,1].
+type CandidateScores struct {
+	Length    float64 `json:"length"`
+	Relevance float64 `json:"relevance"`
+	Novelty   float64 `json:"novelty"`
+	Safety    float64 `json:"safety"`
+}
+
+// Scorer rates candidates against our interests and past replies.
+type Scorer struct {
+	weights   config.ScoreWeights
+	interests config.InterestsConfig
+	maxLen    int
+	past      []map[string]bool // word trigrams of our previous replies
+}
+
+// NewScorer builds a Scorer; zero weights fall back to the defaults.
+func NewScorer(w config.ScoreWeights, interests config.InterestsConfig, maxLen int, pastReplies []string) *Scorer {
+	if w == (config.ScoreWeights{}) { w = config.Default().Suggest.Weights }
+	if maxLen <= 0 { maxLen = 220 }
+	s := &Scorer{weights: w, interests: interests, maxLen: maxLen}
+	for _, r := range pastReplies { s.past = append(s.past, ngrams(r, 3)) }
+	return s
+}
+
+// Score fills in c.Parts and the weighted c.Score.
+func (s *Scorer) Score(c *Candidate) {
+	n := utf8.RuneCountInString(c.Text)
+	c.Parts = CandidateScores{
+		Length:    lengthScore(n, s.maxLen),
+		Relevance: relevanceScore(c.Text, s.interests),
+		Novelty:   s.novelty(c.Text),
+		Safety:    safetyScore(c.Text),
+	}
+	w := s.weights
+	total := w.Length + w.Relevance + w.Novelty + w.Safety
+	if total == 0 { return }
+	c.Score = (w.Length*c.Parts.Length + w.Relevance*c.Parts.Relevance + w.Novelty*c.Parts.Novelty + w.Safety*c.Parts.Safety) / total
+}
+
+// lengthScore prefers replies between 60 and 80% of the limit.
+func lengthScore(n, maxLen int) float64 {
+	lo, hi := 60, maxLen*4/5
+	switch {
+	case n == 0 || n > maxLen:
+		return 0
+	case n < lo:
+		return float64(n) / float64(lo)
+	case n > hi:
+		return 1 - 0.5*float64(n-hi)/float64(maxLen-hi+1)
+	}
+	return 1
+}
+
+// relevanceScore scales model.KeywordRelevance so about one weighted keyword per
+// four tokens saturates; a higher semantic score (model.SetRelevance) wins.
+func relevanceScore(text string, in config.InterestsConfig) float64 {
+	kw := append(append([]string{}, in.Keywords...), in.Topics...)
+	r := model.KeywordRelevance(text, kw, in.Weights) * 4
+	if r > 1 { r = 1 }
+	if s, ok := model.SemanticRelevance(text); ok && s > r { r = s }
+	return r
+}
+
+// novelty is one minus the highest trigram Jaccard similarity to a past reply.
+func (s *Scorer) novelty(text string) float64 {
+	sh := ngrams(text, 3)
+	best := 0.0
+	for _, p := range s.past {
+		if j := jaccard(sh, p); j > best { best = j }
+	}
+	return 1 - best
+}
+
+// safetyScore penalizes shouting, links, mentions spam and excessive punctuation.
+func safetyScore(text string) float64 {
+	score := 1.0
+	letters, upper := 0, 0
+	for _, r := range text {
+		if r >= 'A' && r <= 'Z' { upper++; letters++ } else if r >= 'a' && r <= 'z' { letters++ }
+	}
+	if letters > 20 && float64(upper)/float64(letters) > 0.5 { score -= 0.4 }
+	if strings.Contains(text, "http://") || strings.Contains(text, "https://") { score -= 0.3 }
+	if strings.Count(text, "@") > 2 { score -= 0.3 }
+	if strings.Contains(text, "!!") || strings.Contains(text, "??") { score -= 0.1 }
+	if score < 0 { score = 0 }
+	return score
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 { return 0 }
+	inter := 0
+	for k := range a {
+		if b[k] { inter++ }
+	}
+	return float64(inter) / float64(len(a)+len(b)-inter)
+}
+
+// Generate drafts candidates for d (LLM samples when a provider is configured plus the
+// heuristic variants), drops ones that break the persona, runs the validator, scores
+// the survivors and returns at most n of them best first, along with the rejected
+// drafts and their reasons. LLM errors are returned alongside the heuristic candidates.
+func Generate(ctx context.Context, cfg config.LLMConfig, pr *Prompter, sc *Scorer, v *Validator, d PromptData, n int) (kept, rejected []Candidate, err error) {
+	if n <= 0 { n = 3 }
+	dc := DraftContext{Tweet: d.Tweet, Author: d.Author, Thread: d.Thread, Replies: d.Replies}
+	seen := map[string]bool{}
+	add := func(text, source string) {
+		text, ok := pr.Finalize(text)
+		key := strings.ToLower(text)
+		if text == "" || seen[key] { return }
+		seen[key] = true
+		c := Candidate{Text: text, Source: source}
+		if !ok {
+			c.Rejection = &Rejection{Rule: "persona", Reason: "contains a banned phrase"}
+		} else {
+			c.Rejection = v.Validate(text, dc)
+		}
+		if c.Rejection != nil { rejected = append(rejected, c); return }
+		kept = append(kept, c)
+	}
+	var llmErr error
+	if p, err := NewProvider(cfg); err != nil {
+		llmErr = err
+	} else if p != nil {
+		prompt, err := pr.Prompt(d)
+		if err == nil {
+			prompt.N, prompt.MaxTokens, prompt.Temperature = n, cfg.MaxTokens, cfg.Temperature
+			var texts []string
+			var usage Usage
+			texts, usage, err = p.Draft(ctx, prompt)
+			recordUsage(cfg.Provider, cfg.Model, usage)
+			for _, t := range texts { add(t, "llm") }
+		}
+		llmErr = err
+	}
+	variants, err := pr.HeuristicVariants(d)
+	if err != nil && llmErr == nil { llmErr = err }
+	for _, v := range variants { add(v, "heuristic") }
+	// Embed the drafts together rather than one request per draft (errors score 0 and are
+	// logged by the embedder)
+	texts := make([]string, len(kept))
+	for i, c := range kept { texts[i] = c.Text }
+	_ = model.WarmRelevance(ctx, texts)
+	for i := range kept { sc.Score(&kept[i]) }
+	sort.SliceStable(kept, func(i, j int) bool { return kept[i].Score > kept[j].Score })
+	if len(kept) > n { kept = kept[:n] }
+	return kept, rejected, llmErr
+}
+
+// PastReplies returns the text of our replies recorded since the given time.
+func PastReplies(ctx context.Context, db *sqlitevec.DB, since, now time.Time) ([]string, error) {
+	evs, err := db.LoadEventsRange(ctx, since, now, "out_reply")
+	if err != nil { return nil, err }
+	var out []string
+	for _, e := range evs {
+		var p struct{ Text string `json:"text"` }
+		if json.Unmarshal([]byte(e.Payload), &p) == nil && p.Text != "" { out = append(out, p.Text) }
+	}
+	return out, nil
+}
+
+// SaveCandidates persists a tweet's candidate set for later analysis. Kept candidates are
+// ranked from 0; rejected drafts are stored with rank -1 and their rejection reason.
+func SaveCandidates(ctx context.Context, db *sqlitevec.DB, tweetID string, ts time.Time, kept, rejected []Candidate) error {
+	rows := make([]sqlitevec.ReplyCandidate, 0, len(kept)+len(rejected))
+	add := func(rank int, c Candidate) {
+		detail, _ := json.Marshal(struct {
+			Parts     CandidateScores `json:"parts"`
+			Rejection *Rejection      `json:"rejection,omitempty"`
+		}{c.Parts, c.Rejection})
+		rows = append(rows, sqlitevec.ReplyCandidate{TS: ts, TweetID: tweetID, Rank: rank, Source: c.Source, Text: c.Text, Score: c.Score, Detail: string(detail)})
+	}
+	for i, c := range kept { add(i, c) }
+	for _, c := range rejected { add(-1, c) }
+	return db.PutReplyCandidates(ctx, rows)
+}
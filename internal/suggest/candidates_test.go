@@ -0,0 +1,60 @@
+package suggest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"starseed/internal/config"
+	"starseed/internal/model"
+	"starseed/internal/store/sqlitevec"
+)
+
+func TestScorerNoveltyAndWeights(t *testing.T) {
+	past := []string{"Great thread on raft leader election, thanks for sharing the details"}
+	in := config.InterestsConfig{Keywords: []string{"raft", "golang"}}
+	repeat := Candidate{Text: "Great thread on raft leader election, thanks for sharing the details"}
+	fresh := Candidate{Text: "How does your golang raft implementation handle a partitioned leader during log compaction?"}
+	sc := NewScorer(config.ScoreWeights{Novelty: 1}, in, 220, past)
+	sc.Score(&repeat)
+	sc.Score(&fresh)
+	if repeat.Parts.Novelty != 0 || fresh.Parts.Novelty < 0.9 { t.Fatalf("novelty: repeat=%v fresh=%v", repeat.Parts.Novelty, fresh.Parts.Novelty) }
+	if fresh.Score <= repeat.Score { t.Fatalf("fresh reply should win on novelty") }
+	if fresh.Parts.Relevance == 0 { t.Fatalf("expected interest relevance for keyword-rich reply") }
+
+	shouty := Candidate{Text: "THIS IS ABSOLUTELY THE BEST TAKE EVER!! https://spam.example"}
+	NewScorer(config.ScoreWeights{}, in, 220, nil).Score(&shouty)
+	if shouty.Parts.Safety >= 0.5 { t.Fatalf("expected safety penalty, got %v", shouty.Parts.Safety) }
+}
+
+func TestGenerateMergesLLMAndHeuristicCandidates(t *testing.T) {
+	srv := stubServer(t, "/chat/completions", func(t *testing.T, r *http.Request, body map[string]any) (int, string) {
+		if body["n"] != float64(3) { t.Errorf("expected n=3, got %v", body["n"]) }
+		return 200, `{"choices":[{"message":{"content":"How do you handle golang raft snapshots when followers lag far behind the leader?"}},{"message":{"content":"nice"}},{"message":{"content":"this is a game changer"}}]}`
+	})
+	pr, _ := NewPrompter(config.PersonaConfig{BannedPhrases: []string{"game changer"}})
+	in := config.InterestsConfig{Keywords: []string{"raft", "golang"}}
+	sc := NewScorer(config.ScoreWeights{}, in, 220, nil)
+	d := PromptData{Tweet: model.Tweet{ID: "7", Text: "We rewrote our consensus layer"}, Interests: in}
+	v := NewValidator(config.DraftRules{Disabled: []string{"pii"}}, 220, nil)
+	cands, rejected, err := Generate(context.Background(), config.LLMConfig{Provider: "openai-compatible", BaseURL: srv.URL}, pr, sc, v, d, 3)
+	if err != nil { t.Fatal(err) }
+	if len(rejected) != 2 { t.Fatalf("expected banned and too-short drafts rejected, got %+v", rejected) }
+	if len(cands) != 3 { t.Fatalf("expected 3 candidates, got %d", len(cands)) }
+	if cands[0].Source != "llm" || cands[0].Score < cands[1].Score { t.Fatalf("expected best LLM draft first: %+v", cands) }
+	for _, c := range cands {
+		if c.Text == "this is a game changer" { t.Fatalf("banned candidate kept") }
+	}
+
+	db, _ := sqlitevec.Open(":memory:")
+	defer db.Close()
+	ctx := context.Background()
+	now := time.Now().UTC()
+	if err := SaveCandidates(ctx, db, "7", now, cands, rejected); err != nil { t.Fatal(err) }
+	rows, err := db.LoadReplyCandidates(ctx, "7")
+	if err != nil || len(rows) != 5 || rows[4].Rank != -1 || rows[0].Text != cands[0].Text || rows[0].Detail == "" { t.Fatalf("stored candidates %+v %v", rows, err) }
+	_ = db.PutEventRef(ctx, now.Add(-time.Hour), "out_reply", "r1", map[string]any{"tweet_id": "r1", "text": "old reply"})
+	past, err := PastReplies(ctx, db, now.Add(-24*time.Hour), now)
+	if err != nil || len(past) != 1 || past[0] != "old reply" { t.Fatalf("past replies %v %v", past, err) }
+}
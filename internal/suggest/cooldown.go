@@ -0,0 +1,68 @@
+package suggest
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"starseed/internal/config"
+	"starseed/internal/engage"
+	"starseed/internal/schedule"
+	"starseed/internal/store/sqlitevec"
+)
+
+// ApplyCooldowns checks each suggestion against the per-author and per-conversation
+// cooldown rules at its scheduled time. Suggestions that would break a rule are deferred
+// to the earliest allowed time when that is within horizon of now, and skipped otherwise
+// (skipped maps tweet ID to the reason). A deferred time is moved past quiet hours and
+// must still fit the global and reply budgets. Earlier suggestions count against later ones.
+func ApplyCooldowns(ctx context.Context, db *sqlitevec.DB, cfg config.EngagementConfig, sugs []Suggestion, horizon time.Duration, now time.Time) ([]Suggestion, map[string]string, error) {
+	skipped := map[string]string{}
+	if db == nil || len(cfg.Cooldowns) == 0 { return sugs, skipped, nil }
+	rules, err := schedule.Compile(cfg)
+	if err != nil { return sugs, skipped, err }
+	b := engage.NewBudget(db, cfg)
+	b.DryRun = true
+	sort.SliceStable(sugs, func(i, j int) bool { return sugs[i].When.Before(sugs[j].When) })
+	kept := make([]Suggestion, 0, len(sugs))
+	for _, s := range sugs {
+		when := s.When
+		if when.Before(now) { when = now }
+		conv := s.Tweet.ConversationID
+		if conv == "" { conv = s.Tweet.ID }
+		target := engage.Target{UserID: s.Tweet.AuthorID, ConversationID: conv}
+		when, reason, ok, err := Reschedule(ctx, b, rules, target, when, now.Add(horizon))
+		if err != nil { return sugs, skipped, err }
+		if !ok {
+			skipped[s.Tweet.ID] = reason
+			continue
+		}
+		if reason != "" { s.When, s.Why = when, s.Why+" (deferred: "+reason+")" }
+		_ = b.RecordTarget(ctx, "reply", target, when)
+		kept = append(kept, s)
+	}
+	sort.SliceStable(kept, func(i, j int) bool { return kept[i].When.Before(kept[j].When) })
+	return kept, skipped, nil
+}
+
+// Reschedule finds the earliest time at or after when, and no later than until, at which
+// a reply to target is outside quiet hours, clear of the cooldowns and within the global
+// and reply budgets. Each move is re-checked against everything, since the new time may
+// land in quiet hours or collide with actions recorded in b. reason is the last rule that
+// moved the reply ("" when when already fit); when ok is false the returned time is the
+// last candidate, past until or after too many moves.
+func Reschedule(ctx context.Context, b *engage.Budget, rules *schedule.Rules, target engage.Target, when, until time.Time) (time.Time, string, bool, error) {
+	reason := ""
+	for i := 0; i < 10; i++ {
+		when = rules.Next(when)
+		if when.After(until) { break }
+		v, err := b.CheckTarget(ctx, "reply", target, when)
+		if err != nil { return when, reason, false, err }
+		if v.Allowed {
+			if v, err = b.Check(ctx, "reply", when); err != nil { return when, reason, false, err }
+		}
+		if v.Allowed { return when, reason, true, nil }
+		when, reason = v.Next, v.Reason
+	}
+	return when, reason, false, nil
+}
@@ -0,0 +1,64 @@
+package suggest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"starseed/internal/config"
+	"starseed/internal/model"
+	"starseed/internal/store/sqlitevec"
+)
+
+func TestApplyCooldownsDefersAndSkips(t *testing.T) {
+	db, err := sqlitevec.Open(":memory:")
+	if err != nil { t.Fatal(err) }
+	defer db.Close()
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := config.EngagementConfig{Cooldowns: []config.CooldownRule{
+		{Scope: "author", Type: "reply", Max: 1, WindowHours: 6},
+		{Scope: "author", Type: "reply", Max: 2, WindowHours: 48},
+	}}
+	// We replied to carol an hour ago
+	_ = db.PutActionTarget(ctx, now.Add(-time.Hour), "reply", "carol", "x")
+	sugs := []Suggestion{
+		{Tweet: model.Tweet{ID: "1", AuthorID: "alice"}, When: now.Add(time.Hour), Why: "w"},
+		{Tweet: model.Tweet{ID: "2", AuthorID: "alice"}, When: now.Add(2 * time.Hour), Why: "w"},
+		{Tweet: model.Tweet{ID: "3", AuthorID: "alice"}, When: now.Add(3 * time.Hour), Why: "w"},
+		{Tweet: model.Tweet{ID: "4", AuthorID: "carol"}, When: now.Add(30 * time.Minute), Why: "w"},
+		{Tweet: model.Tweet{ID: "5", AuthorID: "dave"}, When: now.Add(time.Hour), Why: "w"},
+	}
+	kept, skipped, err := ApplyCooldowns(ctx, db, cfg, sugs, 24*time.Hour, now)
+	if err != nil { t.Fatal(err) }
+	when := map[string]time.Time{}
+	for _, s := range kept { when[s.Tweet.ID] = s.When }
+	if !when["1"].Equal(now.Add(time.Hour)) || !when["5"].Equal(now.Add(time.Hour)) { t.Fatalf("unaffected suggestions moved: %v", when) }
+	if !when["2"].Equal(now.Add(7 * time.Hour)) { t.Fatalf("second alice reply should wait 6h after the first, got %s", when["2"]) }
+	if !when["4"].Equal(now.Add(5 * time.Hour)) { t.Fatalf("carol reply should wait for the earlier one, got %s", when["4"]) }
+	if _, ok := skipped["3"]; !ok || len(kept) != 4 { t.Fatalf("third alice reply should be skipped (2 per 48h), kept %v skipped %v", when, skipped) }
+	for i := 1; i < len(kept); i++ {
+		if kept[i].When.Before(kept[i-1].When) { t.Fatalf("kept suggestions not ordered by time") }
+	}
+}
+
+func TestApplyCooldownsSnapsPastQuietHoursAndBudgets(t *testing.T) {
+	db, err := sqlitevec.Open(":memory:")
+	if err != nil { t.Fatal(err) }
+	defer db.Close()
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := config.EngagementConfig{MaxPerHour: 1,
+		Schedule:  config.ScheduleConfig{Quiet: []config.QuietRange{{Start: "18:00", End: "20:00"}}},
+		Cooldowns: []config.CooldownRule{{Scope: "author", Type: "reply", Max: 1, WindowHours: 6}}}
+	// Another reply is already planned for 19:30
+	_ = db.PutActionTarget(ctx, now.Add(7*time.Hour+30*time.Minute), "reply", "zed", "z")
+	sugs := []Suggestion{
+		{Tweet: model.Tweet{ID: "1", AuthorID: "alice"}, When: now.Add(time.Hour), Why: "w"},
+		{Tweet: model.Tweet{ID: "2", AuthorID: "alice"}, When: now.Add(2 * time.Hour), Why: "w"},
+	}
+	kept, _, err := ApplyCooldowns(ctx, db, cfg, sugs, 24*time.Hour, now)
+	if err != nil { t.Fatal(err) }
+	// 19:00 is quiet until 20:00, and the hourly budget is spent until 20:30
+	if len(kept) != 2 || !kept[1].When.Equal(now.Add(8*time.Hour+30*time.Minute)) { t.Fatalf("expected the deferred reply at 20:30, got %+v", kept) }
+}
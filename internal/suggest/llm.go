@@ -4,42 +4,92 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"starseed/internal/config"
+	"starseed/internal/httpjson"
+	"starseed/internal/logging"
+	"starseed/internal/metrics"
 )
 
-// DraftWithLLM optionally upgrades a heuristic draft using an LLM provider.
-func DraftWithLLM(ctx context.Context, cfg config.LLMConfig, tweetText, heuristic string) (string, error) {
-	if strings.ToLower(cfg.Provider) != "openai" || cfg.APIKey == "" {
-		return heuristic, nil
+// Prompt is a provider-neutral drafting request.
+type Prompt struct {
+	System      string
+	User        string
+	N           int     // candidates requested (default 1)
+	MaxTokens   int     // 0 = provider default
+	Temperature *float64 // nil = provider default
+}
+
+// Usage reports tokens consumed by one Draft call.
+type Usage struct {
+	InputTokens  int
+	OutputTokens int
+}
+
+// Provider drafts reply candidates with an LLM backend.
+type Provider interface {
+	Draft(ctx context.Context, p Prompt) ([]string, Usage, error)
+}
+
+// NewProvider returns the backend selected by cfg.Provider, or nil when drafting
+// with an LLM is disabled ("none", empty) or the provider has no credentials.
+func NewProvider(cfg config.LLMConfig) (Provider, error) {
+	h := httpjson.Options{Name: "llm", Timeout: time.Duration(cfg.TimeoutSeconds) * time.Second, Retries: cfg.MaxRetries}
+	if h.Timeout <= 0 { h.Timeout = 30 * time.Second }
+	switch strings.ToLower(cfg.Provider) {
+	case "", "none":
+		return nil, nil
+	case "openai":
+		if cfg.APIKey == "" { return nil, nil }
+		return &openAIProvider{name: "openai", baseURL: coalesce(cfg.BaseURL, "https://api.openai.com/v1"), apiKey: cfg.APIKey, model: cfg.Model, api: coalesce(cfg.API, "responses"), http: h}, nil
+	case "openai-compatible":
+		if cfg.BaseURL == "" { return nil, fmt.Errorf("llm: baseURL required for openai-compatible") }
+		return &openAIProvider{name: "openai-compatible", baseURL: cfg.BaseURL, apiKey: cfg.APIKey, model: cfg.Model, api: coalesce(cfg.API, "chat"), http: h}, nil
+	case "ollama":
+		return &ollamaProvider{baseURL: coalesce(cfg.BaseURL, "http://localhost:11434"), model: cfg.Model, http: h}, nil
+	case "anthropic":
+		if cfg.APIKey == "" { return nil, nil }
+		return &anthropicProvider{baseURL: coalesce(cfg.BaseURL, "https://api.anthropic.com"), apiKey: cfg.APIKey, model: cfg.Model, http: h}, nil
 	}
-	// Minimal inlined client to avoid heavy deps; replace with official SDK if desired.
-	// OpenAI Responses API (JSON). We keep prompt small and grounded.
-	payload := fmt.Sprintf(`{"model":"%s","input":[{"role":"user","content":[{"type":"text","text":"Tweet: %s\nDraft a concise, wise, kind, on-topic reply (max 220 chars)."}]}]}`, cfg.Model, escapeJSON(tweetText))
-	req, err := httpNewRequest(ctx, "https://api.openai.com/v1/responses", "POST", payload)
+	return nil, fmt.Errorf("llm: unknown provider %q", cfg.Provider)
+}
+
+// DraftWithLLM optionally upgrades a heuristic draft using the configured provider and
+// the persona's prompt templates. The heuristic is kept when no provider is configured,
+// the call fails, or the draft breaks the persona rules.
+func DraftWithLLM(ctx context.Context, cfg config.LLMConfig, pr *Prompter, d PromptData, heuristic string) (string, error) {
+	p, err := NewProvider(cfg)
+	if err != nil || p == nil { return heuristic, err }
+	prompt, err := pr.Prompt(d)
 	if err != nil { return heuristic, err }
-	req.Header.Set("Authorization", "Bearer "+cfg.APIKey)
-	req.Header.Set("Content-Type", "application/json")
-	resp, err := httpDo(req)
+	prompt.MaxTokens, prompt.Temperature = cfg.MaxTokens, cfg.Temperature
+	cands, usage, err := p.Draft(ctx, prompt)
+	recordUsage(cfg.Provider, cfg.Model, usage)
 	if err != nil { return heuristic, err }
-	defer resp.Body.Close()
-	if resp.StatusCode >= 400 { return heuristic, fmt.Errorf("llm status %d", resp.StatusCode) }
-	text, err := parseOpenAIResponse(resp)
-	if err != nil || strings.TrimSpace(text) == "" {
-		return heuristic, err
+	for _, c := range cands {
+		if text, ok := pr.Finalize(c); ok && text != "" { return text, nil }
 	}
-	return text, nil
+	return heuristic, nil
 }
 
-// --- light http helpers (decoupled for testability) ---
-
-var httpNewRequest = defaultNewRequest
-var httpDo = defaultDo
+func recordUsage(provider, model string, u Usage) {
+	if u.InputTokens == 0 && u.OutputTokens == 0 { return }
+	metrics.AddLLMTokens(provider, u.InputTokens, u.OutputTokens)
+	logging.Info("llm_usage", map[string]any{"provider": provider, "model": model, "input_tokens": u.InputTokens, "output_tokens": u.OutputTokens})
+}
 
-// escapeJSON is minimal, for controlled prompts
-func escapeJSON(s string) string {
-	s = strings.ReplaceAll(s, "\\", "\\\\")
-	s = strings.ReplaceAll(s, "\"", "\\\"")
-	s = strings.ReplaceAll(s, "\n", " ")
-	return s
+// draftN calls one-candidate backends n times and sums usage.
+func draftN(n int, one func() (string, Usage, error)) ([]string, Usage, error) {
+	if n < 1 { n = 1 }
+	var out []string
+	var total Usage
+	for i := 0; i < n; i++ {
+		text, u, err := one()
+		total.InputTokens += u.InputTokens
+		total.OutputTokens += u.OutputTokens
+		if err != nil { return out, total, err }
+		if strings.TrimSpace(text) != "" { out = append(out, text) }
+	}
+	return out, total, nil
 }
@@ -0,0 +1,54 @@
+package suggest
+
+import (
+	"context"
+	"strings"
+
+	"starseed/internal/httpjson"
+)
+
+const anthropicVersion = "2023-06-01"
+
+// anthropicProvider drafts with the Anthropic Messages API.
+type anthropicProvider struct {
+	baseURL string
+	apiKey  string
+	model   string
+	http    httpjson.Options
+}
+
+type anthropicRequest struct {
+	Model       string        `json:"model"`
+	System      string        `json:"system,omitempty"`
+	Messages    []chatMessage `json:"messages"`
+	MaxTokens   int           `json:"max_tokens"`
+	Temperature *float64      `json:"temperature,omitempty"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Usage struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+func (p *anthropicProvider) Draft(ctx context.Context, pr Prompt) ([]string, Usage, error) {
+	req := anthropicRequest{Model: p.model, System: pr.System, MaxTokens: pr.MaxTokens, Temperature: pr.Temperature,
+		Messages: []chatMessage{{Role: "user", Content: pr.User}}}
+	if req.MaxTokens <= 0 { req.MaxTokens = 256 } // required by the API
+	headers := map[string]string{"x-api-key": p.apiKey, "anthropic-version": anthropicVersion}
+	url := strings.TrimRight(p.baseURL, "/") + "/v1/messages"
+	return draftN(pr.N, func() (string, Usage, error) {
+		var resp anthropicResponse
+		if err := httpjson.Post(ctx, p.http, url, headers, req, &resp); err != nil { return "", Usage{}, err }
+		var sb strings.Builder
+		for _, c := range resp.Content {
+			if c.Type == "text" { sb.WriteString(c.Text) }
+		}
+		return strings.TrimSpace(sb.String()), Usage{InputTokens: resp.Usage.InputTokens, OutputTokens: resp.Usage.OutputTokens}, nil
+	})
+}
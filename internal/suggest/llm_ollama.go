@@ -0,0 +1,44 @@
+package suggest
+
+import (
+	"context"
+	"strings"
+
+	"starseed/internal/httpjson"
+)
+
+// ollamaProvider drafts with a local Ollama server (/api/chat, non-streaming).
+type ollamaProvider struct {
+	baseURL string
+	model   string
+	http    httpjson.Options
+}
+
+type ollamaRequest struct {
+	Model    string         `json:"model"`
+	Messages []chatMessage  `json:"messages"`
+	Stream   bool           `json:"stream"`
+	Options  map[string]any `json:"options,omitempty"`
+}
+
+type ollamaResponse struct {
+	Message         chatMessage `json:"message"`
+	PromptEvalCount int         `json:"prompt_eval_count"`
+	EvalCount       int         `json:"eval_count"`
+}
+
+func (p *ollamaProvider) Draft(ctx context.Context, pr Prompt) ([]string, Usage, error) {
+	req := ollamaRequest{Model: p.model}
+	if pr.System != "" { req.Messages = append(req.Messages, chatMessage{Role: "system", Content: pr.System}) }
+	req.Messages = append(req.Messages, chatMessage{Role: "user", Content: pr.User})
+	opts := map[string]any{}
+	if pr.MaxTokens > 0 { opts["num_predict"] = pr.MaxTokens }
+	if pr.Temperature != nil { opts["temperature"] = *pr.Temperature }
+	if len(opts) > 0 { req.Options = opts }
+	url := strings.TrimRight(p.baseURL, "/") + "/api/chat"
+	return draftN(pr.N, func() (string, Usage, error) {
+		var resp ollamaResponse
+		if err := httpjson.Post(ctx, p.http, url, nil, req, &resp); err != nil { return "", Usage{}, err }
+		return strings.TrimSpace(resp.Message.Content), Usage{InputTokens: resp.PromptEvalCount, OutputTokens: resp.EvalCount}, nil
+	})
+}
@@ -0,0 +1,109 @@
+package suggest
+
+import (
+	"context"
+	"strings"
+
+	"starseed/internal/httpjson"
+)
+
+// openAIProvider talks to the OpenAI Responses or Chat Completions API, or to any
+// server that implements them under baseURL.
+type openAIProvider struct {
+	name    string
+	baseURL string
+	apiKey  string
+	model   string
+	api     string // "responses" or "chat"
+	http    httpjson.Options
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	N           int           `json:"n,omitempty"`
+	MaxTokens   int           `json:"max_tokens,omitempty"`
+	Temperature *float64      `json:"temperature,omitempty"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+type responsesRequest struct {
+	Model           string  `json:"model"`
+	Instructions    string  `json:"instructions,omitempty"`
+	Input           string  `json:"input"`
+	MaxOutputTokens int     `json:"max_output_tokens,omitempty"`
+	Temperature     *float64 `json:"temperature,omitempty"`
+}
+
+type responsesResponse struct {
+	OutputText string `json:"output_text"`
+	Output     []struct {
+		Type    string `json:"type"`
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	} `json:"output"`
+	Usage struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+func (p *openAIProvider) headers() map[string]string {
+	if p.apiKey == "" { return nil }
+	return map[string]string{"Authorization": "Bearer " + p.apiKey}
+}
+
+func (p *openAIProvider) Draft(ctx context.Context, pr Prompt) ([]string, Usage, error) {
+	if p.api == "chat" { return p.chat(ctx, pr) }
+	return draftN(pr.N, func() (string, Usage, error) { return p.responses(ctx, pr) })
+}
+
+func (p *openAIProvider) chat(ctx context.Context, pr Prompt) ([]string, Usage, error) {
+	req := chatRequest{Model: p.model, MaxTokens: pr.MaxTokens, Temperature: pr.Temperature}
+	if pr.N > 1 { req.N = pr.N }
+	if pr.System != "" { req.Messages = append(req.Messages, chatMessage{Role: "system", Content: pr.System}) }
+	req.Messages = append(req.Messages, chatMessage{Role: "user", Content: pr.User})
+	var resp chatResponse
+	if err := httpjson.Post(ctx, p.http, strings.TrimRight(p.baseURL, "/")+"/chat/completions", p.headers(), req, &resp); err != nil {
+		return nil, Usage{}, err
+	}
+	var out []string
+	for _, c := range resp.Choices {
+		if t := strings.TrimSpace(c.Message.Content); t != "" { out = append(out, t) }
+	}
+	return out, Usage{InputTokens: resp.Usage.PromptTokens, OutputTokens: resp.Usage.CompletionTokens}, nil
+}
+
+func (p *openAIProvider) responses(ctx context.Context, pr Prompt) (string, Usage, error) {
+	req := responsesRequest{Model: p.model, Instructions: pr.System, Input: pr.User, MaxOutputTokens: pr.MaxTokens, Temperature: pr.Temperature}
+	var resp responsesResponse
+	if err := httpjson.Post(ctx, p.http, strings.TrimRight(p.baseURL, "/")+"/responses", p.headers(), req, &resp); err != nil {
+		return "", Usage{}, err
+	}
+	u := Usage{InputTokens: resp.Usage.InputTokens, OutputTokens: resp.Usage.OutputTokens}
+	if resp.OutputText != "" { return resp.OutputText, u, nil }
+	var sb strings.Builder
+	for _, o := range resp.Output {
+		if o.Type != "message" { continue }
+		for _, c := range o.Content {
+			if c.Type == "output_text" { sb.WriteString(c.Text) }
+		}
+	}
+	return sb.String(), u, nil
+}
@@ -0,0 +1,109 @@
+package suggest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"starseed/internal/config"
+	"starseed/internal/httpjson"
+)
+
+func stubServer(t *testing.T, path string, handle func(t *testing.T, r *http.Request, body map[string]any) (int, string)) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path { t.Errorf("unexpected path %s", r.URL.Path) }
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		code, resp := handle(t, r, body)
+		w.WriteHeader(code)
+		_, _ = w.Write([]byte(resp))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func mustProvider(t *testing.T, cfg config.LLMConfig) Provider {
+	t.Helper()
+	p, err := NewProvider(cfg)
+	if err != nil || p == nil { t.Fatalf("provider: %v %v", p, err) }
+	return p
+}
+
+func TestOpenAIChatReturnsCandidatesAndUsage(t *testing.T) {
+	srv := stubServer(t, "/v1/chat/completions", func(t *testing.T, r *http.Request, body map[string]any) (int, string) {
+		if r.Header.Get("Authorization") != "Bearer k" { t.Errorf("missing auth header") }
+		if body["n"] != float64(2) || len(body["messages"].([]any)) != 2 { t.Errorf("unexpected body %v", body) }
+		return 200, `{"choices":[{"message":{"role":"assistant","content":"one"}},{"message":{"role":"assistant","content":"two"}}],"usage":{"prompt_tokens":12,"completion_tokens":7}}`
+	})
+	p := mustProvider(t, config.LLMConfig{Provider: "openai", APIKey: "k", API: "chat", BaseURL: srv.URL + "/v1", Model: "m"})
+	cands, u, err := p.Draft(context.Background(), Prompt{System: "be kind", User: `say "hi"`, N: 2})
+	if err != nil { t.Fatal(err) }
+	if len(cands) != 2 || cands[1] != "two" || u.InputTokens != 12 || u.OutputTokens != 7 { t.Fatalf("got %v %+v", cands, u) }
+}
+
+func TestOpenAIResponsesParsesOutputBlocks(t *testing.T) {
+	srv := stubServer(t, "/responses", func(t *testing.T, r *http.Request, body map[string]any) (int, string) {
+		if body["input"] != "tweet\nwith \"quotes\"" { t.Errorf("prompt not JSON-encoded faithfully: %v", body["input"]) }
+		return 200, `{"output":[{"type":"reasoning"},{"type":"message","content":[{"type":"output_text","text":"Nice point."}]}],"usage":{"input_tokens":5,"output_tokens":3}}`
+	})
+	p := mustProvider(t, config.LLMConfig{Provider: "openai", APIKey: "k", BaseURL: srv.URL})
+	cands, u, err := p.Draft(context.Background(), Prompt{User: "tweet\nwith \"quotes\""})
+	if err != nil { t.Fatal(err) }
+	if len(cands) != 1 || cands[0] != "Nice point." || u.OutputTokens != 3 { t.Fatalf("got %v %+v", cands, u) }
+}
+
+func TestOllamaAndAnthropicBackends(t *testing.T) {
+	ol := stubServer(t, "/api/chat", func(t *testing.T, r *http.Request, body map[string]any) (int, string) {
+		if body["stream"] != false { t.Errorf("expected non-streaming request") }
+		if opts, _ := body["options"].(map[string]any); opts["temperature"] != float64(0) { t.Errorf("explicit temperature 0 not sent: %v", body["options"]) }
+		return 200, `{"message":{"role":"assistant","content":" local reply "},"prompt_eval_count":4,"eval_count":2}`
+	})
+	cands, u, err := mustProvider(t, config.LLMConfig{Provider: "ollama", BaseURL: ol.URL, Model: "llama3"}).Draft(context.Background(), Prompt{User: "x", N: 2, Temperature: config.Float(0)})
+	if err != nil || len(cands) != 2 || cands[0] != "local reply" || u.InputTokens != 8 { t.Fatalf("ollama: %v %+v %v", cands, u, err) }
+
+	an := stubServer(t, "/v1/messages", func(t *testing.T, r *http.Request, body map[string]any) (int, string) {
+		if r.Header.Get("x-api-key") != "ak" || r.Header.Get("anthropic-version") == "" { t.Errorf("missing anthropic headers") }
+		if body["system"] != "sys" || body["max_tokens"] != float64(256) { t.Errorf("unexpected body %v", body) }
+		if _, ok := body["temperature"]; ok { t.Errorf("unset temperature should be left to the provider: %v", body) }
+		return 200, `{"content":[{"type":"text","text":"Thoughtful."}],"usage":{"input_tokens":9,"output_tokens":1}}`
+	})
+	cands, u, err = mustProvider(t, config.LLMConfig{Provider: "anthropic", APIKey: "ak", BaseURL: an.URL, Model: "claude"}).Draft(context.Background(), Prompt{System: "sys", User: "x"})
+	if err != nil || len(cands) != 1 || cands[0] != "Thoughtful." || u.InputTokens != 9 { t.Fatalf("anthropic: %v %+v %v", cands, u, err) }
+}
+
+func TestRetriesTransientErrorsOnly(t *testing.T) {
+	prev := httpjson.RetryBackoff
+	httpjson.RetryBackoff = time.Millisecond
+	t.Cleanup(func() { httpjson.RetryBackoff = prev })
+	var calls int32
+	srv := stubServer(t, "/chat/completions", func(t *testing.T, r *http.Request, body map[string]any) (int, string) {
+		if atomic.AddInt32(&calls, 1) < 3 { return 503, `overloaded` }
+		return 200, `{"choices":[{"message":{"content":"ok"}}]}`
+	})
+	cfg := config.LLMConfig{Provider: "openai-compatible", BaseURL: srv.URL, MaxRetries: 2}
+	cands, _, err := mustProvider(t, cfg).Draft(context.Background(), Prompt{User: "x"})
+	if err != nil || len(cands) != 1 || calls != 3 { t.Fatalf("expected success after retries: %v %v calls=%d", cands, err, calls) }
+
+	atomic.StoreInt32(&calls, 0)
+	bad := stubServer(t, "/chat/completions", func(t *testing.T, r *http.Request, body map[string]any) (int, string) {
+		atomic.AddInt32(&calls, 1)
+		return 400, `{"error":"bad model"}`
+	})
+	cfg.BaseURL = bad.URL
+	if _, _, err := mustProvider(t, cfg).Draft(context.Background(), Prompt{User: "x"}); err == nil || calls != 1 { t.Fatalf("4xx should not be retried: %v calls=%d", err, calls) }
+}
+
+func TestTimeoutAndFallback(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { time.Sleep(200 * time.Millisecond) }))
+	defer srv.Close()
+	p := &ollamaProvider{baseURL: srv.URL, http: httpjson.Options{Name: "llm", Timeout: 20 * time.Millisecond}}
+	if _, _, err := p.Draft(context.Background(), Prompt{User: "x"}); err == nil { t.Fatalf("expected timeout error") }
+	pr, _ := NewPrompter(config.PersonaConfig{})
+	out, err := DraftWithLLM(context.Background(), config.LLMConfig{Provider: "none"}, pr, PromptData{}, "heuristic")
+	if err != nil || out != "heuristic" { t.Fatalf("expected heuristic fallback, got %q %v", out, err) }
+}
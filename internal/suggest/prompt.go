@@ -0,0 +1,131 @@
+package suggest
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"starseed/internal/config"
+	"starseed/internal/model"
+)
+
+//go:embed templates/*.tmpl
+var builtinTemplates embed.FS
+
+// PromptData is what prompt templates can reference.
+type PromptData struct {
+	Account   string // our handle, without @
+	Tweet     model.Tweet
+	Author    model.User
+	Thread    []model.Tweet // earlier tweets in the conversation, oldest first
+	Replies   []model.Tweet // notable existing replies, most liked first
+	Interests config.InterestsConfig
+	Persona   config.PersonaConfig
+}
+
+// Prompter renders the system, reply and heuristic templates for a persona.
+type Prompter struct {
+	persona   config.PersonaConfig
+	system    *template.Template
+	reply     *template.Template
+	heuristic *template.Template
+}
+
+var templateFuncs = template.FuncMap{
+	"join":     strings.Join,
+	"truncate": trimForPrompt,
+	"lower":    strings.ToLower,
+}
+
+// NewPrompter loads the persona's template files, falling back to the built-in prompts.
+func NewPrompter(p config.PersonaConfig) (*Prompter, error) {
+	if p.Voice == "" { p.Voice = "concise, wise, kind" }
+	if p.Emoji == "" { p.Emoji = "none" }
+	if p.MaxLength <= 0 { p.MaxLength = 220 }
+	pr := &Prompter{persona: p}
+	var err error
+	if pr.system, err = loadTemplate("system", p.Templates.System); err != nil { return nil, err }
+	if pr.reply, err = loadTemplate("reply", p.Templates.Reply); err != nil { return nil, err }
+	if pr.heuristic, err = loadTemplate("heuristic", p.Templates.Heuristic); err != nil { return nil, err }
+	return pr, nil
+}
+
+func loadTemplate(name, path string) (*template.Template, error) {
+	var src []byte
+	var err error
+	if path != "" {
+		src, err = os.ReadFile(path)
+	} else {
+		src, err = builtinTemplates.ReadFile("templates/" + name + ".tmpl")
+	}
+	if err != nil { return nil, err }
+	t, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(string(src))
+	if err != nil { return nil, fmt.Errorf("%s template: %w", name, err) }
+	return t, nil
+}
+
+// Persona returns the effective persona (defaults applied).
+func (p *Prompter) Persona() config.PersonaConfig { return p.persona }
+
+// Prompt renders the LLM prompt for d.
+func (p *Prompter) Prompt(d PromptData) (Prompt, error) {
+	d.Persona = p.persona
+	sys, err := render(p.system, d)
+	if err != nil { return Prompt{}, err }
+	user, err := render(p.reply, d)
+	if err != nil { return Prompt{}, err }
+	return Prompt{System: sys, User: user, N: 1}, nil
+}
+
+// Heuristic renders the primary no-LLM reply draft for d.
+func (p *Prompter) Heuristic(d PromptData) (string, error) {
+	vs, err := p.HeuristicVariants(d)
+	if err != nil || len(vs) == 0 { return "", err }
+	return vs[0], nil
+}
+
+// HeuristicVariants renders every variant of the heuristic template; variants are
+// separated by a line containing only "---".
+func (p *Prompter) HeuristicVariants(d PromptData) ([]string, error) {
+	d.Persona = p.persona
+	out, err := render(p.heuristic, d)
+	if err != nil { return nil, err }
+	var vs []string
+	for _, v := range strings.Split(out, "\n---\n") {
+		if v = strings.TrimSpace(v); v != "" { vs = append(vs, v) }
+	}
+	return vs, nil
+}
+
+func render(t *template.Template, d PromptData) (string, error) {
+	var sb strings.Builder
+	if err := t.Execute(&sb, d); err != nil { return "", err }
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// Finalize applies the persona to a draft: strips emoji when disallowed and enforces
+// the length limit. ok is false when the draft contains a banned phrase.
+func (p *Prompter) Finalize(text string) (string, bool) {
+	text = strings.TrimSpace(strings.Trim(strings.TrimSpace(text), `"`))
+	if p.persona.Emoji == "none" { text = strings.Join(strings.Fields(stripEmoji(text)), " ") }
+	for _, b := range p.persona.BannedPhrases {
+		if b != "" && strings.Contains(strings.ToLower(text), strings.ToLower(b)) { return text, false }
+	}
+	if utf8.RuneCountInString(text) > p.persona.MaxLength {
+		text = string([]rune(text)[:p.persona.MaxLength-1]) + "…"
+	}
+	return text, true
+}
+
+func stripEmoji(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 0x1F000 && r <= 0x1FAFF, r >= 0x2600 && r <= 0x27BF, r == 0xFE0F, r == 0x200D:
+			return -1
+		}
+		return r
+	}, s)
+}
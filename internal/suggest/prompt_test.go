@@ -0,0 +1,53 @@
+package suggest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"starseed/internal/config"
+	"starseed/internal/model"
+)
+
+func TestPromptRendersPersonaAndContext(t *testing.T) {
+	pr, err := NewPrompter(config.PersonaConfig{Voice: "dry, precise", Expertise: []string{"raft", "golang"}, BannedPhrases: []string{"game changer"}, Emoji: "sparing"})
+	if err != nil { t.Fatal(err) }
+	d := PromptData{
+		Account:   "me",
+		Tweet:     model.Tweet{Text: "Leader election is hard"},
+		Author:    model.User{Username: "alice", Description: "distributed systems"},
+		Thread:    []model.Tweet{{Text: "What breaks first in consensus?"}},
+		Interests: config.InterestsConfig{Topics: []string{"consensus"}},
+	}
+	p, err := pr.Prompt(d)
+	if err != nil { t.Fatal(err) }
+	for _, want := range []string{"as @me", "dry, precise", "raft, golang", "game changer", "at most one emoji", "220 characters"} {
+		if !strings.Contains(p.System, want) { t.Errorf("system prompt missing %q:\n%s", want, p.System) }
+	}
+	for _, want := range []string{"by @alice", "Author bio: distributed systems", "- What breaks first", "Our interests: consensus", "Draft a dry, precise, on-topic reply"} {
+		if !strings.Contains(p.User, want) { t.Errorf("user prompt missing %q:\n%s", want, p.User) }
+	}
+	if strings.Contains(p.User, "concise, wise, kind") { t.Errorf("user prompt ignores the persona voice:\n%s", p.User) }
+}
+
+func TestCustomTemplateSharedByHeuristicPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "h.tmpl")
+	if err := os.WriteFile(path, []byte(`Re {{.Author.Username}}: {{lower .Tweet.Text}} 🚀`), 0o644); err != nil { t.Fatal(err) }
+	pr, err := NewPrompter(config.PersonaConfig{Templates: config.PromptTemplates{Heuristic: path}})
+	if err != nil { t.Fatal(err) }
+	tw := model.Tweet{ID: "1", AuthorID: "u1", Text: "Shipping Go Generics", Language: "en", LikeCount: 3}
+	sugs := pr.HeuristicSuggest([]model.Tweet{tw}, PromptData{}, map[string]model.User{"u1": {Username: "bob"}}, time.Now())
+	if len(sugs) != 1 || sugs[0].Text != "Re bob: shipping go generics" { t.Fatalf("unexpected suggestions %+v", sugs) }
+	if _, err := NewPrompter(config.PersonaConfig{Templates: config.PromptTemplates{Reply: filepath.Join(t.TempDir(), "missing")}}); err == nil {
+		t.Fatalf("expected error for missing template file")
+	}
+}
+
+func TestFinalizeEnforcesPersona(t *testing.T) {
+	pr, _ := NewPrompter(config.PersonaConfig{BannedPhrases: []string{"Game Changer"}, MaxLength: 10})
+	if _, ok := pr.Finalize("what a game changer"); ok { t.Fatalf("banned phrase accepted") }
+	out, ok := pr.Finalize(`"Nice 👍 work, truly great"`)
+	if !ok || out != "Nice work…" { t.Fatalf("got %q %v", out, ok) }
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"starseed/internal/config"
 	"starseed/internal/model"
 )
 
@@ -14,35 +15,48 @@ type Suggestion struct {
 	When  time.Time
 	Text  string
 	Why   string
+	// Score of the selected draft and the remaining candidates, best first
+	Score      float64
+	Alternates []Candidate
+	// Predicted engagement of the assigned window (0 when no forecast was available)
+	Window float32
 }
 
-// HeuristicSuggest generates simple rule-based suggestions.
+// HeuristicSuggest generates simple rule-based suggestions with the default persona.
 func HeuristicSuggest(tweets []model.Tweet, now time.Time) []Suggestion {
+	pr, err := NewPrompter(config.PersonaConfig{})
+	if err != nil { return nil }
+	return pr.HeuristicSuggest(tweets, PromptData{}, nil, now)
+}
+
+// HeuristicSuggest drafts rule-based replies from the heuristic template. base carries
+// the account and interests; authors (by ID) fill in each tweet's author profile. Tweets
+// are not filtered here: callers apply the engagement policy first.
+func (p *Prompter) HeuristicSuggest(tweets []model.Tweet, base PromptData, authors map[string]model.User, now time.Time) []Suggestion {
 	out := make([]Suggestion, 0)
 	for _, t := range tweets {
 		org := model.OrganicContentScore(t)
-		if org < 0.55 { // aim for organic content most of the time
+		text := strings.TrimSpace(t.Text)
+		if text == "" {
 			continue
 		}
-		if t.Language != "" && t.Language != "en" {
+		d := base
+		d.Tweet, d.Author = t, authors[t.AuthorID]
+		draft, err := p.Heuristic(d)
+		if err != nil {
 			continue
 		}
-		text := strings.TrimSpace(t.Text)
-		if text == "" {
+		draft, ok := p.Finalize(draft)
+		if !ok {
 			continue
 		}
 		when := now.Add(5 * time.Minute)
 		why := fmt.Sprintf("organic=%.2f, lang=%s", org, coalesce(t.Language, "n/a"))
-		out = append(out, Suggestion{Tweet: t, When: when, Text: generateTemplate(text), Why: why})
+		out = append(out, Suggestion{Tweet: t, When: when, Text: draft, Why: why})
 	}
 	return out
 }
 
-func generateTemplate(tweetText string) string {
-	// Lightweight prompt-engineered reply template; no external LLM dependency.
-	return fmt.Sprintf("Thoughtful take: %s — What trade-offs did you consider?", trimForPrompt(tweetText, 180))
-}
-
 func trimForPrompt(s string, n int) string {
 	runes := []rune(s)
 	if len(runes) <= n {
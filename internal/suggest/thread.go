@@ -0,0 +1,97 @@
+package suggest
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"starseed/internal/model"
+	"starseed/internal/store/sqlitevec"
+)
+
+// ThreadFetcher is the subset of the X client needed to load a conversation.
+type ThreadFetcher interface {
+	GetTweetsByIDs(ctx context.Context, ids []string) ([]model.Tweet, error)
+	SearchRecentTweets(ctx context.Context, query string, limit int) ([]model.Tweet, error)
+}
+
+// Thread is the conversation around a target tweet.
+type Thread struct {
+	Ancestors      []model.Tweet // root ... parent of the target, oldest first
+	Replies        []model.Tweet // other replies in the conversation, most liked first
+	AlreadyReplied bool          // we already posted in this conversation
+}
+
+const maxAncestors = 8
+
+// FetchThread loads the reply chain above t and the conversation's existing replies
+// (recent search, so only the last 7 days). selfID marks our own tweets.
+func FetchThread(ctx context.Context, f ThreadFetcher, t model.Tweet, selfID string) (Thread, error) {
+	var th Thread
+	byID := map[string]model.Tweet{}
+	conv := t.ConversationID
+	if conv == "" && t.ReplyToID == "" { conv = t.ID } // a root tweet starts its own conversation
+	if conv != "" {
+		replies, err := f.SearchRecentTweets(ctx, "conversation_id:"+conv, 100)
+		if err != nil { return th, err }
+		for _, r := range replies {
+			byID[r.ID] = r
+			if selfID != "" && r.AuthorID == selfID { th.AlreadyReplied = true }
+		}
+	}
+	// Walk replied_to links upward, fetching tweets the search did not return.
+	parent := t.ReplyToID
+	for hops := 0; parent != "" && hops < maxAncestors; hops++ {
+		p, ok := byID[parent]
+		if !ok {
+			got, err := f.GetTweetsByIDs(ctx, []string{parent})
+			if err != nil || len(got) == 0 { break }
+			p = got[0]
+			byID[p.ID] = p
+		}
+		th.Ancestors = append([]model.Tweet{p}, th.Ancestors...)
+		if selfID != "" && p.AuthorID == selfID { th.AlreadyReplied = true }
+		parent = p.ReplyToID
+	}
+	inChain := map[string]bool{t.ID: true}
+	for _, a := range th.Ancestors { inChain[a.ID] = true }
+	for id, r := range byID {
+		if !inChain[id] && r.ReplyToID != "" { th.Replies = append(th.Replies, r) }
+	}
+	sort.SliceStable(th.Replies, func(i, j int) bool {
+		if th.Replies[i].LikeCount != th.Replies[j].LikeCount { return th.Replies[i].LikeCount > th.Replies[j].LikeCount }
+		return th.Replies[i].ID < th.Replies[j].ID
+	})
+	return th, nil
+}
+
+// Summarize condenses the thread for a prompt: the root plus the last few tweets above
+// the target, and the most liked existing replies.
+func (th Thread) Summarize(nAncestors, nReplies int) (ancestors, replies []model.Tweet) {
+	ancestors = th.Ancestors
+	if nAncestors > 1 && len(ancestors) > nAncestors {
+		ancestors = append([]model.Tweet{ancestors[0]}, ancestors[len(ancestors)-(nAncestors-1):]...)
+	}
+	replies = th.Replies
+	if len(replies) > nReplies { replies = replies[:nReplies] }
+	return ancestors, replies
+}
+
+// RepliedConversations returns conversation IDs (and parent tweet IDs) we replied to since
+// the given time, from stored out_reply events.
+func RepliedConversations(ctx context.Context, db *sqlitevec.DB, since, now time.Time) (map[string]bool, error) {
+	evs, err := db.LoadEventsRange(ctx, since, now, "out_reply")
+	if err != nil { return nil, err }
+	out := map[string]bool{}
+	for _, e := range evs {
+		var p struct {
+			ConversationID string `json:"conversation_id"`
+			ReplyToID      string `json:"reply_to_id"`
+		}
+		if json.Unmarshal([]byte(e.Payload), &p) != nil { continue }
+		if p.ConversationID != "" { out[p.ConversationID] = true }
+		if p.ReplyToID != "" { out[p.ReplyToID] = true }
+	}
+	return out, nil
+}
@@ -0,0 +1,74 @@
+package suggest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"starseed/internal/config"
+	"starseed/internal/model"
+	"starseed/internal/store/sqlitevec"
+)
+
+type fakeThreadClient struct {
+	search  map[string][]model.Tweet
+	tweets  map[string]model.Tweet
+	lookups int
+}
+
+func (f *fakeThreadClient) SearchRecentTweets(ctx context.Context, q string, limit int) ([]model.Tweet, error) {
+	return f.search[q], nil
+}
+
+func (f *fakeThreadClient) GetTweetsByIDs(ctx context.Context, ids []string) ([]model.Tweet, error) {
+	f.lookups++
+	var out []model.Tweet
+	for _, id := range ids {
+		if t, ok := f.tweets[id]; ok { out = append(out, t) }
+	}
+	return out, nil
+}
+
+func TestFetchThreadBuildsChainAndReplies(t *testing.T) {
+	root := model.Tweet{ID: "1", AuthorID: "a", Text: "Root: we migrated to raft", ConversationID: "1"}
+	mid := model.Tweet{ID: "2", AuthorID: "b", Text: "How long did it take?", ConversationID: "1", ReplyToID: "1"}
+	target := model.Tweet{ID: "3", AuthorID: "a", Text: "Six months, mostly testing", ConversationID: "1", ReplyToID: "2"}
+	other := model.Tweet{ID: "4", AuthorID: "c", Text: "Jepsen saved us too", ConversationID: "1", ReplyToID: "1", LikeCount: 9}
+	f := &fakeThreadClient{
+		search: map[string][]model.Tweet{"conversation_id:1": {mid, target, other}},
+		tweets: map[string]model.Tweet{"1": root},
+	}
+	th, err := FetchThread(context.Background(), f, target, "me")
+	if err != nil { t.Fatal(err) }
+	if len(th.Ancestors) != 2 || th.Ancestors[0].ID != "1" || th.Ancestors[1].ID != "2" { t.Fatalf("unexpected ancestors %+v", th.Ancestors) }
+	if len(th.Replies) != 1 || th.Replies[0].ID != "4" || th.AlreadyReplied { t.Fatalf("unexpected replies %+v replied=%v", th.Replies, th.AlreadyReplied) }
+	if f.lookups != 1 { t.Fatalf("expected only the root to be looked up, got %d lookups", f.lookups) }
+
+	pr, _ := NewPrompter(config.PersonaConfig{})
+	d := PromptData{Tweet: target}
+	d.Thread, d.Replies = th.Summarize(4, 3)
+	p, _ := pr.Prompt(d)
+	if !strings.Contains(p.User, "- How long did it take?") || !strings.Contains(p.User, "- Jepsen saved us too") { t.Fatalf("thread context missing:\n%s", p.User) }
+
+	f.search["conversation_id:1"] = append(f.search["conversation_id:1"], model.Tweet{ID: "5", AuthorID: "me", ReplyToID: "1", ConversationID: "1"})
+	th, _ = FetchThread(context.Background(), f, target, "me")
+	if !th.AlreadyReplied { t.Fatalf("our reply in the conversation should be flagged") }
+}
+
+func TestRepliedConversationsFromEvents(t *testing.T) {
+	db, _ := sqlitevec.Open(":memory:")
+	defer db.Close()
+	ctx := context.Background()
+	now := time.Now().UTC()
+	_ = db.PutEventRef(ctx, now.Add(-time.Hour), "out_reply", "r1", map[string]any{"tweet_id": "r1", "conversation_id": "100", "reply_to_id": "101"})
+	got, err := RepliedConversations(ctx, db, now.Add(-24*time.Hour), now)
+	if err != nil || !got["100"] || !got["101"] || got["r1"] { t.Fatalf("unexpected %v %v", got, err) }
+}
+
+func TestSummarizeKeepsRootAndRecent(t *testing.T) {
+	th := Thread{}
+	for i := 0; i < 6; i++ { th.Ancestors = append(th.Ancestors, model.Tweet{ID: string(rune('a' + i))}) }
+	anc, _ := th.Summarize(3, 3)
+	if len(anc) != 3 || anc[0].ID != "a" || anc[1].ID != "e" || anc[2].ID != "f" { t.Fatalf("unexpected summary %+v", anc) }
+}
@@ -0,0 +1,209 @@
+package suggest
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"starseed/internal/config"
+	"starseed/internal/model"
+	"starseed/internal/util"
+)
+
+// Rejection explains why a draft was dropped.
+type Rejection struct {
+	Rule   string `json:"rule"`
+	Reason string `json:"reason"`
+}
+
+func (r *Rejection) Error() string { return r.Rule + ": " + r.Reason }
+
+// DraftContext is what rules may check a draft against.
+type DraftContext struct {
+	Tweet   model.Tweet
+	Author  model.User
+	Thread  []model.Tweet
+	Replies []model.Tweet
+}
+
+// Rule checks one draft; it returns a non-empty reason to reject it.
+type Rule struct {
+	Name  string
+	Check func(text string, c DraftContext) string
+}
+
+// Validator runs draft rules in order and stops at the first rejection.
+type Validator struct {
+	rules []Rule
+}
+
+// NewValidator builds the configured rule pipeline. pastReplies are our recent replies,
+// newest last; maxLen is the persona limit used when rules.maxLength is unset.
+func NewValidator(r config.DraftRules, maxLen int, pastReplies []string) *Validator {
+	disabled := map[string]bool{}
+	for _, d := range r.Disabled { disabled[d] = true }
+	if r.MinLength == 0 { r.MinLength = 10 }
+	if r.MaxLength == 0 { r.MaxLength = maxLen }
+	if r.MaxLength == 0 { r.MaxLength = 280 }
+	if r.MaxHashtags == 0 { r.MaxHashtags = 2 }
+	if r.Links == "" { r.Links = "none" }
+	if r.RepetitionWindow == 0 { r.RepetitionWindow = 50 }
+	if r.NGram == 0 { r.NGram = 3 }
+	if r.MaxSimilarity == 0 { r.MaxSimilarity = 0.6 }
+	if len(pastReplies) > r.RepetitionWindow { pastReplies = pastReplies[len(pastReplies)-r.RepetitionWindow:] }
+	all := []Rule{
+		lengthRule(r.MinLength, r.MaxLength),
+		charsRule(r.MaxHashtags),
+		blocklistRule(r.Blocklist),
+		piiRule(),
+		linksRule(r.Links, r.AllowedDomains),
+		repetitionRule(pastReplies, r.NGram, r.MaxSimilarity),
+	}
+	v := &Validator{}
+	for _, rule := range all {
+		if !disabled[rule.Name] { v.rules = append(v.rules, rule) }
+	}
+	return v
+}
+
+// Rules returns the active rule names in evaluation order.
+func (v *Validator) Rules() []string {
+	names := make([]string, len(v.rules))
+	for i, r := range v.rules { names[i] = r.Name }
+	return names
+}
+
+// Validate returns nil when text passes every rule, otherwise the first rejection.
+func (v *Validator) Validate(text string, c DraftContext) *Rejection {
+	if v == nil { return nil }
+	for _, r := range v.rules {
+		if reason := r.Check(text, c); reason != "" { return &Rejection{Rule: r.Name, Reason: reason} }
+	}
+	return nil
+}
+
+func lengthRule(min, max int) Rule {
+	return Rule{Name: "length", Check: func(text string, _ DraftContext) string {
+		n := utf8.RuneCountInString(strings.TrimSpace(text))
+		if n < min { return fmt.Sprintf("too short (%d < %d chars)", n, min) }
+		if n > max { return fmt.Sprintf("too long (%d > %d chars)", n, max) }
+		return ""
+	}}
+}
+
+func charsRule(maxHashtags int) Rule {
+	return Rule{Name: "chars", Check: func(text string, _ DraftContext) string {
+		for _, r := range text {
+			if r == '\n' { continue }
+			if unicode.IsControl(r) || r == '\u200b' || r == '\u202e' || r == utf8.RuneError {
+				return fmt.Sprintf("disallowed character %U", r)
+			}
+		}
+		if n := strings.Count(text, "#"); n > maxHashtags { return fmt.Sprintf("%d hashtags (max %d)", n, maxHashtags) }
+		return ""
+	}}
+}
+
+func blocklistRule(terms []string) Rule {
+	return Rule{Name: "blocklist", Check: func(text string, _ DraftContext) string {
+		padded := " " + strings.Join(util.Tokenize(text), " ") + " "
+		for _, t := range terms {
+			norm := strings.Join(util.Tokenize(t), " ")
+			if norm != "" && strings.Contains(padded, " "+norm+" ") { return fmt.Sprintf("blocklisted term %q", t) }
+		}
+		return ""
+	}}
+}
+
+var (
+	emailRe      = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	phoneRe      = regexp.MustCompile(`(?:\+\d{1,3}[\s.-]?)?(?:\(\d{2,4}\)[\s.-]?)?\d{2,4}(?:[\s.-]\d{2,4}){1,3}`)
+	localPhoneRe = regexp.MustCompile(`^\d{3}[.-]\d{4}$`)
+	handleRe     = regexp.MustCompile(`(?:^|[^A-Za-z0-9_])@([A-Za-z0-9_]{1,15})`)
+	urlRe        = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s]+`)
+)
+
+// hasPhone reports a phone-shaped number: digit groups only count when written with a
+// country code or area-code parentheses, as a 7-digit local number ("555-0199"), or when
+// they add up to 10+ digits, so year ranges ("2019-2020") and measurements ("1500.2500") pass.
+func hasPhone(text string) bool {
+	for _, m := range phoneRe.FindAllString(text, -1) {
+		digits := 0
+		for _, r := range m {
+			if r >= '0' && r <= '9' { digits++ }
+		}
+		if strings.HasPrefix(m, "+") || strings.Contains(m, "(") || digits >= 10 || localPhoneRe.MatchString(m) { return true }
+	}
+	return false
+}
+
+// piiRule rejects emails, phone numbers and @handles that don't appear in the thread.
+func piiRule() Rule {
+	return Rule{Name: "pii", Check: func(text string, c DraftContext) string {
+		if emailRe.MatchString(text) { return "contains an email address" }
+		if hasPhone(text) { return "contains a phone number" }
+		known := threadHandles(c)
+		for _, m := range handleRe.FindAllStringSubmatch(text, -1) {
+			if !known[strings.ToLower(m[1])] { return fmt.Sprintf("mentions @%s who is not in the thread", m[1]) }
+		}
+		return ""
+	}}
+}
+
+func threadHandles(c DraftContext) map[string]bool {
+	known := map[string]bool{}
+	if c.Author.Username != "" { known[strings.ToLower(c.Author.Username)] = true }
+	for _, t := range append(append([]model.Tweet{c.Tweet}, c.Thread...), c.Replies...) {
+		for _, m := range handleRe.FindAllStringSubmatch(t.Text, -1) { known[strings.ToLower(m[1])] = true }
+	}
+	return known
+}
+
+func linksRule(policy string, allowed []string) Rule {
+	return Rule{Name: "links", Check: func(text string, _ DraftContext) string {
+		links := urlRe.FindAllString(text, -1)
+		if len(links) == 0 || policy == "any" { return "" }
+		if policy == "none" { return "links are not allowed" }
+		for _, l := range links {
+			if !strings.Contains(l, "://") { l = "https://" + l }
+			u, err := url.Parse(l)
+			if err != nil || !domainAllowed(u.Hostname(), allowed) { return fmt.Sprintf("link to %s not in allowlist", l) }
+		}
+		return ""
+	}}
+}
+
+func domainAllowed(host string, allowed []string) bool {
+	host = strings.ToLower(host)
+	for _, d := range allowed {
+		d = strings.ToLower(d)
+		if host == d || strings.HasSuffix(host, "."+d) { return true }
+	}
+	return false
+}
+
+func repetitionRule(past []string, n int, max float64) Rule {
+	grams := make([]map[string]bool, len(past))
+	for i, p := range past { grams[i] = ngrams(p, n) }
+	return Rule{Name: "repetition", Check: func(text string, _ DraftContext) string {
+		g := ngrams(text, n)
+		for i, p := range grams {
+			if sim := jaccard(g, p); sim >= max { return fmt.Sprintf("%.0f%% similar to a recent reply: %q", sim*100, trimForPrompt(past[i], 60)) }
+		}
+		return ""
+	}}
+}
+
+func ngrams(text string, n int) map[string]bool {
+	toks := util.Tokenize(text)
+	out := make(map[string]bool)
+	if len(toks) < n {
+		if len(toks) > 0 { out[strings.Join(toks, " ")] = true }
+		return out
+	}
+	for i := 0; i+n <= len(toks); i++ { out[strings.Join(toks[i:i+n], " ")] = true }
+	return out
+}
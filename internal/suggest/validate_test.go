@@ -0,0 +1,60 @@
+package suggest
+
+import (
+	"testing"
+
+	"starseed/internal/config"
+	"starseed/internal/model"
+)
+
+func TestValidatorRules(t *testing.T) {
+	past := []string{"Consensus is mostly about failure detection and timeouts in practice"}
+	v := NewValidator(config.DraftRules{Blocklist: []string{"crypto", "to the moon"}, MaxLength: 80}, 220, past)
+	ctx := DraftContext{
+		Tweet:  model.Tweet{Text: "cc @bob what do you think?"},
+		Author: model.User{Username: "alice"},
+	}
+	cases := []struct {
+		text, rule string
+	}{
+		{"Agreed, the leader lease trick is underrated.", ""},
+		{"short", "length"},
+		{"This reply rambles on and on about consensus protocols well past the configured limit of eighty", "length"},
+		{"Nice thread\u200b on raft internals", "chars"},
+		{"Great take #raft #golang #distsys", "chars"},
+		{"This is going to the moon, honestly", "blocklist"},
+		{"Love the crypto angle here", "blocklist"},
+		{"Cryptography details matter here", ""},
+		{"Email me at dev@example.com for details", "pii"},
+		{"Call me on 415-555-0199 to discuss", "pii"},
+		{"Ring +44 20 7946 0958 any time", "pii"},
+		{"Try (415) 555-0199 after lunch", "pii"},
+		{"Or just call 555-0199 tonight", "pii"},
+		{"Raft matured a lot during 2019-2020 in practice", ""},
+		{"Compare the 2023 2024 benchmark results", ""},
+		{"Latency moved from 1500.2500 after the fix", ""},
+		{"@carol should weigh in on this one", "pii"},
+		{"@alice and @bob both make fair points", ""},
+		{"See https://example.com/post for more", "links"},
+		{"consensus is mostly about failure detection and timeouts", "repetition"},
+	}
+	for _, c := range cases {
+		rej := v.Validate(c.text, ctx)
+		got := ""
+		if rej != nil { got = rej.Rule }
+		if got != c.rule { t.Errorf("%q: got rule %q want %q (%v)", c.text, got, c.rule, rej) }
+		if rej != nil && rej.Reason == "" { t.Errorf("%q: missing rejection reason", c.text) }
+	}
+}
+
+func TestValidatorLinkAllowlistAndDisabled(t *testing.T) {
+	v := NewValidator(config.DraftRules{Links: "allowlist", AllowedDomains: []string{"go.dev"}}, 220, nil)
+	if rej := v.Validate("Docs: https://pkg.go.dev/net/http explain it", DraftContext{}); rej != nil { t.Fatalf("allowlisted subdomain rejected: %v", rej) }
+	if rej := v.Validate("Docs: https://evil.example/go.dev explain it", DraftContext{}); rej == nil || rej.Rule != "links" { t.Fatalf("expected link rejection, got %v", rej) }
+
+	v = NewValidator(config.DraftRules{Disabled: []string{"pii", "links"}}, 220, nil)
+	for _, name := range v.Rules() {
+		if name == "pii" || name == "links" { t.Fatalf("disabled rule %q still active", name) }
+	}
+	if rej := v.Validate("Email dev@example.com or https://x.com", DraftContext{}); rej != nil { t.Fatalf("disabled rules should not reject: %v", rej) }
+}
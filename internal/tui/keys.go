@@ -0,0 +1,69 @@
+package tui
+
+import (
+	"bufio"
+	"unicode/utf8"
+)
+
+// Key is one decoded keypress: a special key or a printable rune.
+type Key struct {
+	Special string // up, down, left, right, enter, esc, backspace, tab, ctrl-c, ctrl-u; empty for runes
+	Rune    rune
+}
+
+// ReadKey decodes the next key from raw terminal input (or a scripted stream). A lone ESC
+// is reported as esc when no CSI (ESC [) or SS3 (ESC O) sequence follows in the buffered
+// input; sequences other than the plain arrows (Delete, Home, modified arrows, ...) are
+// skipped whole so they never read as esc plus stray runes.
+func ReadKey(r *bufio.Reader) (Key, error) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil { return Key{}, err }
+		if b != 0x1b { return decodeByte(r, b) }
+		if r.Buffered() == 0 { return Key{Special: "esc"}, nil }
+		next, _ := r.Peek(1)
+		if next[0] != '[' && next[0] != 'O' { return Key{Special: "esc"}, nil }
+		_, _ = r.Discard(1)
+		seq, err := readSequence(r, next[0])
+		if err != nil { return Key{}, err }
+		switch seq {
+		case "A": return Key{Special: "up"}, nil
+		case "B": return Key{Special: "down"}, nil
+		case "C": return Key{Special: "right"}, nil
+		case "D": return Key{Special: "left"}, nil
+		}
+	}
+}
+
+// readSequence reads the rest of an escape sequence after its introducer: for CSI the
+// parameter and intermediate bytes up to the final byte (0x40-0x7E), for SS3 one byte.
+func readSequence(r *bufio.Reader, intro byte) (string, error) {
+	var seq []byte
+	for {
+		c, err := r.ReadByte()
+		if err != nil { return "", err }
+		seq = append(seq, c)
+		// a sequence this long is garbage; give up on it rather than eat more keys
+		if intro == 'O' || (c >= 0x40 && c <= 0x7e) || len(seq) >= 16 { return string(seq), nil }
+	}
+}
+
+// decodeByte decodes a key starting with a byte other than ESC.
+func decodeByte(r *bufio.Reader, b byte) (Key, error) {
+	switch b {
+	case '\r', '\n':
+		return Key{Special: "enter"}, nil
+	case 0x7f, 0x08:
+		return Key{Special: "backspace"}, nil
+	case '\t':
+		return Key{Special: "tab"}, nil
+	case 0x03:
+		return Key{Special: "ctrl-c"}, nil
+	case 0x15:
+		return Key{Special: "ctrl-u"}, nil
+	}
+	if b < utf8.RuneSelf { return Key{Rune: rune(b)}, nil }
+	_ = r.UnreadByte()
+	ru, _, err := r.ReadRune()
+	return Key{Rune: ru}, err
+}
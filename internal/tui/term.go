@@ -0,0 +1,33 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// stty runs stty against f (the controlling terminal, also under SSH).
+func stty(f *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = f
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// MakeRaw puts the terminal into raw, no-echo mode and returns a function restoring it.
+func MakeRaw(f *os.File) (func(), error) {
+	saved, err := stty(f, "-g")
+	if err != nil { return nil, fmt.Errorf("not a terminal: %w", err) }
+	if _, err := stty(f, "raw", "-echo"); err != nil { return nil, err }
+	return func() { _, _ = stty(f, saved) }, nil
+}
+
+// Size returns the terminal's columns and rows, or 100x30 when unknown.
+func Size(f *os.File) (int, int) {
+	out, err := stty(f, "size")
+	var rows, cols int
+	if err != nil { return 100, 30 }
+	if _, err := fmt.Sscan(out, &rows, &cols); err != nil || rows <= 0 || cols <= 0 { return 100, 30 }
+	return cols, rows
+}
@@ -0,0 +1,285 @@
+// Package tui is a small keyboard-driven terminal UI for triaging queued reply drafts.
+// It only needs ANSI escapes and a raw terminal, so it works over SSH, and it reads keys
+// from any io.Reader so sessions can be scripted in tests.
+package tui
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"starseed/internal/queue"
+	"starseed/internal/store/sqlitevec"
+	"starseed/internal/util"
+)
+
+// Item is one draft with the scores shown to the reviewer.
+type Item struct {
+	Draft sqlitevec.Draft
+	Meta  queue.Meta
+}
+
+// Items decodes draft metadata for display.
+func Items(drafts []sqlitevec.Draft) []Item {
+	out := make([]Item, 0, len(drafts))
+	for _, d := range drafts {
+		it := Item{Draft: d}
+		_ = json.Unmarshal([]byte(d.Meta), &it.Meta)
+		out = append(out, it)
+	}
+	return out
+}
+
+// Actions applies review decisions (QueueActions in production).
+type Actions interface {
+	Approve(id int64) (sqlitevec.Draft, error)
+	Reject(id int64, reason string) (sqlitevec.Draft, error)
+	Edit(id int64, text string) (sqlitevec.Draft, error)
+}
+
+// QueueActions applies decisions to the review queue. Check, when set, validates edited
+// text before it is saved.
+type QueueActions struct {
+	DB    *sqlitevec.DB
+	Check func(d sqlitevec.Draft, text string) error
+	Now   func() time.Time
+}
+
+func (q QueueActions) now() time.Time {
+	if q.Now != nil { return q.Now() }
+	return time.Now().UTC()
+}
+
+func (q QueueActions) Approve(id int64) (sqlitevec.Draft, error) {
+	return queue.Approve(context.Background(), q.DB, id, time.Time{}, q.now())
+}
+
+func (q QueueActions) Reject(id int64, reason string) (sqlitevec.Draft, error) {
+	return queue.Reject(context.Background(), q.DB, id, reason, q.now())
+}
+
+func (q QueueActions) Edit(id int64, text string) (sqlitevec.Draft, error) {
+	if q.Check != nil {
+		d, err := q.DB.GetDraft(context.Background(), id)
+		if err != nil { return d, err }
+		if err := q.Check(d, text); err != nil { return d, err }
+	}
+	return queue.Edit(context.Background(), q.DB, id, text, q.now())
+}
+
+const (
+	modeList   = "list"
+	modeEdit   = "edit"
+	modeReject = "reject"
+)
+
+// App holds the UI state; Run drives it from a key stream.
+type App struct {
+	Items  []Item
+	Width  int
+	Height int
+	Loc    *time.Location
+
+	act    Actions
+	cur    int
+	alt    int // selected alternate in the right column
+	mode   string
+	buf    []rune
+	status string
+}
+
+// New creates an app over items sized for a width x height terminal.
+func New(items []Item, act Actions, width, height int) *App {
+	if width < 60 { width = 60 }
+	if height < 16 { height = 16 }
+	return &App{Items: items, Width: width, Height: height, Loc: time.UTC, act: act, mode: modeList}
+}
+
+// Run renders and handles keys until q, ctrl-c or the end of input.
+func (a *App) Run(in io.Reader, out io.Writer) error {
+	r := bufio.NewReader(in)
+	fmt.Fprint(out, "\x1b[?1049h\x1b[?25l")
+	defer fmt.Fprint(out, "\x1b[?25h\x1b[?1049l")
+	for {
+		a.Render(out)
+		k, err := ReadKey(r)
+		if errors.Is(err, io.EOF) { return nil }
+		if err != nil { return err }
+		if a.Handle(k) { return nil }
+	}
+}
+
+// Handle applies one key and reports whether the app should quit.
+func (a *App) Handle(k Key) bool {
+	if k.Special == "ctrl-c" { return true }
+	switch a.mode {
+	case modeEdit, modeReject:
+		a.handleInput(k)
+		return false
+	}
+	switch {
+	case k.Rune == 'q':
+		return true
+	case k.Special == "down" || k.Rune == 'j':
+		a.move(1)
+	case k.Special == "up" || k.Rune == 'k':
+		a.move(-1)
+	case k.Special == "tab" || k.Special == "right" || k.Rune == 'l':
+		if it := a.current(); it != nil && len(it.Meta.Alternates) > 0 { a.alt = (a.alt + 1) % len(it.Meta.Alternates) }
+	case k.Special == "left" || k.Rune == 'h':
+		if it := a.current(); it != nil && len(it.Meta.Alternates) > 0 { a.alt = (a.alt + len(it.Meta.Alternates) - 1) % len(it.Meta.Alternates) }
+	case k.Rune == 'a':
+		a.apply("approved", func(id int64) (sqlitevec.Draft, error) { return a.act.Approve(id) })
+	case k.Rune == 'r':
+		if a.current() != nil { a.mode, a.buf, a.status = modeReject, nil, "" }
+	case k.Rune == 'e':
+		if it := a.current(); it != nil { a.mode, a.buf, a.status = modeEdit, []rune(it.Draft.Text), "" }
+	case k.Rune == 'u':
+		// Use the selected alternate as the draft text
+		if it := a.current(); it != nil && a.alt < len(it.Meta.Alternates) {
+			text := it.Meta.Alternates[a.alt].Text
+			a.apply("updated", func(id int64) (sqlitevec.Draft, error) { return a.act.Edit(id, text) })
+		}
+	}
+	return false
+}
+
+func (a *App) handleInput(k Key) {
+	switch k.Special {
+	case "esc":
+		a.mode, a.buf, a.status = modeList, nil, "cancelled"
+	case "enter":
+		text := strings.TrimSpace(string(a.buf))
+		mode := a.mode
+		a.mode, a.buf = modeList, nil
+		if mode == modeReject {
+			a.apply("rejected", func(id int64) (sqlitevec.Draft, error) { return a.act.Reject(id, text) })
+		} else {
+			a.apply("updated", func(id int64) (sqlitevec.Draft, error) { return a.act.Edit(id, text) })
+		}
+	case "backspace":
+		if len(a.buf) > 0 { a.buf = a.buf[:len(a.buf)-1] }
+	case "ctrl-u":
+		a.buf = nil
+	case "":
+		if k.Rune >= ' ' { a.buf = append(a.buf, k.Rune) }
+	}
+}
+
+// apply runs an action on the selected draft and records the outcome in the status line.
+func (a *App) apply(verb string, fn func(id int64) (sqlitevec.Draft, error)) {
+	it := a.current()
+	if it == nil { return }
+	d, err := fn(it.Draft.ID)
+	if err != nil {
+		a.status = fmt.Sprintf("#%d: %v", it.Draft.ID, err)
+		return
+	}
+	it.Draft = d
+	a.status = fmt.Sprintf("#%d %s", d.ID, verb)
+	if verb != "updated" { a.move(1) }
+}
+
+func (a *App) current() *Item {
+	if a.cur < 0 || a.cur >= len(a.Items) { return nil }
+	return &a.Items[a.cur]
+}
+
+func (a *App) move(d int) {
+	n := a.cur + d
+	if n < 0 || n >= len(a.Items) { return }
+	a.cur, a.alt = n, 0
+}
+
+// Render draws the full screen: the draft list, the selected draft beside its
+// alternates, and a help/status footer. Lines end in CRLF for raw terminals.
+func (a *App) Render(w io.Writer) {
+	var b strings.Builder
+	b.WriteString("\x1b[H\x1b[2J")
+	line := func(s string) { b.WriteString(s); b.WriteString("\x1b[K\r\n") }
+	line(fmt.Sprintf("\x1b[1mstarseed triage\x1b[0m  %d drafts", len(a.Items)))
+	line(pad(fmt.Sprintf("  %-4s %-9s %-16s %-14s %5s %5s %6s  %s", "ID", "STATE", "WHEN", "AUTHOR", "AUTH", "ORG", "WINDOW", "TWEET"), a.Width))
+	listRows := a.Height / 2 - 2
+	if listRows < 3 { listRows = 3 }
+	start := 0
+	if a.cur >= listRows { start = a.cur - listRows + 1 }
+	for i := start; i < len(a.Items) && i < start+listRows; i++ {
+		it := a.Items[i]
+		author := it.Meta.Author
+		if author == "" { author = it.Draft.AuthorID } else { author = "@" + author }
+		row := fmt.Sprintf("  %-4d %-9s %-16s %-14s %5.2f %5.2f %6.3f  %s", it.Draft.ID, it.Draft.State, it.Draft.Scheduled.In(a.Loc).Format("01-02 15:04"), util.Truncate(author, 13), it.Meta.AuthorScore, it.Meta.Organic, it.Meta.Window, strings.Join(strings.Fields(it.Draft.TweetText), " "))
+		row = pad(row, a.Width)
+		if i == a.cur { row = "\x1b[7m" + row + "\x1b[0m" }
+		line(row)
+	}
+	if len(a.Items) == 0 { line("  (no drafts)") }
+	line(strings.Repeat("─", a.Width))
+	if it := a.current(); it != nil {
+		half := (a.Width - 3) / 2
+		left := append(wrap("Tweet: "+it.Draft.TweetText, half), "")
+		draft := it.Draft.Text
+		if a.mode == modeEdit { draft = string(a.buf) + "▏" }
+		left = append(left, wrap(fmt.Sprintf("Draft (%.2f): %s", it.Draft.Score, draft), half)...)
+		right := []string{"Alternates:"}
+		for i, c := range it.Meta.Alternates {
+			mark := "  "
+			if i == a.alt { mark = "> " }
+			right = append(right, wrap(fmt.Sprintf("%s%d. (%s %.2f) %s", mark, i+1, c.Source, c.Score, c.Text), half)...)
+		}
+		if len(it.Meta.Alternates) == 0 { right = append(right, "  (none)") }
+		rows := a.Height - listRows - 6
+		for i := 0; i < rows && (i < len(left) || i < len(right)); i++ {
+			var l, r string
+			if i < len(left) { l = left[i] }
+			if i < len(right) { r = right[i] }
+			line(pad(l, half) + " │ " + r)
+		}
+	}
+	line(strings.Repeat("─", a.Width))
+	switch a.mode {
+	case modeReject:
+		line(pad("Reject reason: "+string(a.buf)+"▏  (enter to confirm, esc to cancel)", a.Width))
+	case modeEdit:
+		line(pad("Editing draft (enter to save, esc to cancel, ctrl-u to clear)", a.Width))
+	default:
+		line(pad("j/k move  a approve  r reject  e edit  tab/h/l alternate  u use alternate  q quit", a.Width))
+	}
+	line(pad(a.status, a.Width))
+	_, _ = io.WriteString(w, b.String())
+}
+
+// pad truncates or right-pads s to exactly n runes.
+func pad(s string, n int) string {
+	r := []rune(s)
+	if len(r) > n { return string(r[:n-1]) + "…" }
+	return s + strings.Repeat(" ", n-len(r))
+}
+
+// wrap breaks text into lines of at most n runes on word boundaries.
+func wrap(s string, n int) []string {
+	var out []string
+	cur := ""
+	for _, w := range strings.Fields(s) {
+		for len([]rune(w)) > n {
+			if cur != "" { out = append(out, cur); cur = "" }
+			out = append(out, string([]rune(w)[:n]))
+			w = string([]rune(w)[n:])
+		}
+		switch {
+		case cur == "":
+			cur = w
+		case len([]rune(cur))+1+len([]rune(w)) <= n:
+			cur += " " + w
+		default:
+			out = append(out, cur)
+			cur = w
+		}
+	}
+	if cur != "" { out = append(out, cur) }
+	return out
+}
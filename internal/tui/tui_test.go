@@ -0,0 +1,74 @@
+package tui
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"starseed/internal/model"
+	"starseed/internal/queue"
+	"starseed/internal/store/sqlitevec"
+	"starseed/internal/suggest"
+)
+
+func TestReadKeyDecodesSequences(t *testing.T) {
+	// Delete (ESC [3~) and ctrl-up (ESC [1;5A) are skipped; SS3 arrows come from
+	// terminals in application cursor mode
+	r := bufio.NewReader(strings.NewReader("j\x1b[A\x1b[B\r\x7f\x1b[3~\x1b[1;5Ak\x1bOA\x1bOD\x1bqé"))
+	want := []Key{{Rune: 'j'}, {Special: "up"}, {Special: "down"}, {Special: "enter"}, {Special: "backspace"}, {Rune: 'k'}, {Special: "up"}, {Special: "left"}, {Special: "esc"}, {Rune: 'q'}, {Rune: 'é'}}
+	for i, w := range want {
+		k, err := ReadKey(r)
+		if err != nil || k != w { t.Fatalf("key %d: got %+v %v, want %+v", i, k, err, w) }
+	}
+}
+
+func TestScriptedTriageUpdatesQueue(t *testing.T) {
+	db, err := sqlitevec.Open(":memory:")
+	if err != nil { t.Fatal(err) }
+	defer db.Close()
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	var sugs []suggest.Suggestion
+	for _, id := range []string{"1", "2", "3"} {
+		sugs = append(sugs, suggest.Suggestion{Tweet: model.Tweet{ID: id, Text: "tweet " + id, CreatedAt: now}, Text: "draft " + id, When: now,
+			Alternates: []suggest.Candidate{{Text: "alt one " + id, Source: "llm"}, {Text: "alt two " + id, Source: "heuristic"}}})
+	}
+	if _, err := queue.Enqueue(ctx, db, sugs, nil, now); err != nil { t.Fatal(err) }
+	drafts, _ := db.ListDrafts(ctx, []string{queue.Pending}, 0)
+	act := QueueActions{DB: db, Now: func() time.Time { return now }, Check: func(d sqlitevec.Draft, text string) error {
+		if strings.Contains(text, "bad") { return errors.New("blocked word") }
+		return nil
+	}}
+	app := New(Items(drafts), act, 100, 30)
+	// approve #1; edit #2 (rejected edit, then a good one) and approve; pick alternate two for #3 and reject it
+	script := "a" + "ebad\r" + "e\x15fresh take\r" + "a" + "\tu" + "rspam\r" + "q"
+	var out bytes.Buffer
+	if err := app.Run(strings.NewReader(script), &out); err != nil { t.Fatal(err) }
+	got := map[int64]sqlitevec.Draft{}
+	all, _ := db.ListDrafts(ctx, nil, 0)
+	for _, d := range all { got[d.ID] = d }
+	if got[1].State != queue.Approved { t.Fatalf("#1: %+v", got[1]) }
+	if got[2].State != queue.Approved || got[2].Text != "fresh take" { t.Fatalf("#2: %+v", got[2]) }
+	if got[3].State != queue.Rejected || got[3].Text != "alt two 3" || got[3].Note != "spam" { t.Fatalf("#3: %+v", got[3]) }
+	if !strings.Contains(out.String(), "blocked word") { t.Fatalf("expected validation error in output") }
+	if !strings.Contains(out.String(), "alt one 1") { t.Fatalf("expected alternates rendered") }
+}
+
+func TestRenderFitsWidth(t *testing.T) {
+	items := []Item{{Draft: sqlitevec.Draft{ID: 1, State: "pending", TweetText: strings.Repeat("long words\n\nhere ", 20), Text: strings.Repeat("draft ", 40)},
+		Meta: queue.Meta{Author: "someone", AuthorScore: 0.7, Organic: 0.8, Window: 0.42}}}
+	app := New(items, nil, 80, 20)
+	var out bytes.Buffer
+	app.Render(&out)
+	for _, l := range strings.Split(out.String(), "\r\n") {
+		plain := l
+		for _, esc := range []string{"\x1b[H", "\x1b[2J", "\x1b[K", "\x1b[7m", "\x1b[0m", "\x1b[1m"} { plain = strings.ReplaceAll(plain, esc, "") }
+		if n := len([]rune(plain)); n > 80 { t.Fatalf("line exceeds width (%d): %q", n, plain) }
+		if strings.Contains(plain, "\n") { t.Fatalf("raw newline breaks the layout: %q", plain) }
+	}
+	if !strings.Contains(out.String(), "@someone") || !strings.Contains(out.String(), "0.420") { t.Fatalf("missing scores:\n%s", out.String()) }
+}
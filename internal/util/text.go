@@ -34,3 +34,13 @@ func Tokenize(s string) []string {
 	parts := strings.Fields(s)
 	return parts
 }
+
+// Truncate shortens s to at most n runes after normalizing whitespace, adding an ellipsis when cut.
+func Truncate(s string, n int) string {
+	s = NormalizeWhitespace(s)
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "…"
+}
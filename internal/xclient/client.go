@@ -1,6 +1,7 @@
 package xclient
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -22,6 +23,7 @@ type XClient interface {
 	GetUserByUsername(ctx context.Context, username string) (model.User, error)
 	GetHomeTimeline(ctx context.Context, userID string, limit int) ([]model.Tweet, error)
 	GetFollowing(ctx context.Context, userID string, limit int) ([]model.User, error)
+	GetFollowers(ctx context.Context, userID string, limit int) ([]model.User, error)
     SearchRecentTweets(ctx context.Context, query string, limit int) ([]model.Tweet, error)
     SearchRecentTweetsSince(ctx context.Context, query string, limit int, start time.Time) ([]model.Tweet, error)
     GetUserTweets(ctx context.Context, userID string, limit int) ([]model.Tweet, error)
@@ -35,6 +37,7 @@ type XClient interface {
 type HTTPClient struct {
 	baseURL     string
 	bearerToken string
+	userToken   string // OAuth 2.0 user-context token for write actions
 	httpClient  *http.Client
     limiter     *rate.Limiter
     maxAttempts int
@@ -52,6 +55,9 @@ func NewHTTPClient(bearerToken string) *HTTPClient {
 	}
 }
 
+// SetUserToken sets the user-context token used for write actions such as posting.
+func (c *HTTPClient) SetUserToken(token string) { c.userToken = token }
+
 func (c *HTTPClient) auth(req *http.Request) {
 	if c.bearerToken != "" {
 		req.Header.Set("Authorization", "Bearer "+c.bearerToken)
@@ -85,6 +91,7 @@ func (c *HTTPClient) GetUserByUsername(ctx context.Context, username string) (mo
 			Verified bool      `json:"verified"`
 			Description string `json:"description"`
 			URL string `json:"url"`
+			ProfileImageURL string `json:"profile_image_url"`
 			PublicMetrics struct {
 				FollowersCount int `json:"followers_count"`
 				FollowingCount int `json:"following_count"`
@@ -104,6 +111,7 @@ func (c *HTTPClient) GetUserByUsername(ctx context.Context, username string) (mo
 		Verified: raw.Data.Verified,
 		Description: raw.Data.Description,
 		URL: raw.Data.URL,
+		DefaultImage: defaultImage(raw.Data.ProfileImageURL),
 		FollowersCount: raw.Data.PublicMetrics.FollowersCount,
 		FollowingCount: raw.Data.PublicMetrics.FollowingCount,
 		TweetCount: raw.Data.PublicMetrics.TweetCount,
@@ -121,7 +129,7 @@ func (c *HTTPClient) GetHomeTimeline(ctx context.Context, userID string, limit i
 
 // SearchRecentTweets searches recent tweets by query of interests.
 func (c *HTTPClient) SearchRecentTweets(ctx context.Context, query string, limit int) ([]model.Tweet, error) {
-    u := fmt.Sprintf("%s/tweets/search/recent?max_results=%d&tweet.fields=created_at,public_metrics,lang,author_id&query=%s",
+    u := fmt.Sprintf("%s/tweets/search/recent?max_results=%d&tweet.fields=created_at,public_metrics,lang,author_id,conversation_id,referenced_tweets&query=%s",
         c.baseURL, clamp(limit, 10, 100), url.QueryEscape(query))
     req, _ := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
     c.auth(req)
@@ -137,6 +145,8 @@ func (c *HTTPClient) SearchRecentTweets(ctx context.Context, query string, limit
             CreatedAt time.Time `json:"created_at"`
             Lang string `json:"lang"`
             AuthorID string `json:"author_id"`
+            ConversationID string `json:"conversation_id"`
+            ReferencedTweets []referencedTweet `json:"referenced_tweets"`
             PublicMetrics struct{
                 LikeCount int `json:"like_count"`
                 ReplyCount int `json:"reply_count"`
@@ -154,6 +164,8 @@ func (c *HTTPClient) SearchRecentTweets(ctx context.Context, query string, limit
             CreatedAt: d.CreatedAt,
             Language: d.Lang,
             AuthorID: d.AuthorID,
+            ConversationID: d.ConversationID,
+            ReplyToID: repliedToID(d.ReferencedTweets),
             LikeCount: d.PublicMetrics.LikeCount,
             ReplyCount: d.PublicMetrics.ReplyCount,
             RetweetCount: d.PublicMetrics.RetweetCount,
@@ -164,7 +176,7 @@ func (c *HTTPClient) SearchRecentTweets(ctx context.Context, query string, limit
 }
 
 func (c *HTTPClient) SearchRecentTweetsSince(ctx context.Context, query string, limit int, start time.Time) ([]model.Tweet, error) {
-    u := fmt.Sprintf("%s/tweets/search/recent?start_time=%s&max_results=%d&tweet.fields=created_at,public_metrics,lang,author_id&query=%s",
+    u := fmt.Sprintf("%s/tweets/search/recent?start_time=%s&max_results=%d&tweet.fields=created_at,public_metrics,lang,author_id,conversation_id,referenced_tweets&query=%s",
         c.baseURL, url.QueryEscape(start.Format(time.RFC3339)), clamp(limit, 10, 100), url.QueryEscape(query))
     req, _ := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
     c.auth(req)
@@ -180,6 +192,8 @@ func (c *HTTPClient) SearchRecentTweetsSince(ctx context.Context, query string,
             CreatedAt time.Time `json:"created_at"`
             Lang string `json:"lang"`
             AuthorID string `json:"author_id"`
+            ConversationID string `json:"conversation_id"`
+            ReferencedTweets []referencedTweet `json:"referenced_tweets"`
             PublicMetrics struct{
                 LikeCount int `json:"like_count"`
                 ReplyCount int `json:"reply_count"`
@@ -197,6 +211,8 @@ func (c *HTTPClient) SearchRecentTweetsSince(ctx context.Context, query string,
             CreatedAt: d.CreatedAt,
             Language: d.Lang,
             AuthorID: d.AuthorID,
+            ConversationID: d.ConversationID,
+            ReplyToID: repliedToID(d.ReferencedTweets),
             LikeCount: d.PublicMetrics.LikeCount,
             ReplyCount: d.PublicMetrics.ReplyCount,
             RetweetCount: d.PublicMetrics.RetweetCount,
@@ -207,57 +223,86 @@ func (c *HTTPClient) SearchRecentTweetsSince(ctx context.Context, query string,
 }
 
 func (c *HTTPClient) GetFollowing(ctx context.Context, userID string, limit int) ([]model.User, error) {
-	u := fmt.Sprintf("%s/users/%s/following?max_results=%d&user.fields=public_metrics,created_at,verified,description,url,profile_image_url", c.baseURL, url.PathEscape(userID), clamp(limit, 10, 1000))
-	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
-	c.auth(req)
-    if err := c.limiter.Wait(ctx); err != nil { return nil, err }
-    resp, err := c.doWithRetry(ctx, req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("x api status %d", resp.StatusCode)
-	}
-	var raw struct {
-		Data []struct {
-			ID       string    `json:"id"`
-			Name     string    `json:"name"`
-			Username string    `json:"username"`
-			CreatedAt time.Time `json:"created_at"`
-			Verified bool      `json:"verified"`
-			Description string `json:"description"`
-			URL string `json:"url"`
-			PublicMetrics struct {
-				FollowersCount int `json:"followers_count"`
-				FollowingCount int `json:"following_count"`
-				TweetCount     int `json:"tweet_count"`
-				ListedCount    int `json:"listed_count"`
-			} `json:"public_metrics"`
-		} `json:"data"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
-		return nil, err
-	}
-	out := make([]model.User, 0, len(raw.Data))
-	for _, d := range raw.Data {
-		out = append(out, model.User{
-			ID: d.ID,
-			Username: d.Username,
-			Name: d.Name,
-			CreatedAt: d.CreatedAt,
-			Verified: d.Verified,
-			Description: d.Description,
-			URL: d.URL,
-			FollowersCount: d.PublicMetrics.FollowersCount,
-			FollowingCount: d.PublicMetrics.FollowingCount,
-			TweetCount: d.PublicMetrics.TweetCount,
-			ListedCount: d.PublicMetrics.ListedCount,
-		})
+	return c.listUsers(ctx, "following", userID, limit)
+}
+
+// GetFollowers returns up to limit accounts following userID, paging as needed.
+func (c *HTTPClient) GetFollowers(ctx context.Context, userID string, limit int) ([]model.User, error) {
+	return c.listUsers(ctx, "followers", userID, limit)
+}
+
+// listUsers pages through /users/:id/<edge> (following or followers) until limit users
+// (default 10).
+func (c *HTTPClient) listUsers(ctx context.Context, edge, userID string, limit int) ([]model.User, error) {
+	if limit <= 0 { limit = 10 }
+	var out []model.User
+	token := ""
+	for {
+		u := fmt.Sprintf("%s/users/%s/%s?max_results=%d&user.fields=public_metrics,created_at,verified,description,url,profile_image_url", c.baseURL, url.PathEscape(userID), edge, clamp(limit-len(out), 10, 1000))
+		if token != "" { u += "&pagination_token=" + url.QueryEscape(token) }
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		c.auth(req)
+		if err := c.limiter.Wait(ctx); err != nil { return out, err }
+		resp, err := c.doWithRetry(ctx, req)
+		if err != nil {
+			return out, err
+		}
+		if resp.StatusCode >= 400 {
+			resp.Body.Close()
+			return out, fmt.Errorf("x api status %d", resp.StatusCode)
+		}
+		var raw struct {
+			Data []struct {
+				ID       string    `json:"id"`
+				Name     string    `json:"name"`
+				Username string    `json:"username"`
+				CreatedAt time.Time `json:"created_at"`
+				Verified bool      `json:"verified"`
+				Description string `json:"description"`
+				URL string `json:"url"`
+				ProfileImageURL string `json:"profile_image_url"`
+				PublicMetrics struct {
+					FollowersCount int `json:"followers_count"`
+					FollowingCount int `json:"following_count"`
+					TweetCount     int `json:"tweet_count"`
+					ListedCount    int `json:"listed_count"`
+				} `json:"public_metrics"`
+			} `json:"data"`
+			Meta struct {
+				NextToken string `json:"next_token"`
+			} `json:"meta"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&raw)
+		resp.Body.Close()
+		if err != nil {
+			return out, err
+		}
+		for _, d := range raw.Data {
+			out = append(out, model.User{
+				ID: d.ID,
+				Username: d.Username,
+				Name: d.Name,
+				CreatedAt: d.CreatedAt,
+				Verified: d.Verified,
+				Description: d.Description,
+				URL: d.URL,
+				DefaultImage: defaultImage(d.ProfileImageURL),
+				FollowersCount: d.PublicMetrics.FollowersCount,
+				FollowingCount: d.PublicMetrics.FollowingCount,
+				TweetCount: d.PublicMetrics.TweetCount,
+				ListedCount: d.PublicMetrics.ListedCount,
+			})
+		}
+		token = raw.Meta.NextToken
+		if token == "" || len(out) >= limit || len(raw.Data) == 0 { break }
 	}
+	if len(out) > limit { out = out[:limit] }
 	return out, nil
 }
 
+// defaultImage reports whether a profile image URL is X's default avatar.
+func defaultImage(u string) bool { return strings.Contains(u, "default_profile_images") }
+
 // GetUserTweets returns recent tweets for a user.
 func (c *HTTPClient) GetUserTweets(ctx context.Context, userID string, limit int) ([]model.Tweet, error) {
     u := fmt.Sprintf("%s/users/%s/tweets?max_results=%d&tweet.fields=created_at,public_metrics,lang&exclude=retweets,replies",
@@ -296,6 +341,7 @@ func (c *HTTPClient) GetUserTweets(ctx context.Context, userID string, limit int
             ReplyCount: d.PublicMetrics.ReplyCount,
             RetweetCount: d.PublicMetrics.RetweetCount,
             QuoteCount: d.PublicMetrics.QuoteCount,
+            HasLink: strings.Contains(d.Text, "https://"),
         })
     }
     return out, nil
@@ -324,6 +370,7 @@ func (c *HTTPClient) GetUsersByIDs(ctx context.Context, ids []string) ([]model.U
             Verified bool      `json:"verified"`
             Description string `json:"description"`
             URL string `json:"url"`
+            ProfileImageURL string `json:"profile_image_url"`
             PublicMetrics struct {
                 FollowersCount int `json:"followers_count"`
                 FollowingCount int `json:"following_count"`
@@ -343,6 +390,7 @@ func (c *HTTPClient) GetUsersByIDs(ctx context.Context, ids []string) ([]model.U
             Verified: d.Verified,
             Description: d.Description,
             URL: d.URL,
+            DefaultImage: defaultImage(d.ProfileImageURL),
             FollowersCount: d.PublicMetrics.FollowersCount,
             FollowingCount: d.PublicMetrics.FollowingCount,
             TweetCount: d.PublicMetrics.TweetCount,
@@ -352,6 +400,66 @@ func (c *HTTPClient) GetUsersByIDs(ctx context.Context, ids []string) ([]model.U
     return out, nil
 }
 
+// GetUserTimeline returns the user's recent tweets including replies (retweets excluded),
+// with conversation and reply references, paging until limit.
+func (c *HTTPClient) GetUserTimeline(ctx context.Context, userID string, limit int) ([]model.Tweet, error) {
+    var out []model.Tweet
+    token := ""
+    for len(out) < limit {
+        u := fmt.Sprintf("%s/users/%s/tweets?max_results=%d&tweet.fields=created_at,public_metrics,lang,conversation_id,referenced_tweets&exclude=retweets",
+            c.baseURL, url.PathEscape(userID), clamp(limit-len(out), 5, 100))
+        if token != "" { u += "&pagination_token=" + url.QueryEscape(token) }
+        req, _ := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+        c.auth(req)
+        if err := c.limiter.Wait(ctx); err != nil { return out, err }
+        resp, err := c.doWithRetry(ctx, req)
+        if err != nil { return out, err }
+        if resp.StatusCode >= 400 { resp.Body.Close(); return out, fmt.Errorf("x api status %d", resp.StatusCode) }
+        var raw struct {
+            Data []struct {
+                ID string `json:"id"`
+                Text string `json:"text"`
+                ConversationID string `json:"conversation_id"`
+                ReferencedTweets []referencedTweet `json:"referenced_tweets"`
+                CreatedAt time.Time `json:"created_at"`
+                Lang string `json:"lang"`
+                PublicMetrics struct{
+                    LikeCount int `json:"like_count"`
+                    ReplyCount int `json:"reply_count"`
+                    RetweetCount int `json:"retweet_count"`
+                    QuoteCount int `json:"quote_count"`
+                } `json:"public_metrics"`
+            } `json:"data"`
+            Meta struct {
+                NextToken string `json:"next_token"`
+            } `json:"meta"`
+        }
+        err = json.NewDecoder(resp.Body).Decode(&raw)
+        resp.Body.Close()
+        if err != nil { return out, err }
+        for _, d := range raw.Data {
+            out = append(out, model.Tweet{
+                ID: d.ID,
+                AuthorID: userID,
+                ConversationID: d.ConversationID,
+                ReplyToID: repliedToID(d.ReferencedTweets),
+                Text: d.Text,
+                CreatedAt: d.CreatedAt,
+                Language: d.Lang,
+                LikeCount: d.PublicMetrics.LikeCount,
+                ReplyCount: d.PublicMetrics.ReplyCount,
+                RetweetCount: d.PublicMetrics.RetweetCount,
+                QuoteCount: d.PublicMetrics.QuoteCount,
+                HasLink: strings.Contains(d.Text, "https://"),
+            })
+        }
+        token = raw.Meta.NextToken
+        if token == "" || len(raw.Data) == 0 { break }
+    }
+    if len(out) > limit { out = out[:limit] }
+    return out, nil
+}
+
 // GetLikedTweets returns tweets liked by the user.
 func (c *HTTPClient) GetLikedTweets(ctx context.Context, userID string, limit int) ([]model.Tweet, error) {
     u := fmt.Sprintf("%s/users/%s/liked_tweets?max_results=%d&tweet.fields=created_at,public_metrics,lang,author_id",
@@ -398,7 +506,7 @@ func (c *HTTPClient) GetLikedTweets(ctx context.Context, userID string, limit in
 
 // GetMentions returns tweets that mention the user.
 func (c *HTTPClient) GetMentions(ctx context.Context, userID string, limit int) ([]model.Tweet, error) {
-    u := fmt.Sprintf("%s/users/%s/mentions?max_results=%d&tweet.fields=created_at,public_metrics,lang,author_id",
+    u := fmt.Sprintf("%s/users/%s/mentions?max_results=%d&tweet.fields=created_at,public_metrics,lang,author_id,conversation_id,referenced_tweets",
         c.baseURL, url.PathEscape(userID), clamp(limit, 10, 100))
     req, _ := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
     c.auth(req)
@@ -412,6 +520,8 @@ func (c *HTTPClient) GetMentions(ctx context.Context, userID string, limit int)
             ID string `json:"id"`
             Text string `json:"text"`
             AuthorID string `json:"author_id"`
+            ConversationID string `json:"conversation_id"`
+            ReferencedTweets []referencedTweet `json:"referenced_tweets"`
             CreatedAt time.Time `json:"created_at"`
             Lang string `json:"lang"`
             PublicMetrics struct{
@@ -428,6 +538,8 @@ func (c *HTTPClient) GetMentions(ctx context.Context, userID string, limit int)
         out = append(out, model.Tweet{
             ID: d.ID,
             AuthorID: d.AuthorID,
+            ConversationID: d.ConversationID,
+            ReplyToID: repliedToID(d.ReferencedTweets),
             Text: d.Text,
             CreatedAt: d.CreatedAt,
             Language: d.Lang,
@@ -460,6 +572,93 @@ func (c *HTTPClient) GetQuoteTweets(ctx context.Context, tweetID string, limit i
     return out, nil
 }
 
+// GetTweetsByIDs looks up up to 100 tweets by ID.
+func (c *HTTPClient) GetTweetsByIDs(ctx context.Context, ids []string) ([]model.Tweet, error) {
+    if len(ids) == 0 { return nil, nil }
+    if len(ids) > 100 { ids = ids[:100] }
+    u := fmt.Sprintf("%s/tweets?ids=%s&tweet.fields=created_at,public_metrics,lang,author_id,conversation_id,referenced_tweets",
+        c.baseURL, url.QueryEscape(strings.Join(ids, ",")))
+    req, _ := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+    c.auth(req)
+    if err := c.limiter.Wait(ctx); err != nil { return nil, err }
+    resp, err := c.doWithRetry(ctx, req)
+    if err != nil { return nil, err }
+    defer resp.Body.Close()
+    if resp.StatusCode >= 400 { return nil, fmt.Errorf("x api status %d", resp.StatusCode) }
+    var raw struct {
+        Data []struct{
+            ID string `json:"id"`
+            Text string `json:"text"`
+            CreatedAt time.Time `json:"created_at"`
+            Lang string `json:"lang"`
+            AuthorID string `json:"author_id"`
+            ConversationID string `json:"conversation_id"`
+            ReferencedTweets []referencedTweet `json:"referenced_tweets"`
+            PublicMetrics struct{
+                LikeCount int `json:"like_count"`
+                ReplyCount int `json:"reply_count"`
+                RetweetCount int `json:"retweet_count"`
+                QuoteCount int `json:"quote_count"`
+            } `json:"public_metrics"`
+        } `json:"data"`
+    }
+    if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil { return nil, err }
+    out := make([]model.Tweet, 0, len(raw.Data))
+    for _, d := range raw.Data {
+        out = append(out, model.Tweet{
+            ID: d.ID,
+            Text: d.Text,
+            CreatedAt: d.CreatedAt,
+            Language: d.Lang,
+            AuthorID: d.AuthorID,
+            ConversationID: d.ConversationID,
+            ReplyToID: repliedToID(d.ReferencedTweets),
+            LikeCount: d.PublicMetrics.LikeCount,
+            ReplyCount: d.PublicMetrics.ReplyCount,
+            RetweetCount: d.PublicMetrics.RetweetCount,
+            QuoteCount: d.PublicMetrics.QuoteCount,
+        })
+    }
+    return out, nil
+}
+
+// PostReply publishes text as a reply to inReplyTo and returns the new tweet ID.
+// It is attempted once: retrying a write could post the reply twice.
+func (c *HTTPClient) PostReply(ctx context.Context, inReplyTo, text string) (string, error) {
+    if c.userToken == "" { return "", fmt.Errorf("posting requires a user token (X_USER_TOKEN)") }
+    body, err := json.Marshal(map[string]any{"text": text, "reply": map[string]string{"in_reply_to_tweet_id": inReplyTo}})
+    if err != nil { return "", err }
+    req, _ := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/tweets", bytes.NewReader(body))
+    req.Header.Set("Authorization", "Bearer "+c.userToken)
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set("Accept", "application/json")
+    if err := c.limiter.Wait(ctx); err != nil { return "", err }
+    resp, err := c.httpClient.Do(req)
+    if err != nil { return "", err }
+    defer resp.Body.Close()
+    if resp.StatusCode >= 400 { return "", fmt.Errorf("x api status %d", resp.StatusCode) }
+    var raw struct {
+        Data struct {
+            ID string `json:"id"`
+        } `json:"data"`
+    }
+    if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil { return "", err }
+    return raw.Data.ID, nil
+}
+
+type referencedTweet struct {
+    Type string `json:"type"`
+    ID   string `json:"id"`
+}
+
+// repliedToID returns the parent tweet ID of a reply, if any.
+func repliedToID(refs []referencedTweet) string {
+    for _, r := range refs {
+        if r.Type == "replied_to" { return r.ID }
+    }
+    return ""
+}
+
 func clamp(v, min, max int) int { if v < min { return min }; if v > max { return max }; return v }
 
 func (c *HTTPClient) doWithRetry(ctx context.Context, req *http.Request) (*http.Response, error) {
@@ -46,3 +46,48 @@ func TestDoWithRetryHandles429(t *testing.T) {
 		t.Fatalf("expected at least 2 attempts, got %d", attempts)
 	}
 }
+
+func TestPostReplySendsReplyOnce(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if r.Method != http.MethodPost || r.URL.Path != "/tweets" || r.Header.Get("Authorization") != "Bearer user" {
+			t.Errorf("unexpected request %s %s %q", r.Method, r.URL.Path, r.Header.Get("Authorization"))
+		}
+		if attempts > 1 {
+			_, _ = w.Write([]byte(`{"data":{"id":"2"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+	c := newTestClient()
+	c.httpClient = ts.Client()
+	c.baseURL = ts.URL
+	if _, err := c.PostReply(context.Background(), "1", "hi"); err == nil { t.Fatal("expected missing user token error") }
+	c.SetUserToken("user")
+	if _, err := c.PostReply(context.Background(), "1", "hi"); err == nil || attempts != 1 { t.Fatalf("expected single failed attempt, got %v after %d", err, attempts) }
+	id, err := c.PostReply(context.Background(), "1", "hi")
+	if err != nil || id != "2" { t.Fatalf("expected id 2, got %q %v", id, err) }
+}
+
+func TestGetFollowersPages(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users/42/followers" { t.Errorf("path %s", r.URL.Path) }
+		if r.URL.Query().Get("pagination_token") == "" {
+			_, _ = w.Write([]byte(`{"data":[{"id":"1","username":"a"},{"id":"2","username":"b"}],"meta":{"next_token":"p2"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[{"id":"3","username":"c","profile_image_url":"https://pbs.twimg.com/sticky/default_profile_images/default_profile_normal.png","public_metrics":{"followers_count":7}}],"meta":{}}`))
+	}))
+	defer ts.Close()
+	c := newTestClient()
+	c.httpClient = ts.Client()
+	c.baseURL = ts.URL
+	users, err := c.GetFollowers(context.Background(), "42", 50)
+	if err != nil { t.Fatal(err) }
+	if len(users) != 3 || users[2].Username != "c" || users[2].FollowersCount != 7 || !users[2].DefaultImage || users[0].DefaultImage { t.Fatalf("users = %+v", users) }
+	// No limit defaults to 10 users instead of none
+	users, err = c.GetFollowers(context.Background(), "42", 0)
+	if err != nil || len(users) != 3 { t.Fatalf("limit 0: %+v %v", users, err) }
+}
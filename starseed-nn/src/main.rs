@@ -3,7 +3,7 @@ use rand::prelude::*;
 use rand::seq::SliceRandom;
 use serde::{Deserialize, Serialize};
 use std::fs;
-use std::io::{self, Read};
+use std::io::{self, BufRead, Read, Write};
 
 #[derive(Debug, Parser)]
 #[command(name = "starseed-nn", about = "NN for 15-min windows: train/infer")] 
@@ -42,6 +42,41 @@ enum Commands {
         #[arg(long)]
         data: Option<String>, // path to JSONL input, or stdin if not set
     },
+    /// Keep the model loaded and answer JSON-line requests on stdin/stdout
+    Serve {
+        #[arg(long, default_value = "model.json")]
+        model: String,
+    },
+}
+
+/// One request line in serve mode: {"id":1,"op":"infer","x":[[...]]},
+/// {"id":2,"op":"health"} or {"id":3,"op":"reload","model":"path"}.
+#[derive(Debug, Deserialize)]
+struct ServeRequest {
+    id: u64,
+    #[serde(default)]
+    op: String,
+    #[serde(default)]
+    x: Vec<Vec<f32>>,
+    #[serde(default)]
+    model: Option<String>,
+}
+
+#[derive(Debug, Serialize)]
+struct ServeResponse {
+    id: u64,
+    ok: bool,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    y: Option<Vec<Vec<f32>>>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    input: Option<usize>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    error: Option<String>,
+}
+
+impl ServeResponse {
+    fn ok(id: u64) -> Self { Self { id, ok: true, y: None, input: None, error: None } }
+    fn err(id: u64, msg: String) -> Self { Self { id, ok: false, y: None, input: None, error: Some(msg) } }
 }
 
 #[derive(Debug, Clone, Serialize, Deserialize)]
@@ -141,6 +176,50 @@ fn read_jsonl(path: &Option<String>) -> io::Result<Vec<Sample>> {
 #[derive(Serialize, Deserialize)]
 struct ModelFile { input: usize, hidden: usize, output: usize, mlp: MLP, threshold: f32 }
 
+fn load_model(path: &str) -> io::Result<ModelFile> {
+    let bytes = fs::read(path)?;
+    serde_json::from_slice(&bytes).map_err(|e| io::Error::new(io::ErrorKind::InvalidData, e))
+}
+
+fn handle_request(mf: &mut ModelFile, req: ServeRequest) -> ServeResponse {
+    match req.op.as_str() {
+        "" | "infer" => {
+            for x in &req.x {
+                if x.len() != mf.input {
+                    return ServeResponse::err(req.id, format!("expected {} features, got {}", mf.input, x.len()));
+                }
+            }
+            let y = req.x.iter().map(|x| mf.mlp.forward(x).1).collect();
+            ServeResponse { y: Some(y), ..ServeResponse::ok(req.id) }
+        }
+        "health" => ServeResponse { input: Some(mf.input), ..ServeResponse::ok(req.id) },
+        "reload" => match req.model.as_deref().map(load_model) {
+            Some(Ok(next)) => { *mf = next; ServeResponse::ok(req.id) }
+            Some(Err(e)) => ServeResponse::err(req.id, e.to_string()),
+            None => ServeResponse::err(req.id, "reload requires model".to_string()),
+        },
+        other => ServeResponse::err(req.id, format!("unknown op {}", other)),
+    }
+}
+
+fn serve(model: &str) -> io::Result<()> {
+    let mut mf = load_model(model)?;
+    let stdin = io::stdin();
+    let stdout = io::stdout();
+    let mut out = stdout.lock();
+    for line in stdin.lock().lines() {
+        let line = line?;
+        if line.trim().is_empty() { continue; }
+        let resp = match serde_json::from_str::<ServeRequest>(&line) {
+            Ok(req) => handle_request(&mut mf, req),
+            Err(e) => ServeResponse::err(0, e.to_string()),
+        };
+        writeln!(out, "{}", serde_json::to_string(&resp).unwrap())?;
+        out.flush()?;
+    }
+    Ok(())
+}
+
 fn mse(mlp: &MLP, data: &[Sample]) -> f32 {
     if data.is_empty() { return 0.0; }
     let mut sum = 0.0f32;
@@ -247,6 +326,7 @@ fn main() -> io::Result<()> {
                 println!("{}", serde_json::to_string(&y).unwrap());
             }
         }
+        Commands::Serve { model } => serve(&model)?,
     }
     Ok(())
 }
@@ -1,6 +0,0 @@
-#[doc(hidden)]
-pub mod __private228 {
-    #[doc(hidden)]
-    pub use crate::private::*;
-}
-use serde_core::__private228 as serde_core_private;
@@ -1,5 +0,0 @@
-#[doc(hidden)]
-pub mod __private228 {
-    #[doc(hidden)]
-    pub use crate::private::*;
-}